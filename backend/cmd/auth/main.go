@@ -14,9 +14,12 @@ import (
 	authrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/repository"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/bootstrap"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
 	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
 	srv "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/server"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/health"
 )
 
 func main() {
@@ -28,6 +31,7 @@ func main() {
 
 	refreshTokenRepo := authrepo.NewPgRefreshTokenRepository(app.Pool)
 	revokedTokenRepo := authrepo.NewPgRevokedTokenRepository(app.Pool)
+	roleRepo := authrepo.NewPgRoleRepository(app.Pool)
 	hasher := &commoncrypto.BcryptHasher{}
 	idGenerator := &commoncrypto.UUIDGenerator{}
 	authService := service.NewAuthService(
@@ -36,6 +40,8 @@ func main() {
 			IdentityService:  app.IdentityService,
 			RefreshTokenRepo: refreshTokenRepo,
 			RevokedTokenRepo: revokedTokenRepo,
+			RoleRepo:         roleRepo,
+			Audit:            app.AuditService,
 			Hasher:           hasher,
 			IDGenerator:      idGenerator,
 			Log:              app.Log,
@@ -45,9 +51,14 @@ func main() {
 			AccessTokenTTL:          app.Config.AccessTokenTTL,
 			RefreshTokenTTL:         app.Config.RefreshTokenTTL,
 			MaxRefreshTokens:        app.Config.MaxRefreshTokensPerUser,
+			ScopedTokenMaxTTL:       app.Config.ScopedTokenMaxTTL,
+			BotTokenMaxTTL:          app.Config.BotTokenMaxTTL,
 			CircuitBreakerThreshold: app.Config.CircuitBreakerThreshold,
 			CircuitBreakerTimeout:   app.Config.CircuitBreakerTimeout,
 			CircuitBreakerReset:     app.Config.CircuitBreakerReset,
+			CircuitBreakerWindow:    app.Config.CircuitBreakerWindow,
+			CircuitBreakerRate:      app.Config.CircuitBreakerRate,
+			CircuitBreakerHalfOpen:  app.Config.CircuitBreakerHalfOpen,
 		},
 	)
 
@@ -55,7 +66,7 @@ func main() {
 	defer cancel()
 
 	var cleanupWg sync.WaitGroup
-	cleanupWg.Add(2)
+	cleanupWg.Add(3)
 	go func() {
 		defer cleanupWg.Done()
 		authcleanup.StartRefreshTokenCleanup(ctx, refreshTokenRepo, app.Log)
@@ -64,12 +75,26 @@ func main() {
 		defer cleanupWg.Done()
 		authcleanup.StartRevokedTokenCleanup(ctx, revokedTokenRepo, app.Log)
 	}()
+	go func() {
+		defer cleanupWg.Done()
+		app.Log.WatchReloadSignal(ctx)
+	}()
+
+	app.Health.AddReadiness(health.CircuitBreakerChecker(constants.CircuitBreakerDatabaseName, authService.DatabaseCircuitOpen))
 
 	handler := authhttp.NewHandler(authService, app.Config, app.Log)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", commonhttp.HealthHandler(app.Log))
+	mux.HandleFunc("/livez", commonhttp.LivenessHandler(app.Health))
+	mux.HandleFunc("/readyz", commonhttp.ReadinessHandler(app.Health))
 	mux.Handle("/metrics", promhttp.Handler())
+
+	jwtMw := jwtverify.Middleware(app.Config.JWTSecret, app.Log, revokedTokenRepo)
+	mux.Handle("/api/auth/tokens", jwtMw(jwtverify.RequireScope(jwtverify.ScopeTokensIssue)(handler)))
+	mux.Handle("/api/auth/audit", jwtMw(jwtverify.RequireScope(jwtverify.ScopeProfileRead)(handler)))
+	mux.Handle("/api/auth/users/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeRolesAdmin)(handler)))
+	mux.Handle("/api/auth/admin/log-level", jwtMw(jwtverify.RequireScope(jwtverify.ScopeLogsAdmin)(commonhttp.LogLevelHandler(app.Log))))
 	mux.Handle("/", handler)
 
 	baseHandler := commonhttp.BuildBaseHandler("auth", app.Log, mux)
@@ -79,6 +104,12 @@ func main() {
 	server := srv.NewServer(serverConfig, finalHandler)
 
 	shutdownHooks := []srv.ShutdownHook{
+		func(ctx context.Context) error {
+			app.Log.Infof("auth service: failing readiness probe")
+			app.Health.MarkShuttingDown()
+			srv.WaitForDrain(ctx, constants.ReadinessDrainDelay, app.Log, "auth")
+			return nil
+		},
 		func(ctx context.Context) error {
 			app.Log.Infof("auth service: stopping cleanup goroutines")
 			cancel()
@@ -90,6 +121,14 @@ func main() {
 			authService.CloseRefreshTokenCache()
 			return nil
 		},
+		func(ctx context.Context) error {
+			app.Log.Infof("auth service: closing audit exporter")
+			return app.AuditService.Close()
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("auth service: flushing traces")
+			return app.ShutdownTracing(ctx)
+		},
 		func(ctx context.Context) error {
 			app.Log.Infof("auth service: closing database pool")
 			srv.ClosePoolWithTimeout(ctx, app.Pool, app.Log, "auth")
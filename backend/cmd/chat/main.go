@@ -16,12 +16,32 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket/middleware"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/bootstrap"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
 	srv "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/server"
+	conversationrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/repository"
+	conversationservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/service"
+	disappearingcleanup "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/cleanup"
+	disappearingrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/repository"
+	disappearingservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/service"
 	identityhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/health"
+	privacyrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/repository"
+	privacyservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/service"
+	pushdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	pushnotifier "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/notifier"
+	pushrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/repository"
+	pushservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/service"
+	reactionrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/repository"
+	reactionservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/service"
+	receiptrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/repository"
+	receiptservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/service"
+	webhookcleanup "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/cleanup"
+	webhookrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/repository"
+	webhookservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/service"
 )
 
 func main() {
@@ -31,17 +51,78 @@ func main() {
 		os.Exit(1)
 	}
 
-	chatSvc := chatservice.NewChatService(chatservice.ChatServiceDeps{
-		Repo:            app.UserRepo,
-		IdentityService: app.IdentityService,
-		Log:             app.Log,
+	searchBulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: app.Config.SearchMaxConcurrent,
+		MaxLimit:      constants.SearchBulkheadMaxLimit,
+		MaxQueue:      constants.SearchBulkheadQueue,
+		Adaptive:      true,
+		TargetLatency: constants.SearchBulkheadTargetLatency,
+		Name:          constants.SearchBulkheadName,
+		Logger:        app.Log,
+	})
+	receiptRepo := receiptrepo.NewPgRepository(app.Pool)
+	receiptSvc := receiptservice.NewReceiptService(context.Background(), receiptservice.ReceiptServiceDeps{
+		Repo:       receiptRepo,
+		EditWindow: app.Config.MessageEditWindow,
+		Log:        app.Log.Component("receipts"),
+	})
+	reactionRepo := reactionrepo.NewPgRepository(app.Pool)
+	reactionSvc := reactionservice.NewReactionService(reactionservice.ReactionServiceDeps{
+		Repo: reactionRepo,
+		Log:  app.Log.Component("reactions"),
+	})
+	privacySvc := privacyservice.NewPrivacyService(privacyservice.PrivacyServiceDeps{
+		Repo: privacyrepo.NewPgRepository(app.Pool),
+		Log:  app.Log.Component("privacy"),
+	})
+	conversationSvc := conversationservice.NewConversationService(context.Background(), conversationservice.ConversationServiceDeps{
+		Repo: conversationrepo.NewPgRepository(app.Pool),
+		Log:  app.Log.Component("conversations"),
+	})
+	timerSvc := disappearingservice.NewTimerService(disappearingservice.TimerServiceDeps{
+		Repo: disappearingrepo.NewPgRepository(app.Pool),
+		Log:  app.Log.Component("disappearing"),
+	})
+	pushClient := pushnotifier.NewHTTPClient(app.Config.PushTimeout)
+	pushNotifiers := make(map[pushdomain.Provider]pushnotifier.Notifier)
+	var vapidPublicKey string
+	if app.Config.PushVAPIDPrivateKey != "" {
+		webPush, err := pushnotifier.NewWebPushNotifier(pushnotifier.WebPushConfig{
+			PrivateKey: app.Config.PushVAPIDPrivateKey,
+			Subject:    app.Config.PushVAPIDSubject,
+			Client:     commonhttp.NewPublicClient(app.Config.PushTimeout),
+		})
+		if err != nil {
+			app.Log.Fatalf("failed to configure web push: %v", err)
+		}
+		pushNotifiers[pushdomain.ProviderWebPush] = webPush
+		vapidPublicKey = webPush.PublicKey()
+	}
+	if app.Config.PushWebhookURL != "" {
+		pushNotifiers[pushdomain.ProviderWebhook] = pushnotifier.NewWebhookNotifier(pushnotifier.WebhookConfig{
+			URL:    app.Config.PushWebhookURL,
+			Client: pushClient,
+		})
+	}
+	pushSvc := pushservice.NewPushService(context.Background(), pushservice.PushServiceDeps{
+		Repo:           pushrepo.NewPgRepository(app.Pool),
+		Notifiers:      pushNotifiers,
+		VAPIDPublicKey: vapidPublicKey,
+		Timeout:        app.Config.PushTimeout,
+		Log:            app.Log.Component("push"),
+	})
+	webhookRepo := webhookrepo.NewPgRepository(app.Pool)
+	webhookSvc := webhookservice.NewWebhookService(context.Background(), webhookservice.WebhookServiceDeps{
+		Repo:    webhookRepo,
+		Timeout: app.Config.WebhookTimeout,
+		Log:     app.Log.Component("webhooks"),
 	})
 
 	hubConfig := websocket.HubConfig{
 		MaxFileSize:             constants.MaxFileSizeBytes,
 		MaxVoiceSize:            constants.MaxVoiceSizeBytes,
-		ProcessorWorkers:        constants.WebSocketProcessorWorkers,
-		ProcessorQueueSize:      constants.WebSocketProcessorQueueSize,
+		ProcessorWorkers:        app.Config.WebSocketProcessorWorkers,
+		ProcessorQueueSize:      app.Config.WebSocketProcessorQueueSize,
 		LastSeenUpdateInterval:  app.Config.LastSeenUpdateInterval,
 		CircuitBreakerThreshold: app.Config.CircuitBreakerThreshold,
 		CircuitBreakerTimeout:   app.Config.CircuitBreakerTimeout,
@@ -54,37 +135,92 @@ func main() {
 	}
 
 	clk := clock.NewRealClock()
+	wsLog := app.Log.Component("websocket")
 	hub := websocket.NewHub(websocket.HubDeps{
-		Log:   app.Log,
+		Log:   wsLog,
 		Clock: clk,
 	}, hubConfig)
 
 	lastSeenCB := resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{
-		Threshold:  hubConfig.CircuitBreakerThreshold,
-		Timeout:    hubConfig.CircuitBreakerTimeout,
-		ResetAfter: hubConfig.CircuitBreakerReset,
-		Name:       "last_seen_update",
-		Logger:     app.Log,
+		Threshold:        hubConfig.CircuitBreakerThreshold,
+		Timeout:          hubConfig.CircuitBreakerTimeout,
+		ResetAfter:       hubConfig.CircuitBreakerReset,
+		Window:           app.Config.CircuitBreakerWindow,
+		FailureRate:      app.Config.CircuitBreakerRate,
+		HalfOpenMaxCalls: app.Config.CircuitBreakerHalfOpen,
+		Clock:            clk,
+		Name:             "last_seen_update",
+		Logger:           app.Log,
+	})
+	presenceBulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: constants.PresenceBulkheadConcurrency,
+		MaxQueue:      constants.PresenceBulkheadQueue,
+		QueueTimeout:  constants.LastSeenUpdateTimeout,
+		Clock:         clk,
+		Name:          constants.PresenceBulkheadName,
+		Logger:        app.Log,
 	})
 	presenceService := websocket.NewPresenceService(hub.Context(), websocket.PresenceServiceDeps{
-		Sender:   hub,
-		UserRepo: app.UserRepo,
-		Log:      app.Log,
-		Clock:    clk,
+		Sender:    hub,
+		UserRepo:  app.UserRepo,
+		Privacy:   privacySvc,
+		Directory: hub,
+		Log:       wsLog,
+		Clock:     clk,
 	}, websocket.PresenceServiceConfig{
 		LastSeenUpdateInterval: hubConfig.LastSeenUpdateInterval,
 		CircuitBreaker:         lastSeenCB,
+		Bulkhead:               presenceBulkhead,
 	})
 
-	fileService := websocket.NewFileTransferService(hub, hubConfig.FileTransferTimeout, clk, app.Log, hub.Context())
+	fileService := websocket.NewFileTransferService(hub, hubConfig.FileTransferTimeout, clk, wsLog, hub.Context())
 
 	validator := websocket.NewDefaultValidator(hubConfig.MaxFileSize, hubConfig.MaxVoiceSize)
-	router := websocket.NewMessageRouter(hub, presenceService, fileService, validator, app.Log, hubConfig.DebugSampleRate)
-	processor := websocket.NewMessageProcessor(hubConfig.ProcessorWorkers, router, app.Log, hubConfig.ProcessorQueueSize)
+	sequenceTracker := websocket.NewSequenceTracker(hub.Context(), constants.WebSocketSequenceIdleTTL, clk)
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:        hub,
+		Presence:      presenceService,
+		FileService:   fileService,
+		Validator:     validator,
+		Sequences:     sequenceTracker,
+		Receipts:      receiptSvc,
+		Privacy:       privacySvc,
+		Conversations: conversationSvc,
+		Timers:        timerSvc,
+		Push:          pushSvc,
+		Webhooks:      webhookSvc,
+		Reactions:     reactionSvc,
+		Clock:         clk,
+		Log:           wsLog,
+	}, websocket.MessageRouterConfig{
+		DebugSampleRate: hubConfig.DebugSampleRate,
+	})
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{
+		Router: router,
+		Log:    wsLog,
+	}, websocket.MessageProcessorConfig{
+		Workers:         hubConfig.ProcessorWorkers,
+		QueueSize:       hubConfig.ProcessorQueueSize,
+		ClientQueueSize: constants.WebSocketProcessorClientQueueSize,
+	})
+
+	chatSvc := chatservice.NewChatService(chatservice.ChatServiceDeps{
+		Repo:            app.UserRepo,
+		IdentityService: app.IdentityService,
+		SearchBulkhead:  searchBulkhead,
+		Receipts:        receiptSvc,
+		Reactions:       reactionSvc,
+		Privacy:         privacySvc,
+		Conversations:   conversationSvc,
+		Push:            pushSvc,
+		Webhooks:        webhookSvc,
+		Messenger:       router,
+		Log:             app.Log,
+	})
 
 	idempotencyTracker := websocket.NewIdempotencyTracker(hub.Context(), hubConfig.IdempotencyTTL, clk)
 	idempotencyAdapter := &websocket.IdempotencyAdapter{Tracker: idempotencyTracker}
-	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyAdapter, app.Log)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyAdapter, wsLog)
 	messageHandler := websocket.NewIncomingMessageHandler(idempotencyTracker, idempotencyMiddleware, processor)
 
 	hub.Wire(messageHandler, presenceService, fileService)
@@ -97,20 +233,108 @@ func main() {
 		hub.Run(ctx)
 	}()
 
+	hubHeartbeat := health.HeartbeatChecker("websocket_hub", hub, constants.WebSocketHubHeartbeatMaxAge, clk)
+	app.Health.AddLiveness(hubHeartbeat)
+	app.Health.AddReadiness(
+		hubHeartbeat,
+		health.QueueSaturationChecker("websocket_processor_queue", processor, constants.WebSocketProcessorSaturationThreshold),
+		health.Optional(health.CircuitBreakerChecker("last_seen_update", lastSeenCB.IsOpen)),
+	)
+
+	revocationListener := authrepo.NewPgRevocationListener(app.Pool)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		hub.WatchRevocations(revocationListener.Listen)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		app.Log.WatchReloadSignal(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		privacySvc.StartCleanup(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pushSvc.StartCleanup(ctx)
+	}()
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		webhookSvc.StartCleanup(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		webhookcleanup.StartDeadLetterCleanup(ctx, webhookRepo, app.Log.Component("webhooks"))
+	}()
+
+	disappearingLog := app.Log.Component("disappearing")
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		timerSvc.StartCleanup(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		disappearingcleanup.StartReceiptCleanup(ctx, receiptRepo, disappearingLog)
+	}()
+	go func() {
+		defer wg.Done()
+		disappearingcleanup.StartReactionCleanup(ctx, reactionRepo, disappearingLog)
+	}()
+	go func() {
+		defer wg.Done()
+		disappearingcleanup.StartFileTransferCleanup(ctx, fileService, disappearingLog)
+	}()
+
 	handler := chathttp.NewHandler(chatSvc, hub, app.Config, app.Log, app.Pool)
 
+	configWatcher := config.NewChatConfigWatcher(app.Config, app.Log)
+	configWatcher.Subscribe(func(cfg config.ChatConfig) {
+		hub.SetMaxConnections(cfg.WebSocketMaxConnections)
+		hub.SetSendTimeout(cfg.WebSocketSendTimeout)
+		processor.Resize(cfg.WebSocketProcessorWorkers)
+		searchBulkhead.SetLimit(cfg.SearchMaxConcurrent)
+		receiptSvc.SetEditWindow(cfg.MessageEditWindow)
+		handler.ApplyConfig(cfg)
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		configWatcher.Watch(ctx)
+	}()
+
 	restMux := http.NewServeMux()
 	restMux.HandleFunc("/health", commonhttp.HealthHandler(app.Log))
+	restMux.HandleFunc("/livez", commonhttp.LivenessHandler(app.Health))
+	restMux.HandleFunc("/readyz", commonhttp.ReadinessHandler(app.Health))
 	restMux.Handle("/metrics", promhttp.Handler())
 
 	identityHandler := identityhttp.NewHandler(app.IdentityService, app.Log)
 
 	revokedTokenRepo := authrepo.NewPgRevokedTokenRepository(app.Pool)
 	jwtMw := jwtverify.Middleware(app.Config.JWTSecret, app.Log, revokedTokenRepo)
-	restMux.Handle("/api/chat/me", jwtMw(handler))
-	restMux.Handle("/api/chat/users", jwtMw(handler))
-	restMux.Handle("/api/chat/users/", jwtMw(handler))
-	restMux.Handle("/api/identity/", jwtMw(identityHandler))
+	restMux.Handle("/api/chat/me", jwtMw(jwtverify.RequireScope(jwtverify.ScopeProfileRead)(handler)))
+	restMux.Handle("/api/chat/users", jwtMw(jwtverify.RequireScope(jwtverify.ScopeUsersRead)(handler)))
+	restMux.Handle("/api/chat/users/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeIdentityRead)(handler)))
+	restMux.Handle("/api/chat/me/privacy", jwtMw(jwtverify.RequireScope(jwtverify.ScopeChatConnect)(handler)))
+	restMux.Handle("/api/chat/conversations", jwtMw(jwtverify.RequireScope(jwtverify.ScopeChatConnect)(handler)))
+	restMux.Handle("/api/chat/conversations/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeChatConnect)(handler)))
+	restMux.Handle("/api/chat/push/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeChatConnect)(handler)))
+	restMux.Handle("/api/chat/bot/messages", jwtMw(jwtverify.RequireScope(jwtverify.ScopeMessagesSend)(handler)))
+	restMux.Handle("/api/chat/bot/webhooks", jwtMw(jwtverify.RequireScope(jwtverify.ScopeWebhooksManage)(handler)))
+	restMux.Handle("/api/chat/bot/webhooks/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeWebhooksManage)(handler)))
+	restMux.Handle("/api/identity/update-public-key", jwtMw(jwtverify.RequireScope(jwtverify.ScopeIdentityWrite)(identityHandler)))
+	restMux.Handle("/api/identity/", jwtMw(jwtverify.RequireScope(jwtverify.ScopeIdentityRead)(identityHandler)))
+	restMux.Handle("/api/chat/admin/log-level", jwtMw(jwtverify.RequireScope(jwtverify.ScopeLogsAdmin)(commonhttp.LogLevelHandler(app.Log))))
 
 	wrappedRestMux := commonhttp.BuildBaseHandler("chat", app.Log, restMux)
 
@@ -122,6 +346,19 @@ func main() {
 	server := srv.NewServer(serverConfig, mainMux)
 
 	shutdownHooks := []srv.ShutdownHook{
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: failing readiness probe")
+			app.Health.MarkShuttingDown()
+			srv.WaitForDrain(ctx, constants.ReadinessDrainDelay, app.Log, "chat")
+			return nil
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: draining WebSocket hub")
+			drainCtx, drainCancel := context.WithTimeout(ctx, constants.WebSocketDrainTimeout)
+			defer drainCancel()
+			hub.Drain(drainCtx)
+			return nil
+		},
 		func(ctx context.Context) error {
 			app.Log.Infof("chat service: shutting down WebSocket hub")
 			hub.Shutdown()
@@ -129,6 +366,34 @@ func main() {
 			srv.WaitGroupWithTimeout(ctx, &wg, app.Log, "chat service: WebSocket hub stopped")
 			return nil
 		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: flushing message receipts")
+			receiptSvc.Stop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: flushing conversation updates")
+			conversationSvc.Stop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: stopping push notifications")
+			pushSvc.Stop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: stopping webhook deliveries")
+			webhookSvc.Stop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: closing audit exporter")
+			return app.AuditService.Close()
+		},
+		func(ctx context.Context) error {
+			app.Log.Infof("chat service: flushing traces")
+			return app.ShutdownTracing(ctx)
+		},
 		func(ctx context.Context) error {
 			app.Log.Infof("chat service: closing database pool")
 			srv.ClosePoolWithTimeout(ctx, app.Pool, app.Log, "chat")
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type EventType string
+
+const (
+	EventRegister            EventType = "register"
+	EventLoginSuccess        EventType = "login_success"
+	EventLoginFailed         EventType = "login_failed"
+	EventTokenRefreshed      EventType = "token_refreshed"
+	EventRefreshFailed       EventType = "refresh_failed"
+	EventRefreshTokenRevoked EventType = "refresh_token_revoked"
+	EventAccessTokenRevoked  EventType = "access_token_revoked"
+	EventLogout              EventType = "logout"
+	EventIdentityKeyCreated  EventType = "identity_key_created"
+	EventIdentityKeyChanged  EventType = "identity_key_changed"
+	EventRolesChanged        EventType = "roles_changed"
+	EventScopedTokenIssued   EventType = "scoped_token_issued"
+)
+
+type Event struct {
+	ID        string
+	UserID    string
+	Type      EventType
+	ClientIP  string
+	UserAgent string
+	TraceID   string
+	Metadata  map[string]string
+	CreatedAt time.Time
+}
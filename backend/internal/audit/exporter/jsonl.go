@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+)
+
+type Exporter interface {
+	Export(event domain.Event) error
+	Close() error
+}
+
+type record struct {
+	ID        string            `json:"id"`
+	Timestamp string            `json:"timestamp"`
+	Service   string            `json:"service"`
+	EventType string            `json:"event_type"`
+	UserID    string            `json:"user_id,omitempty"`
+	ClientIP  string            `json:"client_ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type JSONLinesExporter struct {
+	mu      sync.Mutex
+	writer  io.WriteCloser
+	service string
+}
+
+func NewJSONLinesExporter(path string, service string) *JSONLinesExporter {
+	return NewJSONLinesExporterWithWriter(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    constants.AuditExportMaxSize,
+		MaxBackups: constants.AuditExportMaxBackups,
+		MaxAge:     constants.AuditExportMaxAge,
+	}, service)
+}
+
+func NewJSONLinesExporterWithWriter(writer io.WriteCloser, service string) *JSONLinesExporter {
+	return &JSONLinesExporter{
+		writer:  writer,
+		service: service,
+	}
+}
+
+func (e *JSONLinesExporter) Export(event domain.Event) error {
+	line, err := json.Marshal(record{
+		ID:        event.ID,
+		Timestamp: event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		Service:   e.service,
+		EventType: string(event.Type),
+		UserID:    event.UserID,
+		ClientIP:  event.ClientIP,
+		UserAgent: event.UserAgent,
+		TraceID:   event.TraceID,
+		Metadata:  event.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.writer.Write(line)
+	return err
+}
+
+func (e *JSONLinesExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.writer.Close()
+}
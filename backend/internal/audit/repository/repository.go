@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+)
+
+type Repository interface {
+	Append(ctx context.Context, event domain.Event) error
+	ListByUserID(ctx context.Context, userID string, before time.Time, limit int) ([]domain.Event, error)
+}
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+func (r *PgRepository) Append(ctx context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	eventMetadata := event.Metadata
+	if eventMetadata == nil {
+		eventMetadata = map[string]string{}
+	}
+	metadata, err := json.Marshal(eventMetadata)
+	if err != nil {
+		return err
+	}
+
+	var userID any
+	if event.UserID != "" {
+		userID = event.UserID
+	}
+
+	start := time.Now()
+	_, err = r.pool.Exec(
+		ctx,
+		`INSERT INTO audit_events (id, user_id, event_type, client_ip, user_agent, trace_id, metadata, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8)`,
+		event.ID,
+		userID,
+		string(event.Type),
+		event.ClientIP,
+		event.UserAgent,
+		event.TraceID,
+		string(metadata),
+		event.CreatedAt,
+	)
+	return db.HandleExecError(err, "append audit event", start)
+}
+
+func (r *PgRepository) ListByUserID(ctx context.Context, userID string, before time.Time, limit int) ([]domain.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT id, user_id, event_type, client_ip, user_agent, trace_id, metadata, created_at
+		 FROM audit_events
+		 WHERE user_id = $1 AND created_at < $2
+		 ORDER BY created_at DESC
+		 LIMIT $3`,
+		userID,
+		before,
+		limit,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list audit events", start)
+	}
+	defer rows.Close()
+
+	events := make([]domain.Event, 0, limit)
+	for rows.Next() {
+		var event domain.Event
+		var eventType string
+		var metadata []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&eventType,
+			&event.ClientIP,
+			&event.UserAgent,
+			&event.TraceID,
+			&metadata,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, db.HandleQueryError(err, nil, "list audit events", start)
+		}
+		event.Type = domain.EventType(eventType)
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+				return nil, db.HandleQueryError(err, nil, "list audit events", start)
+			}
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list audit events", start)
+	}
+
+	db.MeasureQueryDuration("list audit events", start)
+	return events, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/exporter"
+	auditrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/repository"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type Service interface {
+	Record(ctx context.Context, event domain.Event)
+	ListForUser(ctx context.Context, userID string, before time.Time, limit int) ([]domain.Event, error)
+	Close() error
+}
+
+type AuditService struct {
+	repo        auditrepo.Repository
+	exporter    exporter.Exporter
+	idGenerator commoncrypto.IDGenerator
+	clock       clock.Clock
+	log         *logger.Logger
+}
+
+type AuditServiceDeps struct {
+	Repo        auditrepo.Repository
+	Exporter    exporter.Exporter
+	IDGenerator commoncrypto.IDGenerator
+	Clock       clock.Clock
+	Log         *logger.Logger
+}
+
+func NewAuditService(deps AuditServiceDeps) *AuditService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	idGenerator := deps.IDGenerator
+	if idGenerator == nil {
+		idGenerator = &commoncrypto.UUIDGenerator{}
+	}
+
+	return &AuditService{
+		repo:        deps.Repo,
+		exporter:    deps.Exporter,
+		idGenerator: idGenerator,
+		clock:       timeClock,
+		log:         deps.Log,
+	}
+}
+
+func (s *AuditService) Record(ctx context.Context, event domain.Event) {
+	if event.ID == "" {
+		id, err := s.idGenerator.NewID()
+		if err != nil {
+			metrics.AuditEventsFailed.WithLabelValues("id").Inc()
+			s.log.WithFields(ctx, logger.Fields{
+				"event_type": string(event.Type),
+				"action":     "audit_event_id_failed",
+			}).Errorf("failed to generate audit event id: %v", err)
+			return
+		}
+		event.ID = id
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = s.clock.Now()
+	}
+	enrichFromContext(ctx, &event)
+
+	if s.repo != nil {
+		if err := s.repo.Append(context.WithoutCancel(ctx), event); err != nil {
+			metrics.AuditEventsFailed.WithLabelValues("database").Inc()
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id":    event.UserID,
+				"event_type": string(event.Type),
+				"action":     "audit_event_store_failed",
+			}).Errorf("failed to store audit event: %v", err)
+		}
+	}
+
+	if s.exporter != nil {
+		if err := s.exporter.Export(event); err != nil {
+			metrics.AuditEventsFailed.WithLabelValues("exporter").Inc()
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id":    event.UserID,
+				"event_type": string(event.Type),
+				"action":     "audit_event_export_failed",
+			}).Errorf("failed to export audit event: %v", err)
+		}
+	}
+
+	metrics.AuditEventsRecorded.WithLabelValues(string(event.Type)).Inc()
+}
+
+func (s *AuditService) ListForUser(ctx context.Context, userID string, before time.Time, limit int) ([]domain.Event, error) {
+	if s.repo == nil {
+		return []domain.Event{}, nil
+	}
+	if limit <= 0 || limit > constants.MaxAuditListLimit {
+		limit = constants.DefaultAuditListLimit
+	}
+	if before.IsZero() {
+		before = s.clock.Now().Add(time.Second)
+	}
+
+	events, err := s.repo.ListByUserID(ctx, userID, before, limit)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "audit_list_failed",
+		}).Errorf("failed to list audit events: %v", err)
+		return nil, commonerrors.ErrAuditListFailed.WithCause(err)
+	}
+	return events, nil
+}
+
+func (s *AuditService) Close() error {
+	if s.exporter == nil {
+		return nil
+	}
+	return s.exporter.Close()
+}
+
+func enrichFromContext(ctx context.Context, event *domain.Event) {
+	if event.TraceID == "" {
+		if traceID, ok := ctx.Value(constants.TraceIDKey).(string); ok {
+			event.TraceID = traceID
+		}
+	}
+	if event.ClientIP == "" {
+		if clientIP, ok := ctx.Value(constants.ClientIPKey).(string); ok {
+			event.ClientIP = clientIP
+		}
+	}
+	if event.UserAgent == "" {
+		if userAgent, ok := ctx.Value(constants.UserAgentKey).(string); ok {
+			event.UserAgent = userAgent
+		}
+	}
+}
+
+type NopService struct{}
+
+func (NopService) Record(ctx context.Context, event domain.Event) {}
+
+func (NopService) ListForUser(ctx context.Context, userID string, before time.Time, limit int) ([]domain.Event, error) {
+	return []domain.Event{}, nil
+}
+
+func (NopService) Close() error {
+	return nil
+}
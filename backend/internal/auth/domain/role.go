@@ -0,0 +1,49 @@
+package domain
+
+import "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+	RoleBot   Role = "bot"
+)
+
+var DefaultRoles = []string{string(RoleUser)}
+
+var roleScopes = map[Role][]string{
+	RoleUser: jwtverify.DefaultScopes,
+	RoleAdmin: append(append([]string(nil), jwtverify.DefaultScopes...),
+		jwtverify.ScopeRolesAdmin,
+		jwtverify.ScopeLogsAdmin,
+	),
+	RoleBot: {
+		jwtverify.ScopeUsersRead,
+		jwtverify.ScopeIdentityRead,
+		jwtverify.ScopeIdentityWrite,
+		jwtverify.ScopeTokensIssue,
+		jwtverify.ScopeMessagesSend,
+		jwtverify.ScopeWebhooksManage,
+	},
+}
+
+func IsKnownRole(role string) bool {
+	_, ok := roleScopes[Role(role)]
+	return ok
+}
+
+func ScopesForRoles(roles []string) []string {
+	seen := make(map[string]struct{})
+	scopes := make([]string, 0, len(jwtverify.DefaultScopes)+1)
+	for _, role := range roles {
+		for _, scope := range roleScopes[Role(role)] {
+			if _, ok := seen[scope]; ok {
+				continue
+			}
+			seen[scope] = struct{}{}
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
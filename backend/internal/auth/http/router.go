@@ -3,10 +3,14 @@ package http
 import (
 	"encoding/base64"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
@@ -27,6 +31,35 @@ type tokenResponse struct {
 	Token string `json:"token"`
 }
 
+type scopedTokenRequest struct {
+	Scopes     []string `json:"scopes"`
+	TTLSeconds int64    `json:"ttl_seconds"`
+}
+
+type scopedTokenResponse struct {
+	Token     string    `json:"token"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type auditEventResponse struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ClientIP  string            `json:"client_ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type rolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+type rolesResponse struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
 type Handler struct {
 	auth *service.AuthService
 	log  *logger.Logger
@@ -43,6 +76,9 @@ func NewHandler(auth service.Service, cfg config.AuthConfig, log *logger.Logger)
 	mux.HandleFunc("/api/auth/refresh", commonhttp.RequireMethod(http.MethodPost)(commonhttp.WithTimeout(cfg.RequestTimeout)(h.refresh)))
 	mux.HandleFunc("/api/auth/logout", commonhttp.RequireMethod(http.MethodPost)(commonhttp.WithTimeout(cfg.RequestTimeout)(h.logout)))
 	mux.HandleFunc("/api/auth/revoke", commonhttp.RequireMethod(http.MethodPost)(commonhttp.WithTimeout(cfg.RequestTimeout)(h.revoke)))
+	mux.HandleFunc("/api/auth/tokens", commonhttp.RequireMethod(http.MethodPost)(commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.issueScopedToken))))
+	mux.HandleFunc("/api/auth/audit", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.listAudit))))
+	mux.HandleFunc("/api/auth/users/", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.userRoles)))
 	return mux
 }
 
@@ -127,11 +163,11 @@ func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var claims jwtverify.Claims
-	var err error
 	if ctxClaims, ok := jwtverify.FromContext(ctx); ok {
 		claims = ctxClaims
 	} else {
 		if tokenString, ok := jwtverify.ExtractTokenFromHeader(r); ok {
+			var err error
 			claims, err = h.auth.ParseTokenForRevoke(ctx, tokenString)
 			if err != nil {
 				claims = jwtverify.Claims{}
@@ -139,17 +175,13 @@ func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err == nil && claims.JTI != "" {
-		if err := h.auth.RevokeAccessToken(ctx, claims.JTI, claims.UserID); err != nil {
-			h.log.Errorf("logout revoke access token failed: %v", err)
-		}
+	input := service.LogoutInput{Claims: claims}
+	if cookie, cookieErr := r.Cookie("refresh_token"); cookieErr == nil {
+		input.RefreshToken = cookie.Value
 	}
 
-	cookie, err := r.Cookie("refresh_token")
-	if err == nil && cookie.Value != "" {
-		if err := h.auth.RevokeRefreshToken(ctx, cookie.Value); err != nil {
-			h.log.Errorf("logout revoke refresh token failed: %v", err)
-		}
+	if err := h.auth.Logout(ctx, input); err != nil {
+		h.log.Errorf("logout revoke tokens failed: %v", err)
 	}
 
 	clearRefreshCookie(w, r)
@@ -181,7 +213,7 @@ func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.auth.RevokeAccessToken(ctx, claims.JTI, claims.UserID); err != nil {
+	if err := h.auth.RevokeAccessTokenUntil(ctx, claims.JTI, claims.UserID, claims.ExpiresAt); err != nil {
 		commonhttp.HandleError(w, r, err, h.log)
 		return
 	}
@@ -189,6 +221,124 @@ func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) issueScopedToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	var req scopedTokenRequest
+	if err := commonhttp.DecodeJSON(r, &req); err != nil {
+		h.log.Warnf("issue scoped token failed: invalid json: %v", err)
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+		return
+	}
+	if req.TTLSeconds < 0 {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "ttl_seconds must not be negative", nil, "")
+		return
+	}
+
+	result, err := h.auth.IssueScopedToken(ctx, claims, service.ScopedTokenInput{
+		Scopes: req.Scopes,
+		TTL:    time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	commonhttp.WriteJSON(w, http.StatusCreated, scopedTokenResponse{
+		Token:     result.Token,
+		Scopes:    result.Scopes,
+		ExpiresAt: result.ExpiresAt,
+	})
+}
+
+func (h *Handler) listAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	limit := constants.DefaultAuditListLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= constants.MaxAuditListLimit {
+			limit = v
+		}
+	}
+
+	var before time.Time
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		parsed, err := time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "before must be RFC3339 timestamp", nil, "")
+			return
+		}
+		before = parsed
+	}
+
+	events, err := h.auth.ListAuditEvents(ctx, claims.UserID, before, limit)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	resp := make([]auditEventResponse, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, auditEventResponse{
+			ID:        event.ID,
+			Type:      string(event.Type),
+			ClientIP:  event.ClientIP,
+			UserAgent: event.UserAgent,
+			Metadata:  event.Metadata,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) userRoles(w http.ResponseWriter, r *http.Request) {
+	urlPath := r.URL.Path
+	if !strings.HasSuffix(urlPath, "/roles") {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidPath, "invalid path", nil, "")
+		return
+	}
+
+	userID, err := commonhttp.ExtractAndValidateUserID(urlPath, "/roles")
+	if err != nil {
+		if err == commonerrors.ErrEmptyUUID {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeUserIDRequired, "user_id is required", nil, "")
+			return
+		}
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidUserIDFormat, "invalid user_id format (must be UUID)", nil, "")
+		return
+	}
+
+	ctx := r.Context()
+
+	var roles []string
+	switch r.Method {
+	case http.MethodGet:
+		roles, err = h.auth.GetUserRoles(ctx, userID)
+	case http.MethodPut:
+		var req rolesRequest
+		if decodeErr := commonhttp.DecodeJSON(r, &req); decodeErr != nil {
+			h.log.Warnf("set user roles failed: invalid json: %v", decodeErr)
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+			return
+		}
+		roles, err = h.auth.SetUserRoles(ctx, userID, req.Roles)
+	default:
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+		return
+	}
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	commonhttp.WriteJSON(w, http.StatusOK, rolesResponse{
+		UserID: userID,
+		Roles:  roles,
+	})
+}
+
 func setRefreshCookie(w http.ResponseWriter, r *http.Request, token string, expiresAt time.Time) {
 	if token == "" {
 		return
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+)
+
+type revocationNotification struct {
+	JTI    string `json:"jti"`
+	UserID string `json:"user_id"`
+}
+
+type PgRevocationListener struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRevocationListener(pool *pgxpool.Pool) *PgRevocationListener {
+	return &PgRevocationListener{pool: pool}
+}
+
+func (l *PgRevocationListener) Listen(ctx context.Context, onRevoked func(jti, userID string)) error {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire listener connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{constants.TokenRevokedChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", constants.TokenRevokedChannel, err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		var payload revocationNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+			continue
+		}
+		onRevoked(payload.JTI, payload.UserID)
+	}
+}
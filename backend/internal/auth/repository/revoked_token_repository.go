@@ -33,12 +33,18 @@ func (r *PgRevokedTokenRepository) Revoke(ctx context.Context, jti string, userI
 	start := time.Now()
 	_, err := r.pool.Exec(
 		ctx,
-		`INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
-		 VALUES ($1, $2, $3, NOW())
-		 ON CONFLICT (jti) DO NOTHING`,
+		`WITH inserted AS (
+			INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (jti) DO NOTHING
+			RETURNING jti, user_id
+		)
+		SELECT pg_notify($4, json_build_object('jti', jti, 'user_id', user_id)::text)
+		FROM inserted`,
 		jti,
 		userID,
 		expiresAt,
+		constants.TokenRevokedChannel,
 	)
 	return db.HandleExecError(err, "revoke token", start)
 }
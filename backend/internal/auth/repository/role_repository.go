@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+)
+
+type RoleRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]string, error)
+	ReplaceRoles(ctx context.Context, userID string, roles []string) error
+}
+
+type PgRoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRoleRepository(pool *pgxpool.Pool) *PgRoleRepository {
+	return &PgRoleRepository{pool: pool}
+}
+
+func (r *PgRoleRepository) FindByUserID(ctx context.Context, userID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role`,
+		userID,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "find user roles", start)
+	}
+	defer rows.Close()
+
+	roles := make([]string, 0, 2)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, db.HandleQueryError(err, nil, "find user roles", start)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "find user roles", start)
+	}
+
+	db.MeasureQueryDuration("find user roles", start)
+	return roles, nil
+}
+
+func (r *PgRoleRepository) ReplaceRoles(ctx context.Context, userID string, roles []string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return db.HandleExecError(err, "replace user roles", start)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID); err != nil {
+		return db.HandleExecError(err, "replace user roles", start)
+	}
+
+	for _, role := range roles {
+		if _, err = tx.Exec(
+			ctx,
+			`INSERT INTO user_roles (user_id, role, created_at)
+			 VALUES ($1, $2, NOW())
+			 ON CONFLICT (user_id, role) DO NOTHING`,
+			userID,
+			role,
+		); err != nil {
+			return db.HandleExecError(err, "replace user roles", start)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.HandleExecError(err, "replace user roles", start)
+	}
+	db.MeasureQueryDuration("replace user roles", start)
+	return nil
+}
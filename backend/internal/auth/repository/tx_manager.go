@@ -7,6 +7,8 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
 )
 
 type RefreshTokenTxManagerInterface interface {
@@ -14,14 +16,24 @@ type RefreshTokenTxManagerInterface interface {
 }
 
 type RefreshTokenTxManager struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
 }
 
 func NewRefreshTokenTxManager(pool *pgxpool.Pool) *RefreshTokenTxManager {
-	return &RefreshTokenTxManager{pool: pool}
+	return &RefreshTokenTxManager{
+		pool:  pool,
+		retry: db.NewRetryPolicy("refresh_token_tx", db.IsRetryableTxError),
+	}
 }
 
 func (m *RefreshTokenTxManager) WithTx(ctx context.Context, fn func(context.Context, RefreshTokenTx) error) error {
+	return m.retry.Do(ctx, func(ctx context.Context) error {
+		return m.withTxOnce(ctx, fn)
+	})
+}
+
+func (m *RefreshTokenTxManager) withTxOnce(ctx context.Context, fn func(context.Context, RefreshTokenTx) error) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
 	defer cancel()
 
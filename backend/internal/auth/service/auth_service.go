@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	auditdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	auditservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/service"
 	authdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/domain"
 	authrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/repository"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
@@ -27,7 +29,13 @@ type Service interface {
 	RefreshAccessToken(ctx context.Context, refreshToken string, clientIP string) (AuthResult, error)
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
 	RevokeAccessToken(ctx context.Context, jti string, userID string) error
+	RevokeAccessTokenUntil(ctx context.Context, jti string, userID string, expiresAt time.Time) error
+	GetUserRoles(ctx context.Context, userID string) ([]string, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) ([]string, error)
+	IssueScopedToken(ctx context.Context, caller jwtverify.Claims, input ScopedTokenInput) (ScopedTokenResult, error)
 	ParseTokenForRevoke(ctx context.Context, tokenString string) (jwtverify.Claims, error)
+	Logout(ctx context.Context, input LogoutInput) error
+	ListAuditEvents(ctx context.Context, userID string, before time.Time, limit int) ([]auditdomain.Event, error)
 	CloseRefreshTokenCache()
 }
 
@@ -36,12 +44,17 @@ type AuthService struct {
 	identityService     identityservice.Service
 	refreshTokenRepo    authrepo.RefreshTokenRepository
 	revokedTokenRepo    authrepo.RevokedTokenRepository
+	roleRepo            authrepo.RoleRepository
+	audit               auditservice.Service
 	hasher              commoncrypto.PasswordHasher
 	idGenerator         commoncrypto.IDGenerator
 	clock               clock.Clock
 	log                 *logger.Logger
 	dbCircuitBreaker    resilience.CircuitBreakerInterface
+	dbWrites            resilience.CircuitBreakerInterface
 	accessTokenTTL      time.Duration
+	scopedTokenMaxTTL   time.Duration
+	botTokenMaxTTL      time.Duration
 	tokenIssuer         TokenIssuerInterface
 	refreshTokenRotator RefreshTokenRotatorInterface
 	credentialValidator CredentialValidator
@@ -53,9 +66,14 @@ type AuthServiceConfig struct {
 	AccessTokenTTL          time.Duration
 	RefreshTokenTTL         time.Duration
 	MaxRefreshTokens        int
+	ScopedTokenMaxTTL       time.Duration
+	BotTokenMaxTTL          time.Duration
 	CircuitBreakerThreshold int32
 	CircuitBreakerTimeout   time.Duration
 	CircuitBreakerReset     time.Duration
+	CircuitBreakerWindow    time.Duration
+	CircuitBreakerRate      float64
+	CircuitBreakerHalfOpen  int32
 }
 
 type AuthServiceDeps struct {
@@ -63,6 +81,8 @@ type AuthServiceDeps struct {
 	IdentityService  identityservice.Service
 	RefreshTokenRepo authrepo.RefreshTokenRepository
 	RevokedTokenRepo authrepo.RevokedTokenRepository
+	RoleRepo         authrepo.RoleRepository
+	Audit            auditservice.Service
 	Hasher           commoncrypto.PasswordHasher
 	IDGenerator      commoncrypto.IDGenerator
 	Clock            clock.Clock
@@ -70,20 +90,46 @@ type AuthServiceDeps struct {
 }
 
 func NewAuthService(deps AuthServiceDeps, config AuthServiceConfig) *AuthService {
-	databaseCircuitBreaker := resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{
-		Threshold:  config.CircuitBreakerThreshold,
-		Timeout:    config.CircuitBreakerTimeout,
-		ResetAfter: config.CircuitBreakerReset,
-		Name:       constants.CircuitBreakerDatabaseName,
-		Logger:     deps.Log,
-	})
 	timeClock := deps.Clock
 	if timeClock == nil {
 		timeClock = clock.NewRealClock()
 	}
+	databaseCircuitBreaker := resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{
+		Threshold:        config.CircuitBreakerThreshold,
+		Timeout:          config.CircuitBreakerTimeout,
+		ResetAfter:       config.CircuitBreakerReset,
+		Window:           config.CircuitBreakerWindow,
+		FailureRate:      config.CircuitBreakerRate,
+		HalfOpenMaxCalls: config.CircuitBreakerHalfOpen,
+		Clock:            timeClock,
+		Name:             constants.CircuitBreakerDatabaseName,
+		Logger:           deps.Log,
+	})
+	databaseWrites := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: constants.AuthWriteBulkheadConcurrency,
+		MaxLimit:      constants.AuthWriteBulkheadMaxLimit,
+		MaxQueue:      constants.AuthWriteBulkheadQueue,
+		Adaptive:      true,
+		TargetLatency: constants.AuthWriteBulkheadTargetLatency,
+		Clock:         timeClock,
+		Name:          constants.AuthWriteBulkheadName,
+		Logger:        deps.Log,
+	}).Around(databaseCircuitBreaker)
 	tokenIssuer := NewTokenIssuer(config.JWTSecret, deps.IDGenerator, config.AccessTokenTTL, timeClock)
-	refreshTokenRotator := NewRefreshTokenRotator(deps.RefreshTokenRepo, databaseCircuitBreaker, deps.IDGenerator, config.RefreshTokenTTL, config.MaxRefreshTokens, timeClock, deps.Log)
+	refreshTokenRotator := NewRefreshTokenRotator(deps.RefreshTokenRepo, databaseWrites, deps.IDGenerator, config.RefreshTokenTTL, config.MaxRefreshTokens, timeClock, deps.Log)
 	credentialValidator := NewCredentialValidator()
+	auditService := deps.Audit
+	if auditService == nil {
+		auditService = auditservice.NopService{}
+	}
+	scopedTokenMaxTTL := config.ScopedTokenMaxTTL
+	if scopedTokenMaxTTL <= 0 {
+		scopedTokenMaxTTL = constants.DefaultScopedTokenMaxTTL
+	}
+	botTokenMaxTTL := config.BotTokenMaxTTL
+	if botTokenMaxTTL <= 0 {
+		botTokenMaxTTL = constants.DefaultBotTokenMaxTTL
+	}
 
 	ctx := context.Background()
 	refreshTokenCache := NewRefreshTokenCache(ctx, timeClock, deps.Log)
@@ -93,12 +139,17 @@ func NewAuthService(deps AuthServiceDeps, config AuthServiceConfig) *AuthService
 		identityService:     deps.IdentityService,
 		refreshTokenRepo:    deps.RefreshTokenRepo,
 		revokedTokenRepo:    deps.RevokedTokenRepo,
+		roleRepo:            deps.RoleRepo,
+		audit:               auditService,
 		hasher:              deps.Hasher,
 		idGenerator:         deps.IDGenerator,
 		clock:               timeClock,
 		log:                 deps.Log,
 		dbCircuitBreaker:    databaseCircuitBreaker,
+		dbWrites:            databaseWrites,
 		accessTokenTTL:      config.AccessTokenTTL,
+		scopedTokenMaxTTL:   scopedTokenMaxTTL,
+		botTokenMaxTTL:      botTokenMaxTTL,
 		tokenIssuer:         tokenIssuer,
 		refreshTokenRotator: refreshTokenRotator,
 		credentialValidator: credentialValidator,
@@ -117,6 +168,11 @@ type LoginInput struct {
 	Password string
 }
 
+type LogoutInput struct {
+	Claims       jwtverify.Claims
+	RefreshToken string
+}
+
 type AuthResult struct {
 	AccessToken      string
 	RefreshToken     string
@@ -162,7 +218,7 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (AuthRe
 		CreatedAt:    s.clock.Now(),
 	}
 
-	err = s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+	err = s.dbWrites.Call(ctx, func(ctx context.Context) error {
 		return s.repo.Create(ctx, user)
 	})
 	if err != nil {
@@ -207,6 +263,9 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (AuthRe
 		"user_id":  string(user.ID),
 		"action":   "register_success",
 	}).Info("register success")
+	s.recordAudit(ctx, auditdomain.EventRegister, string(user.ID), map[string]string{
+		"username": user.Username,
+	})
 
 	return AuthResult{
 		AccessToken:      accessToken,
@@ -236,6 +295,12 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput) (AuthResult,
 		return fetchErr
 	})
 	if err != nil {
+		if errors.Is(err, userrepo.ErrUserNotFound) {
+			s.recordAudit(ctx, auditdomain.EventLoginFailed, "", map[string]string{
+				"username": input.Username,
+				"reason":   "user_not_found",
+			})
+		}
 		return s.handleDBError(ctx, err, input.Username, dbErrorConfig{
 			operation:             "login",
 			specificError:         userrepo.ErrUserNotFound,
@@ -249,6 +314,10 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput) (AuthResult,
 			"username": input.Username,
 			"action":   "login_invalid_password",
 		}).Warn("login failed: invalid password")
+		s.recordAudit(ctx, auditdomain.EventLoginFailed, string(user.ID), map[string]string{
+			"username": input.Username,
+			"reason":   "invalid_password",
+		})
 		return AuthResult{}, ErrInvalidCredentials
 	}
 
@@ -271,6 +340,9 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput) (AuthResult,
 		"user_id":  string(user.ID),
 		"action":   "login_success",
 	}).Info("login success")
+	s.recordAudit(ctx, auditdomain.EventLoginSuccess, string(user.ID), map[string]string{
+		"username": user.Username,
+	})
 
 	return AuthResult{
 		AccessToken:      accessToken,
@@ -306,7 +378,7 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 	txMgr := s.refreshTokenRepo.TxManager()
 	var err error
 
-	err = s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+	err = s.dbWrites.Call(ctx, func(ctx context.Context) error {
 		return txMgr.WithTx(ctx, func(txCtx context.Context, tx authrepo.RefreshTokenTx) error {
 			if !cacheHit {
 				fetchedToken, fetchedUser, fetchErr := tx.FindByTokenHashWithUserForUpdate(txCtx, hash)
@@ -370,6 +442,9 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 				fields["client_ip"] = clientIP
 			}
 			s.log.WithFields(ctx, fields).Warnf("refresh token failed: %v", err)
+			s.recordAudit(ctx, auditdomain.EventRefreshFailed, stored.UserID, map[string]string{
+				"reason": handledErr.Error(),
+			})
 			return AuthResult{}, handledErr
 		}
 		if errors.Is(err, commonerrors.ErrUserNotFound) {
@@ -405,6 +480,7 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 		"user_id": stored.UserID,
 		"action":  "refresh_token_success",
 	}).Info("refresh token success")
+	s.recordAudit(ctx, auditdomain.EventTokenRefreshed, stored.UserID, nil)
 
 	return AuthResult{
 		AccessToken:      accessToken,
@@ -443,7 +519,7 @@ func (s *AuthService) RevokeRefreshToken(ctx context.Context, refreshToken strin
 		)
 	}
 
-	err = s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+	err = s.dbWrites.Call(ctx, func(ctx context.Context) error {
 		err := s.refreshTokenRepo.DeleteByTokenHash(ctx, hash)
 		if err == nil {
 			s.refreshTokenCache.Invalidate(hash)
@@ -472,6 +548,7 @@ func (s *AuthService) RevokeRefreshToken(ctx context.Context, refreshToken strin
 		"user_id": stored.UserID,
 		"action":  "refresh_token_revoked",
 	}).Info("refresh token revoked")
+	s.recordAudit(ctx, auditdomain.EventRefreshTokenRevoked, stored.UserID, nil)
 
 	metrics.RefreshTokensRevoked.Inc()
 
@@ -479,12 +556,18 @@ func (s *AuthService) RevokeRefreshToken(ctx context.Context, refreshToken strin
 }
 
 func (s *AuthService) RevokeAccessToken(ctx context.Context, jti string, userID string) error {
+	return s.RevokeAccessTokenUntil(ctx, jti, userID, s.clock.Now().Add(s.accessTokenTTL))
+}
+
+func (s *AuthService) RevokeAccessTokenUntil(ctx context.Context, jti string, userID string, expiresAt time.Time) error {
 	if jti == "" {
 		return nil
 	}
 
-	expiresAt := s.clock.Now().Add(s.accessTokenTTL)
-	err := s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+	if expiresAt.IsZero() {
+		expiresAt = s.clock.Now().Add(s.accessTokenTTL)
+	}
+	err := s.dbWrites.Call(ctx, func(ctx context.Context) error {
 		return s.revokedTokenRepo.Revoke(ctx, jti, userID, expiresAt)
 	})
 	if err != nil {
@@ -509,9 +592,45 @@ func (s *AuthService) RevokeAccessToken(ctx context.Context, jti string, userID
 		"user_id": userID,
 		"action":  "access_token_revoked",
 	}).Info("access token revoked")
+	s.recordAudit(ctx, auditdomain.EventAccessTokenRevoked, userID, map[string]string{
+		"jti": jti,
+	})
 	return nil
 }
 
+func (s *AuthService) Logout(ctx context.Context, input LogoutInput) error {
+	var firstErr error
+	if input.Claims.JTI != "" {
+		if err := s.RevokeAccessTokenUntil(ctx, input.Claims.JTI, input.Claims.UserID, input.Claims.ExpiresAt); err != nil {
+			firstErr = err
+		}
+	}
+
+	if input.RefreshToken != "" {
+		if err := s.RevokeRefreshToken(ctx, input.RefreshToken); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if input.Claims.UserID != "" {
+		s.recordAudit(ctx, auditdomain.EventLogout, input.Claims.UserID, nil)
+	}
+
+	return firstErr
+}
+
+func (s *AuthService) ListAuditEvents(ctx context.Context, userID string, before time.Time, limit int) ([]auditdomain.Event, error) {
+	return s.audit.ListForUser(ctx, userID, before, limit)
+}
+
+func (s *AuthService) recordAudit(ctx context.Context, eventType auditdomain.EventType, userID string, metadata map[string]string) {
+	s.audit.Record(ctx, auditdomain.Event{
+		UserID:   userID,
+		Type:     eventType,
+		Metadata: metadata,
+	})
+}
+
 func (s *AuthService) ParseTokenForRevoke(ctx context.Context, tokenString string) (jwtverify.Claims, error) {
 	return s.tokenIssuer.ParseToken(tokenString)
 }
@@ -561,7 +680,15 @@ func getSpecificErrorAction(err error) string {
 }
 
 func (s *AuthService) issueTokens(ctx context.Context, user userdomain.User) (string, authdomain.RefreshToken, error) {
-	accessToken, _, err := s.tokenIssuer.IssueAccessToken(user)
+	roles, err := s.loadRoles(ctx, string(user.ID))
+	if err != nil {
+		return "", authdomain.RefreshToken{}, err
+	}
+
+	accessToken, _, err := s.tokenIssuer.IssueAccessTokenWithGrant(user, AccessGrant{
+		Roles:  roles,
+		Scopes: authdomain.ScopesForRoles(roles),
+	})
 	if err != nil {
 		return "", authdomain.RefreshToken{}, err
 	}
@@ -574,6 +701,11 @@ func (s *AuthService) issueTokens(ctx context.Context, user userdomain.User) (st
 	return accessToken, refresh, nil
 }
 
+func (s *AuthService) DatabaseCircuitOpen() bool {
+	breaker, ok := s.dbCircuitBreaker.(interface{ IsOpen() bool })
+	return ok && breaker.IsOpen()
+}
+
 func (s *AuthService) CloseRefreshTokenCache() {
 	if s.refreshTokenCache != nil {
 		s.refreshTokenCache.Close()
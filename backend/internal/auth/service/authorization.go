@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	auditdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	authdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/domain"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
+)
+
+type ScopedTokenInput struct {
+	Scopes []string
+	TTL    time.Duration
+}
+
+type ScopedTokenResult struct {
+	Token     string
+	JTI       string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+func (s *AuthService) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.loadRoles(ctx, userID)
+}
+
+func (s *AuthService) SetUserRoles(ctx context.Context, userID string, roles []string) ([]string, error) {
+	normalized, err := normalizeRoles(roles)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "set_user_roles_validation_failed",
+		}).Warnf("set user roles validation failed: %v", err)
+		return nil, err
+	}
+
+	if s.roleRepo == nil {
+		return nil, newInternalError(
+			"ROLE_STORE_UNAVAILABLE",
+			"role store is not configured",
+			nil,
+		)
+	}
+
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	err = s.dbWrites.Call(ctx, func(ctx context.Context) error {
+		return s.roleRepo.ReplaceRoles(ctx, userID, normalized)
+	})
+	if err != nil {
+		if handledErr := handleCircuitBreakerError(err); handledErr != err {
+			return nil, handledErr
+		}
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "set_user_roles_failed",
+		}).Errorf("failed to set user roles: %v", err)
+		return nil, newInternalError(
+			"SET_USER_ROLES_FAILED",
+			"failed to set user roles",
+			err,
+		)
+	}
+
+	s.log.WithFields(ctx, logger.Fields{
+		"user_id": userID,
+		"roles":   normalized,
+		"action":  "user_roles_updated",
+	}).Info("user roles updated")
+	s.recordAudit(ctx, auditdomain.EventRolesChanged, userID, map[string]string{
+		"roles": strings.Join(normalized, ","),
+	})
+
+	return normalized, nil
+}
+
+func (s *AuthService) IssueScopedToken(ctx context.Context, caller jwtverify.Claims, input ScopedTokenInput) (ScopedTokenResult, error) {
+	if len(input.Scopes) == 0 {
+		return ScopedTokenResult{}, ErrEmptyScopes
+	}
+	if caller.Derived || !caller.HasScope(jwtverify.ScopeTokensIssue) {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": caller.UserID,
+			"action":  "scoped_token_caller_not_allowed",
+		}).Warn("scoped token rejected: caller cannot issue tokens")
+		return ScopedTokenResult{}, ErrScopeNotGranted
+	}
+
+	scopes := make([]string, 0, len(input.Scopes))
+	seen := make(map[string]struct{}, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !jwtverify.IsKnownScope(scope) {
+			return ScopedTokenResult{}, ErrUnknownScope
+		}
+		if scope == jwtverify.ScopeTokensIssue {
+			return ScopedTokenResult{}, ErrScopeNotDelegable
+		}
+		if !caller.HasScope(scope) {
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id": caller.UserID,
+				"scope":   scope,
+				"action":  "scoped_token_scope_not_granted",
+			}).Warn("scoped token rejected: scope not granted to caller")
+			return ScopedTokenResult{}, ErrScopeNotGranted
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		scopes = append(scopes, scope)
+	}
+
+	ttl := input.TTL
+	if ttl <= 0 {
+		ttl = s.accessTokenTTL
+	}
+	botSession := caller.HasRole(string(authdomain.RoleBot)) && !caller.Derived
+	maxTTL := s.scopedTokenMaxTTL
+	if botSession {
+		maxTTL = s.botTokenMaxTTL
+	}
+	if ttl > maxTTL {
+		return ScopedTokenResult{}, ErrScopedTokenTTLTooLong
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(ttl)
+	if !botSession {
+		if !caller.ExpiresAt.After(now) {
+			return ScopedTokenResult{}, commonerrors.ErrInvalidToken
+		}
+		if expiresAt.After(caller.ExpiresAt) {
+			expiresAt = caller.ExpiresAt
+			ttl = expiresAt.Sub(now)
+		}
+	}
+
+	user := userdomain.User{
+		ID:       userdomain.ID(caller.UserID),
+		Username: caller.Username,
+	}
+	token, jti, err := s.tokenIssuer.IssueAccessTokenWithGrant(user, AccessGrant{
+		Roles:   caller.Roles,
+		Scopes:  scopes,
+		TTL:     ttl,
+		Derived: true,
+	})
+	if err != nil {
+		return ScopedTokenResult{}, newInternalError(
+			"TOKEN_ISSUE_FAILED",
+			"failed to issue tokens",
+			err,
+		)
+	}
+
+	s.log.WithFields(ctx, logger.Fields{
+		"user_id": caller.UserID,
+		"jti":     jti,
+		"scopes":  scopes,
+		"action":  "scoped_token_issued",
+	}).Info("scoped token issued")
+	s.recordAudit(ctx, auditdomain.EventScopedTokenIssued, caller.UserID, map[string]string{
+		"jti":        jti,
+		"scopes":     jwtverify.JoinScopes(scopes),
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	})
+
+	return ScopedTokenResult{
+		Token:     token,
+		JTI:       jti,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *AuthService) loadRoles(ctx context.Context, userID string) ([]string, error) {
+	if s.roleRepo == nil {
+		return authdomain.DefaultRoles, nil
+	}
+
+	var roles []string
+	err := s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+		var fetchErr error
+		roles, fetchErr = s.roleRepo.FindByUserID(ctx, userID)
+		return fetchErr
+	})
+	if err != nil {
+		if handledErr := handleCircuitBreakerError(err); handledErr != err {
+			return nil, handledErr
+		}
+		return nil, newInternalError(
+			"GET_USER_ROLES_FAILED",
+			"failed to get user roles",
+			err,
+		)
+	}
+
+	if len(roles) == 0 {
+		return authdomain.DefaultRoles, nil
+	}
+	return roles, nil
+}
+
+func (s *AuthService) ensureUserExists(ctx context.Context, userID string) error {
+	err := s.dbCircuitBreaker.Call(ctx, func(ctx context.Context) error {
+		_, fetchErr := s.repo.FindByID(ctx, userdomain.ID(userID))
+		return fetchErr
+	})
+	if err == nil {
+		return nil
+	}
+	if handledErr := handleCircuitBreakerError(err); handledErr != err {
+		return handledErr
+	}
+	if errors.Is(err, userrepo.ErrUserNotFound) {
+		return commonerrors.ErrUserNotFound
+	}
+	return newInternalError(
+		"USER_LOOKUP_FAILED",
+		"failed to get user",
+		err,
+	)
+}
+
+func normalizeRoles(roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return nil, ErrEmptyRoles
+	}
+
+	result := make([]string, 0, len(roles))
+	seen := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		if !authdomain.IsKnownRole(role) {
+			return nil, ErrUnknownRole
+		}
+		if _, ok := seen[role]; ok {
+			continue
+		}
+		seen[role] = struct{}{}
+		result = append(result, role)
+	}
+	return result, nil
+}
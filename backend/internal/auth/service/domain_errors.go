@@ -74,4 +74,53 @@ var (
 		503,
 		"service temporarily unavailable",
 	)
+
+	ErrUnknownRole = commonerrors.NewDomainError(
+		"UNKNOWN_ROLE",
+		commonerrors.CategoryValidation,
+		400,
+		"unknown role",
+	)
+
+	ErrEmptyRoles = commonerrors.NewDomainError(
+		"EMPTY_ROLES",
+		commonerrors.CategoryValidation,
+		400,
+		"at least one role is required",
+	)
+
+	ErrUnknownScope = commonerrors.NewDomainError(
+		"UNKNOWN_SCOPE",
+		commonerrors.CategoryValidation,
+		400,
+		"unknown scope",
+	)
+
+	ErrEmptyScopes = commonerrors.NewDomainError(
+		"EMPTY_SCOPES",
+		commonerrors.CategoryValidation,
+		400,
+		"at least one scope is required",
+	)
+
+	ErrScopeNotGranted = commonerrors.NewDomainError(
+		"SCOPE_NOT_GRANTED",
+		commonerrors.CategoryForbidden,
+		403,
+		"requested scope exceeds caller permissions",
+	)
+
+	ErrScopeNotDelegable = commonerrors.NewDomainError(
+		"SCOPE_NOT_DELEGABLE",
+		commonerrors.CategoryValidation,
+		400,
+		"scope cannot be delegated to a scoped token",
+	)
+
+	ErrScopedTokenTTLTooLong = commonerrors.NewDomainError(
+		"SCOPED_TOKEN_TTL_TOO_LONG",
+		commonerrors.CategoryValidation,
+		400,
+		"requested token lifetime exceeds maximum",
+	)
 )
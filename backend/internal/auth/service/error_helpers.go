@@ -9,7 +9,7 @@ import (
 )
 
 func handleCircuitBreakerError(err error) error {
-	if errors.Is(err, commonerrors.ErrCircuitOpen) {
+	if errors.Is(err, commonerrors.ErrCircuitOpen) || errors.Is(err, commonerrors.ErrBulkheadFull) {
 		return ErrServiceUnavailable.WithCause(err)
 	}
 	return err
@@ -5,6 +5,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 
+	authdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/domain"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
 	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
@@ -14,9 +15,17 @@ import (
 
 type TokenIssuerInterface interface {
 	IssueAccessToken(user userdomain.User) (string, string, error)
+	IssueAccessTokenWithGrant(user userdomain.User, grant AccessGrant) (string, string, error)
 	ParseToken(tokenString string) (jwtverify.Claims, error)
 }
 
+type AccessGrant struct {
+	Roles   []string
+	Scopes  []string
+	TTL     time.Duration
+	Derived bool
+}
+
 type TokenIssuer struct {
 	jwtSecret      []byte
 	idGenerator    commoncrypto.IDGenerator
@@ -39,19 +48,36 @@ func NewTokenIssuer(
 }
 
 func (ti *TokenIssuer) IssueAccessToken(user userdomain.User) (string, string, error) {
+	return ti.IssueAccessTokenWithGrant(user, AccessGrant{
+		Roles:  authdomain.DefaultRoles,
+		Scopes: jwtverify.DefaultScopes,
+	})
+}
+
+func (ti *TokenIssuer) IssueAccessTokenWithGrant(user userdomain.User, grant AccessGrant) (string, string, error) {
 	jti, err := ti.idGenerator.NewID()
 	if err != nil {
 		return "", "", err
 	}
 
+	ttl := grant.TTL
+	if ttl <= 0 {
+		ttl = ti.accessTokenTTL
+	}
+
 	now := ti.clock.Now()
-	expiresAt := now.Add(ti.accessTokenTTL)
+	expiresAt := now.Add(ttl)
 	claims := jwt.MapClaims{
-		"sub": string(user.ID),
-		"usr": user.Username,
-		"jti": jti,
-		"exp": expiresAt.Unix(),
-		"iat": now.Unix(),
+		"sub":   string(user.ID),
+		"usr":   user.Username,
+		"jti":   jti,
+		"roles": grant.Roles,
+		"scope": jwtverify.JoinScopes(grant.Scopes),
+		"exp":   expiresAt.Unix(),
+		"iat":   now.Unix(),
+	}
+	if grant.Derived {
+		claims["drv"] = true
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
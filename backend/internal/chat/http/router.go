@@ -2,9 +2,12 @@ package http
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	gorillaWS "github.com/gorilla/websocket"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -19,6 +22,11 @@ import (
 	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	conversationdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+	observabilitymetrics "github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	privacydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+	pushdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	webhookdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
 )
 
 type Handler struct {
@@ -27,8 +35,10 @@ type Handler struct {
 	jwtSecret string
 	upgrader  gorillaWS.Upgrader
 	log       *logger.Logger
+	cfgMu     sync.RWMutex
 	cfg       config.ChatConfig
 	pool      *pgxpool.Pool
+	mux       *http.ServeMux
 }
 
 type userResponse struct {
@@ -36,7 +46,98 @@ type userResponse struct {
 	Username string `json:"username"`
 }
 
-func NewHandler(chat service.Service, hub websocket.HubInterface, cfg config.ChatConfig, log *logger.Logger, pool *pgxpool.Pool) http.Handler {
+type receiptResponse struct {
+	MessageID   string    `json:"message_id"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id"`
+	Status      string    `json:"status"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type reactionResponse struct {
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+	Count     int    `json:"count"`
+	Reacted   bool   `json:"reacted"`
+}
+
+type privacySettingsRequest struct {
+	ReadReceipts       *bool   `json:"read_receipts"`
+	PresenceVisibility *string `json:"presence_visibility"`
+}
+
+type privacySettingsResponse struct {
+	ReadReceipts       bool   `json:"read_receipts"`
+	PresenceVisibility string `json:"presence_visibility"`
+}
+
+type conversationResponse struct {
+	PeerID         string    `json:"peer_id"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	UnreadCount    int       `json:"unread_count"`
+	Muted          bool      `json:"muted"`
+	Archived       bool      `json:"archived"`
+	Pinned         bool      `json:"pinned"`
+}
+
+type conversationUpdateRequest struct {
+	Muted    *bool `json:"muted"`
+	Archived *bool `json:"archived"`
+	Pinned   *bool `json:"pinned"`
+	Read     bool  `json:"read"`
+}
+
+type pushDeviceRequest struct {
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+}
+
+type pushDeviceResponse struct {
+	Provider  string    `json:"provider"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type pushPublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+type botMessageRequest struct {
+	To         string `json:"to"`
+	MessageID  string `json:"message_id"`
+	Ciphertext string `json:"ciphertext"`
+	Nonce      string `json:"nonce"`
+	ReplyToID  string `json:"reply_to_message_id"`
+}
+
+type botMessageResponse struct {
+	Delivered bool `json:"delivered"`
+}
+
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type webhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type deadLetterResponse struct {
+	ID        string          `json:"id"`
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewHandler(chat service.Service, hub websocket.HubInterface, cfg config.ChatConfig, log *logger.Logger, pool *pgxpool.Pool) *Handler {
 	h := &Handler{
 		chat:      chat.(*service.ChatService),
 		hub:       hub,
@@ -47,6 +148,7 @@ func NewHandler(chat service.Service, hub websocket.HubInterface, cfg config.Cha
 			ReadBufferSize:    constants.WebSocketReadBufferSize,
 			WriteBufferSize:   constants.WebSocketWriteBufferSize,
 			EnableCompression: true,
+			Subprotocols:      websocket.Subprotocols(),
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
 				if origin == "" {
@@ -66,9 +168,34 @@ func NewHandler(chat service.Service, hub websocket.HubInterface, cfg config.Cha
 	mux.HandleFunc("/api/chat/me", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.RequestTimeout)(h.me)))
 	mux.HandleFunc("/api/chat/users", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.SearchTimeout)(h.searchUsers)))
 	mux.HandleFunc("/api/chat/users/", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.RequestTimeout)(h.getIdentityKey)))
+	mux.HandleFunc("/api/chat/me/privacy", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.privacySettings)))
+	mux.HandleFunc("/api/chat/conversations", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.listConversations))))
+	mux.HandleFunc("/api/chat/conversations/", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.conversation)))
+	mux.HandleFunc("/api/chat/push/vapid-key", commonhttp.RequireMethod(http.MethodGet)(commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.pushPublicKey))))
+	mux.HandleFunc("/api/chat/push/devices", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.pushDevices)))
+	mux.HandleFunc("/api/chat/bot/messages", commonhttp.RequireMethod(http.MethodPost)(commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.sendBotMessage))))
+	mux.HandleFunc("/api/chat/bot/webhooks", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.webhooks)))
+	mux.HandleFunc("/api/chat/bot/webhooks/", commonhttp.WithTimeout(cfg.RequestTimeout)(jwtverify.RequireAuth(h.webhook)))
 	mux.HandleFunc("/ws/", h.handleWebSocket)
+	h.mux = mux
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) ApplyConfig(cfg config.ChatConfig) {
+	h.cfgMu.Lock()
+	defer h.cfgMu.Unlock()
+	h.cfg = cfg
+}
 
-	return mux
+func (h *Handler) config() config.ChatConfig {
+	h.cfgMu.RLock()
+	defer h.cfgMu.RUnlock()
+	return h.cfg
 }
 
 func (h *Handler) me(w http.ResponseWriter, r *http.Request) {
@@ -166,16 +293,475 @@ func (h *Handler) getIdentityKey(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) privacySettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	var settings privacydomain.Settings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = h.chat.GetPrivacySettings(ctx, claims.UserID)
+	case http.MethodPut:
+		var req privacySettingsRequest
+		if decodeErr := commonhttp.DecodeJSON(r, &req); decodeErr != nil {
+			h.log.WithFields(ctx, logger.Fields{
+				"user_id": claims.UserID,
+				"action":  "chat_privacy_invalid_json",
+			}).Warnf("chat/me/privacy failed: invalid json: %v", decodeErr)
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+			return
+		}
+		if req.ReadReceipts == nil && req.PresenceVisibility == nil {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "read_receipts or presence_visibility is required", nil, "")
+			return
+		}
+		update := privacydomain.SettingsUpdate{ReadReceipts: req.ReadReceipts}
+		if req.PresenceVisibility != nil {
+			visibility := privacydomain.Visibility(*req.PresenceVisibility)
+			update.PresenceVisibility = &visibility
+		}
+		settings, err = h.chat.UpdatePrivacySettings(ctx, claims.UserID, update)
+		if err == nil {
+			h.log.WithFields(ctx, logger.Fields{
+				"user_id":             claims.UserID,
+				"read_receipts":       settings.ReadReceipts,
+				"presence_visibility": string(settings.PresenceVisibility),
+				"action":              "chat_privacy_updated",
+			}).Info("chat/me/privacy updated")
+		}
+	default:
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+		return
+	}
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	commonhttp.WriteJSON(w, http.StatusOK, privacySettingsResponse{
+		ReadReceipts:       settings.ReadReceipts,
+		PresenceVisibility: string(settings.PresenceVisibility),
+	})
+}
+
+func (h *Handler) listConversations(w http.ResponseWriter, r *http.Request) {
+	limit := constants.DefaultConversationListLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= constants.MaxConversationListLimit {
+			limit = v
+		}
+	}
+
+	var archived bool
+	if archivedStr := r.URL.Query().Get("archived"); archivedStr != "" {
+		parsed, err := strconv.ParseBool(archivedStr)
+		if err != nil {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "archived must be a boolean", nil, "")
+			return
+		}
+		archived = parsed
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	conversations, err := h.chat.ListConversations(ctx, claims.UserID, archived, limit)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	resp := make([]conversationResponse, 0, len(conversations))
+	for _, conversation := range conversations {
+		resp = append(resp, toConversationResponse(conversation))
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) conversation(w http.ResponseWriter, r *http.Request) {
+	receipts := strings.HasSuffix(r.URL.Path, "/receipts")
+	reactions := strings.HasSuffix(r.URL.Path, "/reactions")
+	switch {
+	case receipts && r.Method == http.MethodGet:
+		h.conversationReceipts(w, r)
+	case reactions && r.Method == http.MethodGet:
+		h.conversationReactions(w, r)
+	case !receipts && !reactions && r.Method == http.MethodPatch:
+		h.updateConversation(w, r)
+	default:
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+	}
+}
+
+func (h *Handler) updateConversation(w http.ResponseWriter, r *http.Request) {
+	peerID, err := commonhttp.ExtractAndValidateUserID(r.URL.Path, "")
+	if err != nil {
+		if err == commonerrors.ErrEmptyUUID {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeUserIDRequired, "user_id is required", nil, "")
+			return
+		}
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidUserIDFormat, "invalid user_id format (must be UUID)", nil, "")
+		return
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	var req conversationUpdateRequest
+	if err := commonhttp.DecodeJSON(r, &req); err != nil {
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id": claims.UserID,
+			"action":  "chat_conversation_invalid_json",
+		}).Warnf("chat/conversations update failed: invalid json: %v", err)
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+		return
+	}
+	update := conversationdomain.Update{
+		Muted:    req.Muted,
+		Archived: req.Archived,
+		Pinned:   req.Pinned,
+		MarkRead: req.Read,
+	}
+	if update.Empty() {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "muted, archived, pinned or read is required", nil, "")
+		return
+	}
+
+	conversation, err := h.chat.UpdateConversation(ctx, claims.UserID, peerID, update)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	h.log.WithFields(ctx, logger.Fields{
+		"user_id": claims.UserID,
+		"peer_id": peerID,
+		"action":  "chat_conversation_updated",
+	}).Info("chat/conversations updated")
+	commonhttp.WriteJSON(w, http.StatusOK, toConversationResponse(conversation))
+}
+
+func (h *Handler) conversationReceipts(w http.ResponseWriter, r *http.Request) {
+	urlPath := r.URL.Path
+	if !strings.HasSuffix(urlPath, "/receipts") {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidPath, "invalid path", nil, "")
+		return
+	}
+
+	peerID, err := commonhttp.ExtractAndValidateUserID(urlPath, "/receipts")
+	if err != nil {
+		if err == commonerrors.ErrEmptyUUID {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeUserIDRequired, "user_id is required", nil, "")
+			return
+		}
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidUserIDFormat, "invalid user_id format (must be UUID)", nil, "")
+		return
+	}
+
+	limit := constants.DefaultReceiptListLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= constants.MaxReceiptListLimit {
+			limit = v
+		}
+	}
+
+	var since time.Time
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, sinceStr)
+		if err != nil {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "since must be RFC3339 timestamp", nil, "")
+			return
+		}
+		since = parsed
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	receipts, err := h.chat.ListReceipts(ctx, claims.UserID, peerID, since, limit)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	resp := make([]receiptResponse, 0, len(receipts))
+	for _, receipt := range receipts {
+		resp = append(resp, receiptResponse{
+			MessageID:   receipt.MessageID,
+			SenderID:    receipt.SenderID,
+			RecipientID: receipt.RecipientID,
+			Status:      string(receipt.Status),
+			UpdatedAt:   receipt.UpdatedAt,
+		})
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) conversationReactions(w http.ResponseWriter, r *http.Request) {
+	peerID, err := commonhttp.ExtractAndValidateUserID(r.URL.Path, "/reactions")
+	if err != nil {
+		if err == commonerrors.ErrEmptyUUID {
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeUserIDRequired, "user_id is required", nil, "")
+			return
+		}
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidUserIDFormat, "invalid user_id format (must be UUID)", nil, "")
+		return
+	}
+
+	messageIDs := r.URL.Query()["message_id"]
+	if len(messageIDs) == 0 {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "message_id is required", nil, "")
+		return
+	}
+	if len(messageIDs) > constants.MaxReactionMessageIDs {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "too many message_id values", nil, "")
+		return
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	summaries, err := h.chat.ListReactions(ctx, claims.UserID, peerID, messageIDs)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	resp := make([]reactionResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		resp = append(resp, reactionResponse{
+			MessageID: summary.MessageID,
+			Emoji:     summary.Emoji,
+			Count:     summary.Count,
+			Reacted:   summary.Reacted,
+		})
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) pushPublicKey(w http.ResponseWriter, r *http.Request) {
+	publicKey := h.chat.PushPublicKey()
+	if publicKey == "" {
+		commonhttp.HandleError(w, r, commonerrors.ErrPushProviderUnavailable, h.log)
+		return
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, pushPublicKeyResponse{PublicKey: publicKey})
+}
+
+func (h *Handler) pushDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+		return
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	var req pushDeviceRequest
+	if err := commonhttp.DecodeJSON(r, &req); err != nil {
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id": claims.UserID,
+			"action":  "chat_push_invalid_json",
+		}).Warnf("chat/push/devices failed: invalid json: %v", err)
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+		return
+	}
+	if req.Token == "" {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "token is required", nil, "")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.chat.UnregisterPushDevice(ctx, claims.UserID, req.Token); err != nil {
+			commonhttp.HandleError(w, r, err, h.log)
+			return
+		}
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id": claims.UserID,
+			"action":  "chat_push_device_unregistered",
+		}).Info("chat/push/devices unregistered")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	device, err := h.chat.RegisterPushDevice(ctx, claims.UserID, pushdomain.Provider(req.Provider), req.Token)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+	h.log.WithFields(ctx, logger.Fields{
+		"user_id":  claims.UserID,
+		"provider": string(device.Provider),
+		"action":   "chat_push_device_registered",
+	}).Info("chat/push/devices registered")
+	commonhttp.WriteJSON(w, http.StatusCreated, pushDeviceResponse{
+		Provider:  string(device.Provider),
+		CreatedAt: device.CreatedAt,
+	})
+}
+
+func (h *Handler) sendBotMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	var req botMessageRequest
+	if err := commonhttp.DecodeJSON(r, &req); err != nil {
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id": claims.UserID,
+			"action":  "chat_bot_message_invalid_json",
+		}).Warnf("chat/bot/messages failed: invalid json: %v", err)
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+		return
+	}
+	if req.To == "" || req.MessageID == "" || req.Ciphertext == "" || req.Nonce == "" {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeBadRequest, "to, message_id, ciphertext and nonce are required", nil, "")
+		return
+	}
+
+	delivered, err := h.chat.SendBotMessage(ctx, claims.UserID, websocket.MessagePayload{
+		To:         req.To,
+		MessageID:  req.MessageID,
+		Ciphertext: req.Ciphertext,
+		Nonce:      req.Nonce,
+		ReplyToID:  req.ReplyToID,
+	})
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+	commonhttp.WriteJSON(w, http.StatusAccepted, botMessageResponse{Delivered: delivered})
+}
+
+func (h *Handler) webhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	switch r.Method {
+	case http.MethodGet:
+		webhooks, err := h.chat.ListWebhooks(ctx, claims.UserID)
+		if err != nil {
+			commonhttp.HandleError(w, r, err, h.log)
+			return
+		}
+		resp := make([]webhookResponse, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			webhook.Secret = ""
+			resp = append(resp, toWebhookResponse(webhook))
+		}
+		commonhttp.WriteJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req webhookRequest
+		if err := commonhttp.DecodeJSON(r, &req); err != nil {
+			h.log.WithFields(ctx, logger.Fields{
+				"user_id": claims.UserID,
+				"action":  "chat_webhook_invalid_json",
+			}).Warnf("chat/bot/webhooks failed: invalid json: %v", err)
+			commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidJSON, "invalid json", nil, "")
+			return
+		}
+		events := make([]webhookdomain.EventType, 0, len(req.Events))
+		for _, event := range req.Events {
+			events = append(events, webhookdomain.EventType(event))
+		}
+
+		webhook, err := h.chat.CreateWebhook(ctx, claims.UserID, req.URL, events)
+		if err != nil {
+			commonhttp.HandleError(w, r, err, h.log)
+			return
+		}
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id":    claims.UserID,
+			"webhook_id": webhook.ID,
+			"action":     "chat_webhook_created",
+		}).Info("chat/bot/webhooks created")
+		commonhttp.WriteJSON(w, http.StatusCreated, toWebhookResponse(webhook))
+
+	default:
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+	}
+}
+
+func (h *Handler) webhook(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/chat/bot/webhooks/")
+	webhookID, deadLetters := strings.CutSuffix(path, "/dead-letters")
+	if err := commonhttp.ValidateUUID(webhookID); err != nil {
+		commonhttp.WriteErrorEnvelope(w, http.StatusBadRequest, commonhttp.CodeInvalidPath, "invalid webhook id", nil, "")
+		return
+	}
+
+	switch {
+	case deadLetters && r.Method == http.MethodGet:
+		h.webhookDeadLetters(w, r, webhookID)
+	case !deadLetters && r.Method == http.MethodDelete:
+		ctx := r.Context()
+		claims, _ := jwtverify.FromContext(ctx)
+		if err := h.chat.DeleteWebhook(ctx, claims.UserID, webhookID); err != nil {
+			commonhttp.HandleError(w, r, err, h.log)
+			return
+		}
+		h.log.WithFields(ctx, logger.Fields{
+			"user_id":    claims.UserID,
+			"webhook_id": webhookID,
+			"action":     "chat_webhook_deleted",
+		}).Info("chat/bot/webhooks deleted")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		commonhttp.WriteErrorEnvelope(w, http.StatusMethodNotAllowed, commonhttp.CodeMethodNotAllowed, "method not allowed", nil, "")
+	}
+}
+
+func (h *Handler) webhookDeadLetters(w http.ResponseWriter, r *http.Request, webhookID string) {
+	limit := constants.DefaultWebhookDeadLetterLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= constants.MaxWebhookDeadLetterLimit {
+			limit = v
+		}
+	}
+
+	ctx := r.Context()
+	claims, _ := jwtverify.FromContext(ctx)
+
+	letters, err := h.chat.ListWebhookDeadLetters(ctx, claims.UserID, webhookID, limit)
+	if err != nil {
+		commonhttp.HandleError(w, r, err, h.log)
+		return
+	}
+
+	resp := make([]deadLetterResponse, 0, len(letters))
+	for _, letter := range letters {
+		resp = append(resp, deadLetterResponse{
+			ID:        letter.ID,
+			EventID:   letter.EventID,
+			EventType: string(letter.EventType),
+			Payload:   letter.Payload,
+			Attempts:  letter.Attempts,
+			LastError: letter.LastError,
+			ExpiresAt: letter.ExpiresAt,
+			CreatedAt: letter.CreatedAt,
+		})
+	}
+	commonhttp.WriteJSON(w, http.StatusOK, resp)
+}
+
 func (h *Handler) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if h.hub.IsDraining() {
+		observabilitymetrics.ChatWebSocketDrainDropped.WithLabelValues("rejected_upgrade").Inc()
+		w.Header().Set("Retry-After", strconv.Itoa(int(constants.WebSocketReconnectHintMin.Seconds())))
+		commonhttp.HandleError(w, r, commonerrors.ErrServerRestarting, h.log)
+		return
+	}
+
 	revokedTokenRepo := authrepo.NewPgRevokedTokenRepository(h.pool)
 	var claims jwtverify.Claims
 	var authenticated bool
 
 	if tokenString, ok := jwtverify.ExtractTokenFromHeader(r); ok {
 		parsedClaims, err := jwtverify.ParseToken(tokenString, []byte(h.jwtSecret))
-		if err == nil {
+		if err == nil && parsedClaims.HasScope(jwtverify.ScopeChatConnect) {
 			if parsedClaims.JTI != "" {
 				revoked, err := revokedTokenRepo.IsRevoked(ctx, parsedClaims.JTI)
 				if err == nil && !revoked {
@@ -197,18 +783,21 @@ func (h *Handler) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	cfg := h.config()
 	var client *websocket.Client
 	if authenticated {
 		client = websocket.NewAuthenticatedClient(
 			h.hub,
 			conn,
 			claims,
+			h.jwtSecret,
 			h.log,
-			h.cfg.WebSocketWriteWait,
-			h.cfg.WebSocketPongWait,
-			h.cfg.WebSocketPingPeriod,
-			h.cfg.WebSocketMaxMsgSize,
-			h.cfg.WebSocketSendBufSize,
+			revokedTokenRepo,
+			cfg.WebSocketWriteWait,
+			cfg.WebSocketPongWait,
+			cfg.WebSocketPingPeriod,
+			cfg.WebSocketMaxMsgSize,
+			cfg.WebSocketSendBufSize,
 		)
 		h.hub.Register(client)
 		h.log.WithFields(ctx, logger.Fields{
@@ -223,12 +812,12 @@ func (h *Handler) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			h.jwtSecret,
 			h.log,
 			revokedTokenRepo,
-			h.cfg.WebSocketWriteWait,
-			h.cfg.WebSocketPongWait,
-			h.cfg.WebSocketPingPeriod,
-			h.cfg.WebSocketMaxMsgSize,
-			h.cfg.WebSocketAuthTimeout,
-			h.cfg.WebSocketSendBufSize,
+			cfg.WebSocketWriteWait,
+			cfg.WebSocketPongWait,
+			cfg.WebSocketPingPeriod,
+			cfg.WebSocketMaxMsgSize,
+			cfg.WebSocketAuthTimeout,
+			cfg.WebSocketSendBufSize,
 		)
 	}
 	client.Start()
@@ -244,3 +833,28 @@ func toUserResponses(users []dto.UserSummary) []userResponse {
 	}
 	return result
 }
+
+func toConversationResponse(conversation conversationdomain.Conversation) conversationResponse {
+	return conversationResponse{
+		PeerID:         conversation.PeerID,
+		LastActivityAt: conversation.LastActivityAt,
+		UnreadCount:    conversation.UnreadCount,
+		Muted:          conversation.Muted,
+		Archived:       conversation.Archived,
+		Pinned:         conversation.Pinned,
+	}
+}
+
+func toWebhookResponse(webhook webhookdomain.Webhook) webhookResponse {
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+	return webhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    events,
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
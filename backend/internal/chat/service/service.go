@@ -2,35 +2,81 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/dto"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/mapper"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	conversationdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+	conversationservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/service"
 	identityservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/service"
+	privacydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+	privacyservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/service"
+	pushdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	pushservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/service"
+	reactiondomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+	reactionservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/service"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	receiptservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/service"
 	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
 	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
+	webhookdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
+	webhookservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/service"
 )
 
 type Service interface {
 	GetMe(ctx context.Context, userID string) (dto.User, error)
 	SearchUsers(ctx context.Context, query string, limit int) ([]dto.UserSummary, error)
 	GetIdentityKey(ctx context.Context, userID string) ([]byte, error)
+	ListReceipts(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]receiptdomain.Receipt, error)
+	ListReactions(ctx context.Context, userID, peerID string, messageIDs []string) ([]reactiondomain.Summary, error)
+	GetPrivacySettings(ctx context.Context, userID string) (privacydomain.Settings, error)
+	UpdatePrivacySettings(ctx context.Context, userID string, update privacydomain.SettingsUpdate) (privacydomain.Settings, error)
+	ListConversations(ctx context.Context, userID string, archived bool, limit int) ([]conversationdomain.Conversation, error)
+	UpdateConversation(ctx context.Context, userID, peerID string, update conversationdomain.Update) (conversationdomain.Conversation, error)
+	RegisterPushDevice(ctx context.Context, userID string, provider pushdomain.Provider, token string) (pushdomain.Device, error)
+	UnregisterPushDevice(ctx context.Context, userID, token string) error
+	PushPublicKey() string
+	SendBotMessage(ctx context.Context, botID string, payload websocket.MessagePayload) (bool, error)
+	CreateWebhook(ctx context.Context, ownerID, url string, events []webhookdomain.EventType) (webhookdomain.Webhook, error)
+	ListWebhooks(ctx context.Context, ownerID string) ([]webhookdomain.Webhook, error)
+	DeleteWebhook(ctx context.Context, ownerID, id string) error
+	ListWebhookDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]webhookdomain.DeadLetter, error)
 }
 
 type ChatService struct {
 	repo            userrepo.Repository
 	identityService identityservice.Service
+	searchBulkhead  resilience.CircuitBreakerInterface
+	receipts        receiptservice.Service
+	reactions       reactionservice.Service
+	privacy         privacyservice.Service
+	conversations   conversationservice.Service
+	push            pushservice.Service
+	webhooks        webhookservice.Service
+	messenger       websocket.MessageRouter
 	log             *logger.Logger
 }
 
 type ChatServiceDeps struct {
 	Repo            userrepo.Repository
 	IdentityService identityservice.Service
+	SearchBulkhead  resilience.CircuitBreakerInterface
+	Receipts        receiptservice.Service
+	Reactions       reactionservice.Service
+	Privacy         privacyservice.Service
+	Conversations   conversationservice.Service
+	Push            pushservice.Service
+	Webhooks        webhookservice.Service
+	Messenger       websocket.MessageRouter
 	Log             *logger.Logger
 }
 
@@ -38,6 +84,14 @@ func NewChatService(deps ChatServiceDeps) *ChatService {
 	return &ChatService{
 		repo:            deps.Repo,
 		identityService: deps.IdentityService,
+		searchBulkhead:  deps.SearchBulkhead,
+		receipts:        deps.Receipts,
+		reactions:       deps.Reactions,
+		privacy:         deps.Privacy,
+		conversations:   deps.Conversations,
+		push:            deps.Push,
+		webhooks:        deps.Webhooks,
+		messenger:       deps.Messenger,
 		log:             deps.Log,
 	}
 }
@@ -72,7 +126,21 @@ func (s *ChatService) SearchUsers(ctx context.Context, query string, limit int)
 	if limit > constants.MaxSearchResultsLimit {
 		limit = constants.MaxSearchResultsLimit
 	}
-	users, err := s.repo.SearchByUsername(ctx, q, limit)
+	var users []userdomain.Summary
+	search := func(ctx context.Context) error {
+		var searchErr error
+		users, searchErr = s.repo.SearchByUsername(ctx, q, limit)
+		return searchErr
+	}
+	var err error
+	if s.searchBulkhead != nil {
+		err = s.searchBulkhead.Call(ctx, search)
+	} else {
+		err = search(ctx)
+	}
+	if errors.Is(err, commonerrors.ErrBulkheadFull) {
+		return nil, err
+	}
 	if err != nil {
 		s.log.WithFields(ctx, logger.Fields{
 			"query":  q,
@@ -94,3 +162,105 @@ func (s *ChatService) GetIdentityKey(ctx context.Context, userID string) ([]byte
 	}
 	return key, nil
 }
+
+func (s *ChatService) ListReceipts(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]receiptdomain.Receipt, error) {
+	if s.receipts == nil {
+		return []receiptdomain.Receipt{}, nil
+	}
+	return s.receipts.ListConversation(ctx, userID, peerID, since, limit)
+}
+
+func (s *ChatService) ListReactions(ctx context.Context, userID, peerID string, messageIDs []string) ([]reactiondomain.Summary, error) {
+	if s.reactions == nil {
+		return []reactiondomain.Summary{}, nil
+	}
+	return s.reactions.Summarize(ctx, userID, peerID, messageIDs)
+}
+
+func (s *ChatService) GetPrivacySettings(ctx context.Context, userID string) (privacydomain.Settings, error) {
+	if s.privacy == nil {
+		return privacydomain.DefaultSettings(userID), nil
+	}
+	return s.privacy.GetSettings(ctx, userID)
+}
+
+func (s *ChatService) UpdatePrivacySettings(ctx context.Context, userID string, update privacydomain.SettingsUpdate) (privacydomain.Settings, error) {
+	if s.privacy == nil {
+		return privacydomain.Settings{}, commonerrors.ErrPrivacySettingsFailed
+	}
+	return s.privacy.UpdateSettings(ctx, userID, update)
+}
+
+func (s *ChatService) ListConversations(ctx context.Context, userID string, archived bool, limit int) ([]conversationdomain.Conversation, error) {
+	if s.conversations == nil {
+		return []conversationdomain.Conversation{}, nil
+	}
+	return s.conversations.List(ctx, userID, archived, limit)
+}
+
+func (s *ChatService) UpdateConversation(ctx context.Context, userID, peerID string, update conversationdomain.Update) (conversationdomain.Conversation, error) {
+	if s.conversations == nil {
+		return conversationdomain.Conversation{}, commonerrors.ErrConversationNotFound
+	}
+	return s.conversations.Update(ctx, userID, peerID, update)
+}
+
+func (s *ChatService) RegisterPushDevice(ctx context.Context, userID string, provider pushdomain.Provider, token string) (pushdomain.Device, error) {
+	if s.push == nil {
+		return pushdomain.Device{}, commonerrors.ErrPushProviderUnavailable
+	}
+	return s.push.RegisterDevice(ctx, userID, provider, token)
+}
+
+func (s *ChatService) UnregisterPushDevice(ctx context.Context, userID, token string) error {
+	if s.push == nil {
+		return commonerrors.ErrPushDeviceNotFound
+	}
+	return s.push.UnregisterDevice(ctx, userID, token)
+}
+
+func (s *ChatService) PushPublicKey() string {
+	if s.push == nil {
+		return ""
+	}
+	return s.push.VAPIDPublicKey()
+}
+
+func (s *ChatService) SendBotMessage(ctx context.Context, botID string, payload websocket.MessagePayload) (bool, error) {
+	if s.messenger == nil {
+		return false, commonerrors.ErrBotMessageFailed
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, commonerrors.ErrMarshalError.WithCause(err)
+	}
+	return s.messenger.Deliver(ctx, botID, &websocket.WSMessage{Type: websocket.TypeMessage, Payload: data})
+}
+
+func (s *ChatService) CreateWebhook(ctx context.Context, ownerID, url string, events []webhookdomain.EventType) (webhookdomain.Webhook, error) {
+	if s.webhooks == nil {
+		return webhookdomain.Webhook{}, commonerrors.ErrWebhookFailed
+	}
+	return s.webhooks.Create(ctx, ownerID, url, events)
+}
+
+func (s *ChatService) ListWebhooks(ctx context.Context, ownerID string) ([]webhookdomain.Webhook, error) {
+	if s.webhooks == nil {
+		return []webhookdomain.Webhook{}, nil
+	}
+	return s.webhooks.List(ctx, ownerID)
+}
+
+func (s *ChatService) DeleteWebhook(ctx context.Context, ownerID, id string) error {
+	if s.webhooks == nil {
+		return commonerrors.ErrWebhookNotFound
+	}
+	return s.webhooks.Delete(ctx, ownerID, id)
+}
+
+func (s *ChatService) ListWebhookDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]webhookdomain.DeadLetter, error) {
+	if s.webhooks == nil {
+		return nil, commonerrors.ErrWebhookNotFound
+	}
+	return s.webhooks.ListDeadLetters(ctx, ownerID, webhookID, limit)
+}
@@ -13,6 +13,7 @@ type TrackRequest struct {
 	From        string
 	To          string
 	TotalChunks int
+	ExpiresAt   time.Time
 }
 
 type Transfer struct {
@@ -23,6 +24,8 @@ type Transfer struct {
 	LastChunkAt    time.Time
 	ReceivedChunks int
 	TotalChunks    int
+	ExpiresAt      time.Time
+	Expired        bool
 }
 
 type Tracker interface {
@@ -32,6 +35,9 @@ type Tracker interface {
 	GetTransfersForUser(userID string) []*Transfer
 	GetTransferByID(fileID string) (*Transfer, bool)
 	CleanupStale() int
+	ExpireDue() []*Transfer
+	IsExpired(fileID string) bool
+	ActiveCount() int
 }
 
 type InMemoryTracker struct {
@@ -68,6 +74,7 @@ func (t *InMemoryTracker) Track(req TrackRequest) error {
 		LastChunkAt:    now,
 		TotalChunks:    req.TotalChunks,
 		ReceivedChunks: 0,
+		ExpiresAt:      req.ExpiresAt,
 	}
 
 	if _, loaded := t.transfers.LoadOrStore(req.FileID, transfer); loaded {
@@ -113,7 +120,7 @@ func (t *InMemoryTracker) GetTransfersForUser(userID string) []*Transfer {
 
 	t.transfers.Range(func(key, value interface{}) bool {
 		transfer := value.(*Transfer)
-		if transfer.From == userID || transfer.To == userID {
+		if !transfer.Expired && (transfer.From == userID || transfer.To == userID) {
 			copy := *transfer
 			result = append(result, &copy)
 		}
@@ -123,6 +130,17 @@ func (t *InMemoryTracker) GetTransfersForUser(userID string) []*Transfer {
 	return result
 }
 
+func (t *InMemoryTracker) ActiveCount() int {
+	count := 0
+	t.transfers.Range(func(key, value interface{}) bool {
+		if !value.(*Transfer).Expired {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
 func (t *InMemoryTracker) CleanupStale() int {
 	now := t.clock.Now()
 	removed := 0
@@ -138,3 +156,29 @@ func (t *InMemoryTracker) CleanupStale() int {
 
 	return removed
 }
+
+func (t *InMemoryTracker) ExpireDue() []*Transfer {
+	now := t.clock.Now()
+	var expired []*Transfer
+
+	t.transfers.Range(func(key, value interface{}) bool {
+		transfer := value.(*Transfer)
+		if !transfer.Expired && !transfer.ExpiresAt.IsZero() && !now.Before(transfer.ExpiresAt) {
+			transfer.Expired = true
+			copy := *transfer
+			expired = append(expired, &copy)
+		}
+		return true
+	})
+
+	return expired
+}
+
+func (t *InMemoryTracker) IsExpired(fileID string) bool {
+	value, ok := t.transfers.Load(fileID)
+	if !ok {
+		return false
+	}
+	transfer := value.(*Transfer)
+	return transfer.Expired || (!transfer.ExpiresAt.IsZero() && !t.clock.Now().Before(transfer.ExpiresAt))
+}
@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+)
+
+const (
+	BinaryFrameVersion   byte = 1
+	BinaryFrameFileChunk byte = 1
+)
+
+const (
+	binaryVersionOffset     = 0
+	binaryKindOffset        = 1
+	binaryPeerOffset        = 2
+	binarySeqOffset         = binaryPeerOffset + 16
+	binarySeqEpochOffset    = binarySeqOffset + 8
+	binaryChunkIndexOffset  = binarySeqEpochOffset + 8
+	binaryTotalChunksOffset = binaryChunkIndexOffset + 4
+	binaryFileIDOffset      = binaryTotalChunksOffset + 4
+	binaryHeaderSize        = binaryFileIDOffset + 1
+)
+
+var errShortBinaryFrame = errors.New("binary frame is truncated")
+
+type BinaryFrame struct {
+	data      []byte
+	fileIDEnd int
+	nonceEnd  int
+}
+
+func ParseBinaryFrame(data []byte) (*BinaryFrame, error) {
+	if len(data) < binaryHeaderSize {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(errShortBinaryFrame)
+	}
+	if data[binaryVersionOffset] != BinaryFrameVersion {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(fmt.Errorf("unsupported binary frame version %d", data[binaryVersionOffset]))
+	}
+	if data[binaryKindOffset] != BinaryFrameFileChunk {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(fmt.Errorf("unsupported binary frame kind %d", data[binaryKindOffset]))
+	}
+
+	fileIDEnd := binaryHeaderSize + int(data[binaryFileIDOffset])
+	if fileIDEnd >= len(data) {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(errShortBinaryFrame)
+	}
+	nonceEnd := fileIDEnd + 1 + int(data[fileIDEnd])
+	if nonceEnd > len(data) {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(errShortBinaryFrame)
+	}
+
+	return &BinaryFrame{data: data, fileIDEnd: fileIDEnd, nonceEnd: nonceEnd}, nil
+}
+
+func EncodeFileChunkFrame(payload FileChunkPayload, peer string) ([]byte, error) {
+	peerID, err := uuid.Parse(peer)
+	if err != nil {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(payload.Ciphertext)
+	if err != nil {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(payload.Nonce)
+	if err != nil {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(err)
+	}
+	if len(payload.FileID) > 255 || len(nonce) > 255 {
+		return nil, commonerrors.ErrInvalidBinaryFrame.WithCause(errors.New("file id or nonce exceeds 255 bytes"))
+	}
+	if payload.ChunkIndex < 0 || payload.TotalChunks < 0 {
+		return nil, commonerrors.ErrInvalidChunkIndex
+	}
+
+	data := make([]byte, binaryHeaderSize, binaryHeaderSize+len(payload.FileID)+1+len(nonce)+len(ciphertext))
+	data[binaryVersionOffset] = BinaryFrameVersion
+	data[binaryKindOffset] = BinaryFrameFileChunk
+	copy(data[binaryPeerOffset:binarySeqOffset], peerID[:])
+	binary.BigEndian.PutUint64(data[binarySeqOffset:], payload.Seq)
+	binary.BigEndian.PutUint64(data[binarySeqEpochOffset:], uint64(payload.SeqEpoch))
+	binary.BigEndian.PutUint32(data[binaryChunkIndexOffset:], uint32(payload.ChunkIndex))
+	binary.BigEndian.PutUint32(data[binaryTotalChunksOffset:], uint32(payload.TotalChunks))
+	data[binaryFileIDOffset] = byte(len(payload.FileID))
+	data = append(data, payload.FileID...)
+	data = append(data, byte(len(nonce)))
+	data = append(data, nonce...)
+	data = append(data, ciphertext...)
+	return data, nil
+}
+
+func (f *BinaryFrame) Type() MessageType {
+	return TypeFileChunk
+}
+
+func (f *BinaryFrame) Bytes() []byte {
+	return f.data
+}
+
+func (f *BinaryFrame) Peer() string {
+	peer, _ := uuid.FromBytes(f.data[binaryPeerOffset:binarySeqOffset])
+	return peer.String()
+}
+
+func (f *BinaryFrame) SetPeer(peer string) error {
+	peerID, err := uuid.Parse(peer)
+	if err != nil {
+		return commonerrors.ErrInvalidBinaryFrame.WithCause(err)
+	}
+	copy(f.data[binaryPeerOffset:binarySeqOffset], peerID[:])
+	return nil
+}
+
+func (f *BinaryFrame) Sequence() Sequence {
+	return Sequence{
+		Seq:      binary.BigEndian.Uint64(f.data[binarySeqOffset:]),
+		SeqEpoch: int64(binary.BigEndian.Uint64(f.data[binarySeqEpochOffset:])),
+	}
+}
+
+func (f *BinaryFrame) SetSequence(seq Sequence) {
+	binary.BigEndian.PutUint64(f.data[binarySeqOffset:], seq.Seq)
+	binary.BigEndian.PutUint64(f.data[binarySeqEpochOffset:], uint64(seq.SeqEpoch))
+}
+
+func (f *BinaryFrame) ChunkIndex() int {
+	return int(binary.BigEndian.Uint32(f.data[binaryChunkIndexOffset:]))
+}
+
+func (f *BinaryFrame) TotalChunks() int {
+	return int(binary.BigEndian.Uint32(f.data[binaryTotalChunksOffset:]))
+}
+
+func (f *BinaryFrame) FileID() string {
+	return string(f.data[binaryHeaderSize:f.fileIDEnd])
+}
+
+func (f *BinaryFrame) Nonce() []byte {
+	return f.data[f.fileIDEnd+1 : f.nonceEnd]
+}
+
+func (f *BinaryFrame) Ciphertext() []byte {
+	return f.data[f.nonceEnd:]
+}
+
+func (f *BinaryFrame) FileChunkPayload(to, from string) FileChunkPayload {
+	return FileChunkPayload{
+		Sequence:    f.Sequence(),
+		To:          to,
+		From:        from,
+		FileID:      f.FileID(),
+		ChunkIndex:  f.ChunkIndex(),
+		TotalChunks: f.TotalChunks(),
+		Ciphertext:  base64.StdEncoding.EncodeToString(f.Ciphertext()),
+		Nonce:       base64.StdEncoding.EncodeToString(f.Nonce()),
+	}
+}
@@ -0,0 +1,17 @@
+package websocket
+
+import (
+	"context"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+)
+
+func (r *messageRouter) Deliver(ctx context.Context, fromUserID string, msg *WSMessage) (bool, error) {
+	client := &Client{userID: fromUserID}
+	switch msg.Type {
+	case TypeMessage:
+		return r.deliverPayload(ctx, client, msg, &MessagePayload{}, "message", true, fromUserID, true)
+	default:
+		return false, commonerrors.ErrUnknownMessageType
+	}
+}
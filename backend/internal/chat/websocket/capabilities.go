@@ -0,0 +1,93 @@
+package websocket
+
+import "sort"
+
+const (
+	ProtocolVersionLegacy = 1
+	ProtocolVersion       = 4
+)
+
+var protocolMessageTypes = map[int][]MessageType{
+	1: {
+		TypeAuth, TypeEphemeralKey, TypeMessage, TypeSessionEstablished,
+		TypePeerOffline, TypePeerDisconnected, TypeFileStart, TypeFileChunk,
+		TypeFileComplete, TypeAck, TypeTyping, TypeReaction, TypeMessageDelete,
+		TypeMessageEdit, TypeMessageRead, TypeError,
+	},
+	2: {TypeServerRestarting, TypeAuthExpiring, TypeBackpressure},
+	3: {TypePresenceSubscribe, TypePresence},
+	4: {TypeDisappearingTimer},
+}
+
+var legacyMessageTypes = messageTypesUpTo(ProtocolVersionLegacy)
+
+type Capabilities struct {
+	Version int
+	types   map[MessageType]bool
+}
+
+func messageTypesUpTo(version int) map[MessageType]bool {
+	types := make(map[MessageType]bool)
+	for v := ProtocolVersionLegacy; v <= version; v++ {
+		for _, msgType := range protocolMessageTypes[v] {
+			types[msgType] = true
+		}
+	}
+	return types
+}
+
+func LegacyCapabilities() Capabilities {
+	return Capabilities{Version: ProtocolVersionLegacy, types: legacyMessageTypes}
+}
+
+func NegotiateCapabilities(version int, features []string) Capabilities {
+	if version < ProtocolVersionLegacy {
+		return LegacyCapabilities()
+	}
+	version = min(version, ProtocolVersion)
+	if len(features) == 0 {
+		return Capabilities{Version: version, types: messageTypesUpTo(version)}
+	}
+
+	known := messageTypesUpTo(ProtocolVersion)
+	types := map[MessageType]bool{TypeAuth: true, TypeError: true, TypeAck: true}
+	for _, feature := range features {
+		if msgType := MessageType(feature); known[msgType] {
+			types[msgType] = true
+		}
+	}
+	return Capabilities{Version: version, types: types}
+}
+
+func (c Capabilities) Supports(msgType MessageType) bool {
+	if c.types == nil {
+		return legacyMessageTypes[msgType]
+	}
+	return c.types[msgType]
+}
+
+func (c Capabilities) Features() []string {
+	features := make([]string, 0, len(c.types))
+	for msgType := range c.types {
+		features = append(features, string(msgType))
+	}
+	sort.Strings(features)
+	return features
+}
+
+func ServerFeatures() []string {
+	return Capabilities{Version: ProtocolVersion, types: messageTypesUpTo(ProtocolVersion)}.Features()
+}
+
+func fallbackMessage(payload payloadWithTo) (*WSMessage, bool) {
+	switch p := payload.(type) {
+	case *MessageReadPayload:
+		msg, err := marshalMessage(TypeAck, AckPayload{To: p.To, MessageID: p.MessageID})
+		if err != nil {
+			return nil, false
+		}
+		return msg, true
+	default:
+		return nil, false
+	}
+}
@@ -9,18 +9,38 @@ import (
 
 	gorillaWS "github.com/gorilla/websocket"
 
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
+type outboundFrame struct {
+	data      []byte
+	binary    bool
+	expiresAt time.Time
+}
+
+func (f outboundFrame) expired(now time.Time) bool {
+	if f.expiresAt.IsZero() || now.Before(f.expiresAt) {
+		return false
+	}
+	metrics.ChatDisappearingExpired.WithLabelValues("queued_message").Inc()
+	return true
+}
+
 type Client struct {
 	hub                 HubInterface
 	conn                *gorillaWS.Conn
 	userID              string
 	username            string
-	send                chan []byte
+	send                chan outboundFrame
+	codec               Codec
 	closed              atomic.Bool
+	binaryFrames        atomic.Bool
+	capabilities        atomic.Pointer[Capabilities]
 	log                 *logger.Logger
 	authenticated       bool
 	jwtSecret           []byte
@@ -30,6 +50,12 @@ type Client struct {
 	pingPeriod          time.Duration
 	maxMsgSize          int64
 	authTimeout         time.Duration
+	sessionMu           sync.Mutex
+	tokenJTI            string
+	expiresAt           time.Time
+	sessionExtended     chan struct{}
+	clock               clock.Clock
+	closeFrame          atomic.Value
 	ctx                 context.Context
 	cancel              context.CancelFunc
 	wg                  sync.WaitGroup
@@ -39,26 +65,89 @@ func (c *Client) UserID() string {
 	return c.userID
 }
 
+func (c *Client) BinaryFrames() bool {
+	return c.binaryFrames.Load()
+}
+
+func (c *Client) Capabilities() Capabilities {
+	if caps := c.capabilities.Load(); caps != nil {
+		return *caps
+	}
+	return LegacyCapabilities()
+}
+
+func (c *Client) negotiateCapabilities(authPayload AuthPayload) AuthResponsePayload {
+	caps := NegotiateCapabilities(authPayload.ProtocolVersion, authPayload.Features)
+	c.capabilities.Store(&caps)
+	return AuthResponsePayload{
+		Authenticated:   true,
+		BinaryFrames:    c.negotiateBinaryFrames(authPayload.BinaryFrames),
+		ProtocolVersion: caps.Version,
+		Features:        ServerFeatures(),
+	}
+}
+
+func (c *Client) Codec() Codec {
+	if c.codec == nil {
+		return JSONCodec
+	}
+	return c.codec
+}
+
+func (c *Client) encode(msg *WSMessage) (outboundFrame, error) {
+	codec := c.Codec()
+	data, err := codec.Encode(msg)
+	if err != nil {
+		return outboundFrame{}, err
+	}
+	return outboundFrame{data: data, binary: codec.FrameType() == gorillaWS.BinaryMessage}, nil
+}
+
+func (c *Client) decode(messageType int, data []byte) (*WSMessage, error) {
+	if messageType == gorillaWS.BinaryMessage {
+		return c.Codec().Decode(data)
+	}
+	return JSONCodec.Decode(data)
+}
+
+func (c *Client) negotiateBinaryFrames(requested bool) bool {
+	enabled := requested && c.Codec().FrameType() != gorillaWS.BinaryMessage
+	c.binaryFrames.Store(enabled)
+	return enabled
+}
+
 func (c *Client) sendAuthErrorAndClose(code, message string, closeCode int, closeText string) {
 	payload := AuthResponsePayload{Authenticated: false, Code: code, Message: message}
 	payloadBytes, err := json.Marshal(payload)
 	if err == nil {
 		msg := WSMessage{Type: TypeAuth, Payload: json.RawMessage(payloadBytes)}
-		msgBytes, err := json.Marshal(msg)
+		frame, err := c.encode(&msg)
 		if err == nil {
+			messageType := gorillaWS.TextMessage
+			if frame.binary {
+				messageType = gorillaWS.BinaryMessage
+			}
 			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
-			_ = c.conn.WriteMessage(gorillaWS.TextMessage, msgBytes)
+			_ = c.conn.WriteMessage(messageType, frame.data)
 		}
 	}
 	_ = c.conn.WriteMessage(gorillaWS.CloseMessage, gorillaWS.FormatCloseMessage(closeCode, closeText))
 }
 
+func hubClock(hub HubInterface) clock.Clock {
+	if h, ok := hub.(*Hub); ok && h.clock != nil {
+		return h.clock
+	}
+	return clock.NewRealClock()
+}
+
 func NewUnauthenticatedClient(hub HubInterface, conn *gorillaWS.Conn, jwtSecret string, log *logger.Logger, revokedTokenChecker jwtverify.RevokedTokenChecker, writeWait, pongWait, pingPeriod time.Duration, maxMsgSize int64, authTimeout time.Duration, sendBufSize int) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
 		hub:                 hub,
 		conn:                conn,
-		send:                make(chan []byte, sendBufSize),
+		send:                make(chan outboundFrame, sendBufSize),
+		codec:               CodecForSubprotocol(conn.Subprotocol()),
 		log:                 log,
 		authenticated:       false,
 		jwtSecret:           []byte(jwtSecret),
@@ -68,34 +157,42 @@ func NewUnauthenticatedClient(hub HubInterface, conn *gorillaWS.Conn, jwtSecret
 		pingPeriod:          pingPeriod,
 		maxMsgSize:          maxMsgSize,
 		authTimeout:         authTimeout,
+		sessionExtended:     make(chan struct{}, 1),
+		clock:               hubClock(hub),
 		ctx:                 ctx,
 		cancel:              cancel,
 	}
 }
 
-func NewAuthenticatedClient(hub HubInterface, conn *gorillaWS.Conn, claims jwtverify.Claims, log *logger.Logger, writeWait, pongWait, pingPeriod time.Duration, maxMsgSize int64, sendBufSize int) *Client {
+func NewAuthenticatedClient(hub HubInterface, conn *gorillaWS.Conn, claims jwtverify.Claims, jwtSecret string, log *logger.Logger, revokedTokenChecker jwtverify.RevokedTokenChecker, writeWait, pongWait, pingPeriod time.Duration, maxMsgSize int64, sendBufSize int) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 	client := &Client{
-		hub:           hub,
-		conn:          conn,
-		userID:        claims.UserID,
-		username:      claims.Username,
-		send:          make(chan []byte, sendBufSize),
-		log:           log,
-		authenticated: true,
-		writeWait:     writeWait,
-		pongWait:      pongWait,
-		pingPeriod:    pingPeriod,
-		maxMsgSize:    maxMsgSize,
-		ctx:           ctx,
-		cancel:        cancel,
+		hub:                 hub,
+		conn:                conn,
+		userID:              claims.UserID,
+		username:            claims.Username,
+		send:                make(chan outboundFrame, sendBufSize),
+		codec:               CodecForSubprotocol(conn.Subprotocol()),
+		log:                 log,
+		authenticated:       true,
+		jwtSecret:           []byte(jwtSecret),
+		revokedTokenChecker: revokedTokenChecker,
+		writeWait:           writeWait,
+		pongWait:            pongWait,
+		pingPeriod:          pingPeriod,
+		maxMsgSize:          maxMsgSize,
+		sessionExtended:     make(chan struct{}, 1),
+		clock:               hubClock(hub),
+		ctx:                 ctx,
+		cancel:              cancel,
 	}
+	client.setSession(claims)
 	_ = client.conn.SetReadDeadline(time.Now().Add(client.pongWait))
 	return client
 }
 
 func (c *Client) Start() {
-	c.wg.Add(2)
+	c.wg.Add(3)
 	go func() {
 		defer c.wg.Done()
 		c.writePump()
@@ -104,6 +201,10 @@ func (c *Client) Start() {
 		defer c.wg.Done()
 		c.readPump()
 	}()
+	go func() {
+		defer c.wg.Done()
+		c.watchSession()
+	}()
 }
 
 func (c *Client) Stop() {
@@ -172,7 +273,7 @@ func (c *Client) readPump() {
 			_ = c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
 		}
 
-		_, messageBytes, err := c.conn.ReadMessage()
+		messageType, messageBytes, err := c.conn.ReadMessage()
 		if err != nil {
 			if gorillaWS.IsUnexpectedCloseError(err, gorillaWS.CloseGoingAway, gorillaWS.CloseAbnormalClosure) {
 				if c.authenticated {
@@ -196,8 +297,20 @@ func (c *Client) readPump() {
 			return
 		}
 
-		var msg WSMessage
-		if err := json.Unmarshal(messageBytes, &msg); err != nil {
+		if messageType == gorillaWS.BinaryMessage && c.Codec().FrameType() != gorillaWS.BinaryMessage {
+			if !c.authenticated {
+				c.log.WithFields(c.ctx, logger.Fields{
+					"action": "ws_unauthorized_binary_frame",
+				}).Warn("websocket unauthenticated client sent binary frame")
+				c.sendAuthErrorAndClose("UNAUTHORIZED", "authentication required", gorillaWS.ClosePolicyViolation, "authentication required")
+				break
+			}
+			c.handleBinaryFrame(messageBytes)
+			continue
+		}
+
+		msg, err := c.decode(messageType, messageBytes)
+		if err != nil {
 			if c.authenticated {
 				c.log.WithFields(c.ctx, logger.Fields{
 					"user_id":  c.userID,
@@ -223,7 +336,7 @@ func (c *Client) readPump() {
 			}
 
 			var authPayload AuthPayload
-			if err := json.Unmarshal(msg.Payload, &authPayload); err != nil {
+			if err := msg.DecodePayload(&authPayload); err != nil {
 				c.log.WithFields(c.ctx, logger.Fields{
 					"action": "ws_invalid_auth_payload",
 				}).Warnf("websocket invalid auth payload: %v", err)
@@ -231,55 +344,21 @@ func (c *Client) readPump() {
 				break
 			}
 
-			claims, err := jwtverify.ParseToken(authPayload.Token, c.jwtSecret)
-			if err != nil {
-				c.log.WithFields(c.ctx, logger.Fields{
-					"action": "ws_auth_failed",
-				}).Warnf("websocket authentication failed: %v", err)
-				c.sendAuthErrorAndClose("INVALID_TOKEN", "invalid token", gorillaWS.ClosePolicyViolation, "invalid token")
+			claims, failure := c.verifyToken(authPayload.Token)
+			if failure != nil {
+				c.sendAuthErrorAndClose(failure.code, failure.message, failure.closeCode, failure.message)
 				break
 			}
 
-			if c.revokedTokenChecker != nil && claims.JTI != "" {
-				revoked, err := c.revokedTokenChecker.IsRevoked(c.ctx, claims.JTI)
-				if err != nil {
-					c.log.WithFields(c.ctx, logger.Fields{
-						"jti":    claims.JTI,
-						"action": "ws_auth_revoked_check_failed",
-					}).Errorf("websocket authentication failed: failed to check revoked token: %v", err)
-					c.sendAuthErrorAndClose("INTERNAL_ERROR", "internal error", gorillaWS.CloseInternalServerErr, "internal error")
-					break
-				}
-				if revoked {
-					c.log.WithFields(c.ctx, logger.Fields{
-						"jti":    claims.JTI,
-						"action": "ws_auth_token_revoked",
-					}).Warn("websocket authentication failed: token revoked")
-					c.sendAuthErrorAndClose("TOKEN_REVOKED", "token revoked", gorillaWS.ClosePolicyViolation, "token revoked")
-					break
-				}
-			}
-
 			c.userID = claims.UserID
 			c.username = claims.Username
 			c.authenticated = true
+			c.setSession(claims)
+			response := c.negotiateCapabilities(authPayload)
+			response.ExpiresAt = formatExpiry(claims.ExpiresAt)
 			_ = c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
 
-			authResponse := WSMessage{
-				Type:    TypeAuth,
-				Payload: json.RawMessage(`{"authenticated":true}`),
-			}
-			authResponseBytes, err := json.Marshal(authResponse)
-			if err == nil {
-				select {
-				case c.send <- authResponseBytes:
-				default:
-					c.log.WithFields(c.ctx, logger.Fields{
-						"user_id": c.userID,
-						"action":  "ws_auth_response_buffer_full",
-					}).Warn("websocket auth response send buffer full")
-				}
-			}
+			c.queueDirect(TypeAuth, response)
 
 			c.hub.Register(c)
 			c.log.WithFields(c.ctx, logger.Fields{
@@ -290,10 +369,41 @@ func (c *Client) readPump() {
 			continue
 		}
 
-		c.hub.HandleMessage(c, &msg)
+		if msg.Type == TypeAuth {
+			c.reauthenticate(msg)
+			continue
+		}
+
+		c.hub.HandleMessage(tracing.ExtractTraceParent(c.ctx, msg.TraceParent), c, msg)
 	}
 }
 
+func (c *Client) handleBinaryFrame(data []byte) {
+	if !c.binaryFrames.Load() {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": c.userID,
+			"action":  "ws_binary_frame_not_negotiated",
+		}).Warn("websocket binary frame received without negotiation")
+		metrics.ChatWebSocketErrors.WithLabelValues("binary_frame_not_negotiated").Inc()
+		c.queueError(commonerrors.ErrBinaryFramesNotNegotiated)
+		return
+	}
+
+	frame, err := ParseBinaryFrame(data)
+	if err != nil {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": c.userID,
+			"action":  "ws_invalid_binary_frame",
+		}).Warnf("websocket invalid binary frame: %v", err)
+		metrics.ChatWebSocketErrors.WithLabelValues("invalid_binary_frame").Inc()
+		c.queueError(commonerrors.ErrInvalidBinaryFrame)
+		return
+	}
+
+	metrics.ChatWebSocketBinaryFrames.WithLabelValues("received").Inc()
+	c.hub.HandleMessage(c.ctx, c, &WSMessage{Type: frame.Type(), Frame: frame})
+}
+
 func (c *Client) writePump() {
 	ticker := time.NewTicker(c.pingPeriod)
 	defer func() {
@@ -310,37 +420,61 @@ func (c *Client) writePump() {
 	for {
 		select {
 		case <-c.ctx.Done():
-			_ = c.conn.WriteMessage(gorillaWS.CloseMessage, []byte{})
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+			_ = c.conn.WriteMessage(gorillaWS.CloseMessage, c.closeMessage())
 			return
 
-		case message, ok := <-c.send:
+		case frame, ok := <-c.send:
 			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
 			if !ok {
 				_ = c.conn.WriteMessage(gorillaWS.CloseMessage, []byte{})
 				return
 			}
+			if frame.expired(c.clock.Now()) {
+				continue
+			}
+
+			if frame.binary {
+				if err := c.conn.WriteMessage(gorillaWS.BinaryMessage, frame.data); err != nil {
+					return
+				}
+				continue
+			}
 
 			w, err := c.conn.NextWriter(gorillaWS.TextMessage)
 			if err != nil {
 				return
 			}
-			_, _ = w.Write(message)
+			_, _ = w.Write(frame.data)
 
+			var pendingBinary []byte
 			n := len(c.send)
-			for i := 0; i < n; i++ {
+			for i := 0; i < n && pendingBinary == nil; i++ {
 				select {
 				case <-c.ctx.Done():
 					w.Close()
 					return
-				case msg := <-c.send:
+				case queued := <-c.send:
+					if queued.expired(c.clock.Now()) {
+						continue
+					}
+					if queued.binary {
+						pendingBinary = queued.data
+						continue
+					}
 					_, _ = w.Write([]byte{'\n'})
-					_, _ = w.Write(msg)
+					_, _ = w.Write(queued.data)
 				}
 			}
 
 			if err := w.Close(); err != nil {
 				return
 			}
+			if pendingBinary != nil {
+				if err := c.conn.WriteMessage(gorillaWS.BinaryMessage, pendingBinary); err != nil {
+					return
+				}
+			}
 
 		case <-ticker.C:
 			_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
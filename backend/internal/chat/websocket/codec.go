@@ -0,0 +1,469 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	gorillaWS "github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+)
+
+const (
+	SubprotocolJSON     = "dhchat.v1.json"
+	SubprotocolProtobuf = "dhchat.v1.proto"
+	SchemaVersion       = 1
+)
+
+type Codec interface {
+	Name() string
+	Subprotocol() string
+	FrameType() int
+	Encode(msg *WSMessage) ([]byte, error)
+	Decode(data []byte) (*WSMessage, error)
+}
+
+var (
+	JSONCodec     Codec = jsonCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+func Subprotocols() []string {
+	return []string{SubprotocolProtobuf, SubprotocolJSON}
+}
+
+func CodecForSubprotocol(subprotocol string) Codec {
+	if subprotocol == SubprotocolProtobuf {
+		return ProtobufCodec
+	}
+	return JSONCodec
+}
+
+func (m *WSMessage) DecodePayload(v interface{}) error {
+	if m.decoded != nil {
+		target := reflect.ValueOf(v)
+		source := reflect.ValueOf(m.decoded)
+		if target.Kind() == reflect.Ptr && target.Type() == source.Type() {
+			target.Elem().Set(source.Elem())
+			return nil
+		}
+	}
+	payload, err := m.PayloadJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
+
+func (m *WSMessage) PayloadJSON() (json.RawMessage, error) {
+	if len(m.Payload) > 0 || m.decoded == nil {
+		return m.Payload, nil
+	}
+	return json.Marshal(m.decoded)
+}
+
+func (m *WSMessage) setPayload(payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	m.Payload = payloadBytes
+	m.decoded = payload
+	m.raw = nil
+	return nil
+}
+
+func (m *WSMessage) payloadKey() []byte {
+	if len(m.Payload) > 0 {
+		return m.Payload
+	}
+	return m.raw
+}
+
+type jsonEncoderPoolItem struct {
+	buf *bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		buf := &bytes.Buffer{}
+		return &jsonEncoderPoolItem{
+			buf: buf,
+			enc: json.NewEncoder(buf),
+		}
+	},
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string        { return "json" }
+func (jsonCodec) Subprotocol() string { return SubprotocolJSON }
+func (jsonCodec) FrameType() int      { return gorillaWS.TextMessage }
+
+func (jsonCodec) Encode(msg *WSMessage) ([]byte, error) {
+	if len(msg.Payload) == 0 && msg.decoded != nil {
+		payload, err := msg.PayloadJSON()
+		if err != nil {
+			return nil, err
+		}
+		materialized := *msg
+		materialized.Payload = payload
+		msg = &materialized
+	}
+
+	item := jsonEncoderPool.Get().(*jsonEncoderPoolItem)
+	item.buf.Reset()
+	defer jsonEncoderPool.Put(item)
+
+	if err := item.enc.Encode(msg); err != nil {
+		return nil, err
+	}
+
+	messageBytes := item.buf.Bytes()
+	if len(messageBytes) > 0 && messageBytes[len(messageBytes)-1] == '\n' {
+		messageBytes = messageBytes[:len(messageBytes)-1]
+	}
+
+	messageBytesCopy := make([]byte, len(messageBytes))
+	copy(messageBytesCopy, messageBytes)
+	return messageBytesCopy, nil
+}
+
+func (jsonCodec) Decode(data []byte) (*WSMessage, error) {
+	var msg WSMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+const (
+	envelopeType        protowire.Number = 1
+	envelopePayload     protowire.Number = 2
+	envelopeTraceParent protowire.Number = 3
+	envelopeVersion     protowire.Number = 4
+)
+
+type authSchema struct {
+	Token           string   `json:"token,omitempty" pb:"1"`
+	BinaryFrames    bool     `json:"binary_frames,omitempty" pb:"2"`
+	Authenticated   bool     `json:"authenticated,omitempty" pb:"3"`
+	Code            string   `json:"code,omitempty" pb:"4"`
+	Message         string   `json:"message,omitempty" pb:"5"`
+	ExpiresAt       string   `json:"expires_at,omitempty" pb:"6"`
+	ProtocolVersion int      `json:"protocol_version,omitempty" pb:"7"`
+	Features        []string `json:"features,omitempty" pb:"8"`
+}
+
+var payloadSchemas = map[MessageType]reflect.Type{
+	TypeAuth:               reflect.TypeOf(authSchema{}),
+	TypeEphemeralKey:       reflect.TypeOf(EphemeralKeyPayload{}),
+	TypeMessage:            reflect.TypeOf(MessagePayload{}),
+	TypeSessionEstablished: reflect.TypeOf(SessionEstablishedPayload{}),
+	TypePeerOffline:        reflect.TypeOf(PeerOfflinePayload{}),
+	TypePeerDisconnected:   reflect.TypeOf(PeerDisconnectedPayload{}),
+	TypeFileStart:          reflect.TypeOf(FileStartPayload{}),
+	TypeFileChunk:          reflect.TypeOf(FileChunkPayload{}),
+	TypeFileComplete:       reflect.TypeOf(FileCompletePayload{}),
+	TypeAck:                reflect.TypeOf(AckPayload{}),
+	TypeTyping:             reflect.TypeOf(TypingPayload{}),
+	TypeReaction:           reflect.TypeOf(ReactionPayload{}),
+	TypeMessageDelete:      reflect.TypeOf(MessageDeletePayload{}),
+	TypeMessageEdit:        reflect.TypeOf(MessageEditPayload{}),
+	TypeMessageRead:        reflect.TypeOf(MessageReadPayload{}),
+	TypeError:              reflect.TypeOf(ErrorPayload{}),
+	TypeServerRestarting:   reflect.TypeOf(ServerRestartingPayload{}),
+	TypeAuthExpiring:       reflect.TypeOf(AuthExpiringPayload{}),
+	TypeBackpressure:       reflect.TypeOf(BackpressurePayload{}),
+	TypePresenceSubscribe:  reflect.TypeOf(PresenceSubscribePayload{}),
+	TypePresence:           reflect.TypeOf(PresencePayload{}),
+	TypeDisappearingTimer:  reflect.TypeOf(DisappearingTimerPayload{}),
+}
+
+type schemaField struct {
+	number protowire.Number
+	wire   protowire.Type
+	index  []int
+	bytes  bool
+}
+
+var schemaCache sync.Map
+
+func schemaFields(t reflect.Type) []schemaField {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.([]schemaField)
+	}
+	var fields []schemaField
+	collectSchemaFields(t, nil, &fields)
+	schemaCache.Store(t, fields)
+	return fields
+}
+
+func collectSchemaFields(t reflect.Type, index []int, fields *[]schemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := append(append([]int(nil), index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectSchemaFields(field.Type, path, fields)
+			continue
+		}
+
+		name, option, _ := strings.Cut(field.Tag.Get("pb"), ",")
+		number, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		wire := protowire.VarintType
+		if field.Type.Kind() == reflect.String || field.Type.Kind() == reflect.Slice {
+			wire = protowire.BytesType
+		}
+		*fields = append(*fields, schemaField{
+			number: protowire.Number(number),
+			wire:   wire,
+			index:  path,
+			bytes:  option == "bytes",
+		})
+	}
+}
+
+func appendSchemaFields(b []byte, v reflect.Value) ([]byte, error) {
+	for _, field := range schemaFields(v.Type()) {
+		value := v.FieldByIndex(field.index)
+		if value.IsZero() {
+			continue
+		}
+
+		if value.Kind() == reflect.Slice {
+			for i := 0; i < value.Len(); i++ {
+				b = protowire.AppendTag(b, field.number, field.wire)
+				b = protowire.AppendString(b, value.Index(i).String())
+			}
+			continue
+		}
+
+		b = protowire.AppendTag(b, field.number, field.wire)
+		switch value.Kind() {
+		case reflect.String:
+			if !field.bytes {
+				b = protowire.AppendString(b, value.String())
+				continue
+			}
+			raw, err := base64.StdEncoding.DecodeString(value.String())
+			if err != nil {
+				return nil, fmt.Errorf("field %d: %w", field.number, err)
+			}
+			b = protowire.AppendBytes(b, raw)
+		case reflect.Bool:
+			b = protowire.AppendVarint(b, protowire.EncodeBool(value.Bool()))
+		case reflect.Int, reflect.Int64:
+			b = protowire.AppendVarint(b, uint64(value.Int()))
+		case reflect.Uint64:
+			b = protowire.AppendVarint(b, value.Uint())
+		}
+	}
+	return b, nil
+}
+
+func consumeSchemaFields(data []byte, v reflect.Value) error {
+	fields := schemaFields(v.Type())
+	for len(data) > 0 {
+		number, wire, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		field, ok := findSchemaField(fields, number)
+		if !ok || field.wire != wire {
+			n = protowire.ConsumeFieldValue(number, wire, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value := v.FieldByIndex(field.index)
+		if wire == protowire.BytesType {
+			raw, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			if value.Kind() == reflect.Slice {
+				value.Set(reflect.Append(value, reflect.ValueOf(string(raw))))
+				continue
+			}
+			if field.bytes {
+				value.SetString(base64.StdEncoding.EncodeToString(raw))
+			} else {
+				value.SetString(string(raw))
+			}
+			continue
+		}
+
+		x, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch value.Kind() {
+		case reflect.Bool:
+			value.SetBool(protowire.DecodeBool(x))
+		case reflect.Int, reflect.Int64:
+			value.SetInt(int64(x))
+		case reflect.Uint64:
+			value.SetUint(x)
+		}
+	}
+	return nil
+}
+
+func findSchemaField(fields []schemaField, number protowire.Number) (schemaField, bool) {
+	for _, field := range fields {
+		if field.number == number {
+			return field, true
+		}
+	}
+	return schemaField{}, false
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string        { return "protobuf" }
+func (protobufCodec) Subprotocol() string { return SubprotocolProtobuf }
+func (protobufCodec) FrameType() int      { return gorillaWS.BinaryMessage }
+
+var protobufPayloadPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+func (protobufCodec) Encode(msg *WSMessage) ([]byte, error) {
+	scratch := protobufPayloadPool.Get().(*[]byte)
+	defer protobufPayloadPool.Put(scratch)
+
+	var payload []byte
+	hasPayload := msg.decoded != nil || (len(msg.Payload) > 0 && string(msg.Payload) != "null")
+	if hasPayload {
+		schema, ok := payloadSchemas[msg.Type]
+		if !ok {
+			return nil, commonerrors.ErrUnknownMessageType
+		}
+		value, err := schemaValue(msg, schema)
+		if err != nil {
+			return nil, commonerrors.ErrInvalidPayload.WithCause(err)
+		}
+		payload, err = appendSchemaFields((*scratch)[:0], value.Elem())
+		if err != nil {
+			return nil, commonerrors.ErrInvalidPayload.WithCause(err)
+		}
+		*scratch = payload
+	}
+
+	size := protowire.SizeTag(envelopeVersion) + protowire.SizeVarint(SchemaVersion) +
+		protowire.SizeTag(envelopeType) + protowire.SizeBytes(len(msg.Type))
+	if hasPayload {
+		size += protowire.SizeTag(envelopePayload) + protowire.SizeBytes(len(payload))
+	}
+	if msg.TraceParent != "" {
+		size += protowire.SizeTag(envelopeTraceParent) + protowire.SizeBytes(len(msg.TraceParent))
+	}
+
+	b := make([]byte, 0, size)
+	b = protowire.AppendTag(b, envelopeVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, SchemaVersion)
+	b = protowire.AppendTag(b, envelopeType, protowire.BytesType)
+	b = protowire.AppendString(b, string(msg.Type))
+	if hasPayload {
+		b = protowire.AppendTag(b, envelopePayload, protowire.BytesType)
+		b = protowire.AppendBytes(b, payload)
+	}
+	if msg.TraceParent != "" {
+		b = protowire.AppendTag(b, envelopeTraceParent, protowire.BytesType)
+		b = protowire.AppendString(b, msg.TraceParent)
+	}
+	return b, nil
+}
+
+func (protobufCodec) Decode(data []byte) (*WSMessage, error) {
+	msg := &WSMessage{}
+	var payload []byte
+	version := uint64(SchemaVersion)
+	for len(data) > 0 {
+		number, wire, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, commonerrors.ErrInvalidPayload.WithCause(protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		switch {
+		case number == envelopeVersion && wire == protowire.VarintType:
+			version, n = protowire.ConsumeVarint(data)
+		case number == envelopeType && wire == protowire.BytesType:
+			var raw []byte
+			raw, n = protowire.ConsumeBytes(data)
+			msg.Type = MessageType(raw)
+		case number == envelopePayload && wire == protowire.BytesType:
+			payload, n = protowire.ConsumeBytes(data)
+		case number == envelopeTraceParent && wire == protowire.BytesType:
+			var raw []byte
+			raw, n = protowire.ConsumeBytes(data)
+			msg.TraceParent = string(raw)
+		default:
+			n = protowire.ConsumeFieldValue(number, wire, data)
+		}
+		if n < 0 {
+			return nil, commonerrors.ErrInvalidPayload.WithCause(protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+
+	if version != SchemaVersion {
+		return nil, commonerrors.ErrInvalidPayload.WithCause(fmt.Errorf("unsupported schema version %d", version))
+	}
+	if msg.Type == "" {
+		return nil, commonerrors.ErrInvalidPayload.WithCause(errors.New("message type is missing"))
+	}
+
+	schema, ok := payloadSchemas[msg.Type]
+	if !ok {
+		return msg, nil
+	}
+	value := reflect.New(schema)
+	if err := consumeSchemaFields(payload, value.Elem()); err != nil {
+		return nil, commonerrors.ErrInvalidPayload.WithCause(err)
+	}
+	msg.decoded = value.Interface()
+	msg.raw = payload
+	return msg, nil
+}
+
+func schemaValue(msg *WSMessage, schema reflect.Type) (reflect.Value, error) {
+	if msg.decoded != nil {
+		value := reflect.ValueOf(msg.decoded)
+		if value.Type() == reflect.PtrTo(schema) && !value.IsNil() {
+			return value, nil
+		}
+	}
+	value := reflect.New(schema)
+	payload, err := msg.PayloadJSON()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := json.Unmarshal(payload, value.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
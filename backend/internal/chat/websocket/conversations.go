@@ -0,0 +1,40 @@
+package websocket
+
+import (
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+)
+
+type ConversationRecorder interface {
+	RecordMessage(senderID, recipientID string)
+	MarkRead(userID, peerID string)
+}
+
+func (r *messageRouter) recordConversation(from string, payload payloadWithTo, forwarded bool) {
+	if r.conversations == nil || payload.GetTo() == from {
+		return
+	}
+
+	switch p := payload.(type) {
+	case *MessagePayload:
+		if forwarded {
+			r.conversations.RecordMessage(from, p.To)
+		}
+	case *FileStartPayload:
+		if forwarded {
+			r.conversations.RecordMessage(from, p.To)
+		}
+	case *MessageReadPayload:
+		r.conversations.MarkRead(from, p.To)
+	}
+}
+
+func (r *messageRouter) markConversationRead(client *Client, msg *WSMessage) {
+	var payload MessageReadPayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return
+	}
+	if err := commonhttp.ValidateUUID(payload.To); err != nil {
+		return
+	}
+	r.recordConversation(client.userID, &payload, false)
+}
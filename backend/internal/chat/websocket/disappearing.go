@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+type DisappearingTimers interface {
+	TTL(ctx context.Context, userID, peerID string) (time.Duration, error)
+	SetTTL(ctx context.Context, userID, peerID string, ttl time.Duration) error
+}
+
+type Expiry struct {
+	TTLSeconds int64  `json:"ttl_seconds,omitempty" pb:"12"`
+	ExpiresAt  string `json:"expires_at,omitempty" pb:"13"`
+}
+
+func (e *Expiry) SetExpiry(expiry Expiry) { *e = expiry }
+
+func (e Expiry) Deadline() *time.Time {
+	if e.ExpiresAt == "" {
+		return nil
+	}
+	deadline, err := time.Parse(time.RFC3339, e.ExpiresAt)
+	if err != nil {
+		return nil
+	}
+	return &deadline
+}
+
+type payloadWithExpiry interface {
+	SetExpiry(expiry Expiry)
+}
+
+func (r *messageRouter) stampExpiry(ctx context.Context, client *Client, msg *WSMessage, payload payloadWithTo, msgType string) error {
+	p, ok := payload.(payloadWithExpiry)
+	if !ok {
+		return nil
+	}
+	p.SetExpiry(Expiry{})
+	if r.timers == nil {
+		return nil
+	}
+
+	ttl, err := r.timers.TTL(ctx, client.userID, payload.GetTo())
+	if err != nil {
+		return r.handleError(ctx, client, err, msgType, errorHandlerConfig{
+			err:              commonerrors.ErrDisappearingTimerFailed,
+			action:           "ws_disappearing_timer_failed",
+			metricLabel:      "disappearing_timer_failed",
+			sendToUser:       true,
+			logMessage:       "websocket failed to load disappearing timer: %v",
+			additionalFields: logger.Fields{"to": payload.GetTo()},
+		})
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	expiresAt := r.clock.Now().Add(ttl).UTC().Truncate(time.Second)
+	msg.ExpiresAt = expiresAt
+	p.SetExpiry(Expiry{TTLSeconds: int64(ttl / time.Second), ExpiresAt: expiresAt.Format(time.RFC3339)})
+	return nil
+}
+
+func (r *messageRouter) routeDisappearingTimer(ctx context.Context, client *Client, msg *WSMessage) error {
+	var payload DisappearingTimerPayload
+	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "disappearing_timer"); err != nil {
+		return err
+	}
+	if r.timers == nil || payload.To == client.userID {
+		return nil
+	}
+
+	var err error = commonerrors.ErrInvalidDisappearingTimer
+	if payload.TTLSeconds >= 0 && payload.TTLSeconds <= int64(constants.MaxDisappearingTTL/time.Second) {
+		err = r.timers.SetTTL(ctx, client.userID, payload.To, time.Duration(payload.TTLSeconds)*time.Second)
+	}
+	if err != nil {
+		wsErr := commonerrors.ErrDisappearingTimerFailed
+		if de, ok := commonerrors.AsDomainError(err); ok {
+			wsErr = de
+		}
+		return r.handleError(ctx, client, err, "disappearing_timer", errorHandlerConfig{
+			err:              wsErr,
+			action:           "ws_disappearing_timer_rejected",
+			metricLabel:      "disappearing_timer_rejected",
+			sendToUser:       true,
+			logMessage:       "websocket disappearing timer rejected: %v",
+			additionalFields: logger.Fields{"to": payload.To, "ttl_seconds": payload.TTLSeconds},
+		})
+	}
+
+	payload.From = client.userID
+	r.stampSequence(client.userID, &payload)
+	return r.marshalAndForward(ctx, client, msg, &payload, "disappearing_timer", true)
+}
+
+func (r *messageRouter) rejectExpiredTransfer(ctx context.Context, client *Client, fileID, msgType string) error {
+	return r.handleError(ctx, client, commonerrors.ErrMessageExpired, msgType, errorHandlerConfig{
+		err:              commonerrors.ErrMessageExpired,
+		action:           "ws_file_transfer_expired",
+		metricLabel:      "file_transfer_expired",
+		sendToUser:       true,
+		logMessage:       "websocket file transfer expired: %v",
+		additionalFields: logger.Fields{"file_id": fileID},
+	})
+}
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"context"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+const (
+	DeleteScopeMe       = "me"
+	DeleteScopeEveryone = "everyone"
+)
+
+func (r *messageRouter) routeMessageEdit(ctx context.Context, client *Client, msg *WSMessage) error {
+	var payload MessageEditPayload
+	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "message_edit"); err != nil {
+		return err
+	}
+
+	editCount := 0
+	if r.receipts != nil && payload.To != client.userID {
+		count, err := r.receipts.RecordEdit(ctx, client.userID, payload.To, payload.MessageID)
+		if err != nil {
+			return r.rejectMessageUpdate(ctx, client, err, "message_edit", payload.To, payload.MessageID)
+		}
+		editCount = count
+	}
+
+	payload.From = client.userID
+	payload.EditCount = editCount
+	r.stampSequence(client.userID, &payload)
+	return r.marshalAndForward(ctx, client, msg, &payload, "message_edit", true)
+}
+
+func (r *messageRouter) routeMessageDelete(ctx context.Context, client *Client, msg *WSMessage) error {
+	var payload MessageDeletePayload
+	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "message_delete"); err != nil {
+		return err
+	}
+
+	switch payload.Scope {
+	case "":
+		payload.Scope = DeleteScopeEveryone
+	case DeleteScopeMe, DeleteScopeEveryone:
+	default:
+		return r.handleError(ctx, client, commonerrors.ErrInvalidDeleteScope, "message_delete", errorHandlerConfig{
+			err:              commonerrors.ErrInvalidDeleteScope,
+			action:           "ws_invalid_delete_scope",
+			metricLabel:      "invalid_delete_scope",
+			sendToUser:       true,
+			logMessage:       "websocket invalid delete scope: %v",
+			additionalFields: logger.Fields{"to": payload.To, "message_id": payload.MessageID},
+		})
+	}
+
+	if payload.Scope == DeleteScopeMe {
+		return nil
+	}
+
+	if r.receipts != nil && payload.To != client.userID {
+		if err := r.receipts.RecordDelete(ctx, client.userID, payload.To, payload.MessageID); err != nil {
+			return r.rejectMessageUpdate(ctx, client, err, "message_delete", payload.To, payload.MessageID)
+		}
+	}
+
+	payload.From = client.userID
+	r.stampSequence(client.userID, &payload)
+	return r.marshalAndForward(ctx, client, msg, &payload, "message_delete", true)
+}
+
+func (r *messageRouter) rejectMessageUpdate(ctx context.Context, client *Client, err error, msgType, to, messageID string) error {
+	wsErr := commonerrors.ErrMessageUpdateFailed
+	if de, ok := commonerrors.AsDomainError(err); ok {
+		wsErr = de
+	}
+	return r.handleError(ctx, client, err, msgType, errorHandlerConfig{
+		err:              wsErr,
+		action:           "ws_" + msgType + "_rejected",
+		metricLabel:      msgType + "_rejected",
+		sendToUser:       true,
+		logMessage:       "websocket " + msgType + " rejected: %v",
+		additionalFields: logger.Fields{"to": to, "message_id": messageID},
+	})
+}
@@ -38,6 +38,9 @@ func (s *FileTransferService) Track(payload FileStartPayload) {
 		To:          payload.To,
 		TotalChunks: payload.TotalChunks,
 	}
+	if deadline := payload.Deadline(); deadline != nil {
+		req.ExpiresAt = *deadline
+	}
 
 	if err := s.tracker.Track(req); err != nil {
 		s.log.WithFields(s.ctx, logger.Fields{
@@ -131,6 +134,23 @@ func (s *FileTransferService) OnUserDisconnected(userID string) {
 	}
 }
 
+func (s *FileTransferService) IsExpired(fileID string) bool {
+	return s.tracker.IsExpired(fileID)
+}
+
+func (s *FileTransferService) DeleteExpired(ctx context.Context) (int64, error) {
+	expired := s.tracker.ExpireDue()
+	for _, tr := range expired {
+		s.NotifyFailed(tr)
+		s.sender.SendErrorToUser(tr.From, commonerrors.ErrMessageExpired)
+	}
+	return int64(len(expired)), nil
+}
+
+func (s *FileTransferService) ActiveTransfers() int {
+	return s.tracker.ActiveCount()
+}
+
 func (s *FileTransferService) StartCleanup() {
 	ticker := time.NewTicker(constants.WebSocketFileTrackerCleanupInterval)
 	defer ticker.Stop()
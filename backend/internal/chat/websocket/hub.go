@@ -1,9 +1,9 @@
 package websocket
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"math/rand/v2"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -38,29 +38,16 @@ func isValidAudioMimeType(mimeType string) bool {
 	return validAudioMimeTypes[normalized]
 }
 
-type jsonEncoderPoolItem struct {
-	buf *bytes.Buffer
-	enc *json.Encoder
-}
-
-var jsonEncoderPool = sync.Pool{
-	New: func() interface{} {
-		buf := &bytes.Buffer{}
-		return &jsonEncoderPoolItem{
-			buf: buf,
-			enc: json.NewEncoder(buf),
-		}
-	},
-}
-
 type Hub struct {
 	clients        sync.Map
 	register       chan *Client
 	unregister     chan *Client
 	clientCount    atomic.Int64
-	maxConnections int
+	heartbeat      atomic.Int64
+	draining       atomic.Bool
+	maxConnections atomic.Int64
 	log            *logger.Logger
-	sendTimeout    time.Duration
+	sendTimeout    atomic.Int64
 	clock          clock.Clock
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -97,16 +84,29 @@ func NewHub(deps HubDeps, config HubConfig) *Hub {
 	if timeClock == nil {
 		timeClock = clock.NewRealClock()
 	}
-	return &Hub{
-		register:       make(chan *Client),
-		unregister:     make(chan *Client),
-		log:            deps.Log,
-		sendTimeout:    config.SendTimeout,
-		maxConnections: config.MaxConnections,
-		clock:          timeClock,
-		ctx:            ctx,
-		cancel:         cancel,
+	hub := &Hub{
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		log:        deps.Log,
+		clock:      timeClock,
+		ctx:        ctx,
+		cancel:     cancel,
 	}
+	hub.SetMaxConnections(config.MaxConnections)
+	hub.SetSendTimeout(config.SendTimeout)
+	return hub
+}
+
+func (h *Hub) SetMaxConnections(maxConnections int) {
+	h.maxConnections.Store(int64(maxConnections))
+}
+
+func (h *Hub) MaxConnections() int {
+	return int(h.maxConnections.Load())
+}
+
+func (h *Hub) SetSendTimeout(timeout time.Duration) {
+	h.sendTimeout.Store(int64(timeout))
 }
 
 func (h *Hub) Context() context.Context {
@@ -118,6 +118,7 @@ func (h *Hub) Wire(messageHandler IncomingMessageHandler, presenceService *Prese
 	h.presenceService = presenceService
 	h.fileService = fileService
 	go presenceService.StartCleanup()
+	go presenceService.StartNotifier()
 	go fileService.StartCleanup()
 }
 
@@ -130,12 +131,19 @@ func (h *Hub) Unregister(client *Client) {
 }
 
 func (h *Hub) Run(ctx context.Context) {
+	heartbeat := time.NewTicker(constants.WebSocketHubHeartbeatInterval)
+	defer heartbeat.Stop()
+	h.beat()
+
 	for {
 		select {
 		case <-ctx.Done():
 			h.shutdown()
 			return
 
+		case <-heartbeat.C:
+			h.beat()
+
 		case client := <-h.register:
 			if existing, ok := h.clients.Load(client.userID); ok {
 				existingClient := existing.(*Client)
@@ -153,12 +161,13 @@ func (h *Hub) Run(ctx context.Context) {
 			}
 
 			currentCount := int(h.clientCount.Load())
-			if currentCount >= h.maxConnections {
+			maxConnections := h.MaxConnections()
+			if currentCount >= maxConnections {
 				observabilitymetrics.ChatWebSocketConnectionsRejected.Inc()
 				h.log.WithFields(client.ctx, logger.Fields{
 					"user_id": client.userID,
 					"current": currentCount,
-					"max":     h.maxConnections,
+					"max":     maxConnections,
 					"action":  "ws_register_rejected",
 				}).Warn("websocket connection rejected: max connections limit reached")
 				client.Stop()
@@ -179,6 +188,7 @@ func (h *Hub) Run(ctx context.Context) {
 			}).Info("websocket client registered")
 			if h.presenceService != nil {
 				h.presenceService.UpdateLastSeenDebounced(client.userID)
+				h.presenceService.UserOnline(client.userID)
 			}
 
 		case client := <-h.unregister:
@@ -187,6 +197,115 @@ func (h *Hub) Run(ctx context.Context) {
 	}
 }
 
+func (h *Hub) beat() {
+	h.heartbeat.Store(h.clock.Now().UnixNano())
+}
+
+func (h *Hub) LastHeartbeat() time.Time {
+	nanos := h.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func (h *Hub) IsDraining() bool {
+	return h.draining.Load()
+}
+
+func (h *Hub) Drain(ctx context.Context) {
+	if !h.draining.CompareAndSwap(false, true) {
+		return
+	}
+	start := h.clock.Now()
+
+	notified := h.broadcastServerRestarting(ctx)
+	droppedMessages := h.flushMessages(ctx)
+	transferCtx, cancel := h.transferDrainContext(ctx)
+	droppedTransfers := h.waitForFileTransfers(transferCtx)
+	cancel()
+	if droppedMessages == 0 {
+		droppedMessages = h.flushMessages(ctx)
+	}
+
+	if droppedTransfers > 0 {
+		observabilitymetrics.ChatWebSocketDrainDropped.WithLabelValues("file_transfer").Add(float64(droppedTransfers))
+	}
+	duration := h.clock.Since(start)
+	observabilitymetrics.ChatWebSocketDrainDurationSeconds.Observe(duration.Seconds())
+
+	h.log.WithFields(ctx, logger.Fields{
+		"clients":           notified,
+		"dropped_messages":  droppedMessages,
+		"dropped_transfers": droppedTransfers,
+		"duration_ms":       duration.Milliseconds(),
+		"action":            "ws_hub_drain",
+	}).Info("websocket hub drain completed")
+}
+
+func (h *Hub) broadcastServerRestarting(ctx context.Context) int {
+	notified := 0
+	h.clients.Range(func(key, value interface{}) bool {
+		client := value.(*Client)
+		msg, err := marshalMessage(TypeServerRestarting, ServerRestartingPayload{
+			Reason:           "shutdown",
+			ReconnectAfterMs: reconnectHint().Milliseconds(),
+		})
+		if err != nil {
+			h.log.WithFields(ctx, logger.Fields{
+				"action": "ws_server_restarting_marshal",
+			}).Errorf("websocket failed to marshal server_restarting message: %v", err)
+			return false
+		}
+		if err := h.SendToUserWithContext(ctx, client.userID, msg); err == nil {
+			notified++
+		}
+		return true
+	})
+	return notified
+}
+
+func (h *Hub) flushMessages(ctx context.Context) int {
+	if h.messageHandler == nil {
+		return 0
+	}
+	return h.messageHandler.Flush(ctx)
+}
+
+func (h *Hub) transferDrainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	reserve := time.Until(deadline) / constants.WebSocketDrainFlushReserveDivisor
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
+
+func (h *Hub) waitForFileTransfers(ctx context.Context) int {
+	if h.fileService == nil {
+		return 0
+	}
+
+	ticker := time.NewTicker(constants.WebSocketDrainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		active := h.fileService.ActiveTransfers()
+		if active == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return active
+		case <-ticker.C:
+		}
+	}
+}
+
+func reconnectHint() time.Duration {
+	return constants.WebSocketReconnectHintMin + rand.N(constants.WebSocketReconnectHintJitter)
+}
+
 func (h *Hub) shutdown() {
 	clients := make([]*Client, 0)
 	h.clients.Range(func(key, value interface{}) bool {
@@ -194,19 +313,18 @@ func (h *Hub) shutdown() {
 		return true
 	})
 
-	shutdownMsg, err := json.Marshal(&WSMessage{Type: "shutdown"})
-	if err != nil {
-		h.log.WithFields(h.ctx, logger.Fields{
-			"action": "ws_shutdown_marshal",
-		}).Errorf("websocket failed to marshal shutdown message: %v", err)
-	}
-
+	shutdownMsg := &WSMessage{Type: "shutdown"}
 	for _, client := range clients {
 		client.Stop()
-		if err == nil {
+		frame, err := client.encode(shutdownMsg)
+		if err != nil {
+			h.log.WithFields(h.ctx, logger.Fields{
+				"action": "ws_shutdown_marshal",
+			}).Errorf("websocket failed to marshal shutdown message: %v", err)
+		} else {
 			ctx, cancel := context.WithTimeout(context.Background(), constants.WebSocketShutdownNotificationTimeout)
 			select {
-			case client.send <- shutdownMsg:
+			case client.send <- frame:
 			case <-ctx.Done():
 				h.log.WithFields(ctx, logger.Fields{
 					"user_id": client.userID,
@@ -255,11 +373,8 @@ func (h *Hub) SendToUserWithContext(ctx context.Context, userID string, message
 
 	client := value.(*Client)
 
-	item := jsonEncoderPool.Get().(*jsonEncoderPoolItem)
-	item.buf.Reset()
-	defer jsonEncoderPool.Put(item)
-
-	if err := item.enc.Encode(message); err != nil {
+	frame, err := h.encodeFor(client, message)
+	if err != nil {
 		h.log.WithFields(ctx, logger.Fields{
 			"user_id": userID,
 			"action":  "ws_marshal",
@@ -268,15 +383,8 @@ func (h *Hub) SendToUserWithContext(ctx context.Context, userID string, message
 		return commonerrors.ErrMarshalError.WithCause(err)
 	}
 
-	messageBytes := item.buf.Bytes()
-	if len(messageBytes) > 0 && messageBytes[len(messageBytes)-1] == '\n' {
-		messageBytes = messageBytes[:len(messageBytes)-1]
-	}
-
-	messageBytesCopy := make([]byte, len(messageBytes))
-	copy(messageBytesCopy, messageBytes)
-
-	if err := h.sendWithTimeout(client.send, messageBytesCopy, userID, string(message.Type), ctx); err != nil {
+	frame.expiresAt = message.ExpiresAt
+	if err := h.sendWithTimeout(client.send, frame, userID, string(message.Type), ctx); err != nil {
 		return err
 	}
 
@@ -288,6 +396,46 @@ func (h *Hub) SendToUserWithContext(ctx context.Context, userID string, message
 	return nil
 }
 
+func (h *Hub) encodeFor(client *Client, message *WSMessage) (outboundFrame, error) {
+	if client.binaryFrames.Load() {
+		if data, ok := binaryFrameFor(message); ok {
+			observabilitymetrics.ChatWebSocketBinaryFrames.WithLabelValues("sent").Inc()
+			return outboundFrame{data: data, binary: true}, nil
+		}
+	}
+
+	if message.Frame != nil {
+		payloadBytes, err := json.Marshal(message.Frame.FileChunkPayload(client.userID, message.Frame.Peer()))
+		if err != nil {
+			return outboundFrame{}, err
+		}
+		observabilitymetrics.ChatWebSocketBinaryFrames.WithLabelValues("transcoded").Inc()
+		message = &WSMessage{Type: message.Type, Payload: payloadBytes, TraceParent: message.TraceParent}
+	}
+
+	return client.encode(message)
+}
+
+func binaryFrameFor(message *WSMessage) ([]byte, bool) {
+	if message.Frame != nil {
+		return message.Frame.Bytes(), true
+	}
+	if message.Type != TypeFileChunk {
+		return nil, false
+	}
+
+	var payload FileChunkPayload
+	if err := message.DecodePayload(&payload); err != nil {
+		return nil, false
+	}
+	data, err := EncodeFileChunkFrame(payload, payload.From)
+	if err != nil {
+		return nil, false
+	}
+	observabilitymetrics.ChatWebSocketBinaryFrames.WithLabelValues("transcoded").Inc()
+	return data, true
+}
+
 func (h *Hub) SendErrorToUser(userID string, err error) {
 	if err == nil {
 		return
@@ -319,15 +467,15 @@ func (h *Hub) SendErrorToUser(userID string, err error) {
 	h.SendToUser(userID, errorMsg)
 }
 
-func (h *Hub) sendWithTimeout(sendChan chan []byte, messageBytes []byte, userID, messageType string, ctx context.Context) error {
-	sendCtx, cancel := context.WithTimeout(ctx, h.sendTimeout)
+func (h *Hub) sendWithTimeout(sendChan chan outboundFrame, frame outboundFrame, userID, messageType string, ctx context.Context) error {
+	sendCtx, cancel := context.WithTimeout(ctx, time.Duration(h.sendTimeout.Load()))
 	defer cancel()
 	select {
-	case sendChan <- messageBytes:
+	case sendChan <- frame:
 		return nil
 	case <-sendCtx.Done():
 		select {
-		case sendChan <- messageBytes:
+		case sendChan <- frame:
 			return nil
 		default:
 			observabilitymetrics.ChatWebSocketDroppedMessages.WithLabelValues(messageType).Inc()
@@ -346,9 +494,31 @@ func (h *Hub) IsUserOnline(userID string) bool {
 	return ok
 }
 
-func (h *Hub) HandleMessage(client *Client, msg *WSMessage) {
+func (h *Hub) OnlineUserIDs() []string {
+	userIDs := make([]string, 0, h.clientCount.Load())
+	h.clients.Range(func(key, value interface{}) bool {
+		userIDs = append(userIDs, key.(string))
+		return true
+	})
+	return userIDs
+}
+
+func (h *Hub) SupportsMessageType(userID string, msgType MessageType) bool {
+	value, ok := h.clients.Load(userID)
+	if !ok {
+		return true
+	}
+	return value.(*Client).Capabilities().Supports(msgType)
+}
+
+func (h *Hub) HandleMessage(ctx context.Context, client *Client, msg *WSMessage) {
+	if msg.Type == TypeFileStart && h.IsDraining() {
+		observabilitymetrics.ChatWebSocketDrainDropped.WithLabelValues("rejected_file_transfer").Inc()
+		h.SendErrorToUser(client.userID, commonerrors.ErrServerRestarting)
+		return
+	}
 	if h.messageHandler != nil {
-		h.messageHandler.HandleMessage(client, msg)
+		h.messageHandler.HandleMessage(ctx, client, msg)
 	}
 }
 
@@ -376,23 +546,9 @@ func (h *Hub) handleUnregister(client *Client) {
 		"action":   "ws_unregister",
 	}).Info("websocket client unregistered")
 
-	msg, err := marshalMessage(TypePeerDisconnected, PeerDisconnectedPayload{PeerID: client.userID})
-	if err != nil {
-		h.log.WithFields(client.ctx, logger.Fields{
-			"user_id": client.userID,
-			"action":  "ws_marshal_peer_disconnected",
-		}).Errorf("websocket marshal peer_disconnected failed: %v", err)
-		return
+	if h.presenceService != nil {
+		h.presenceService.UserOffline(client.userID)
 	}
-	msgBytes, _ := json.Marshal(msg)
-	h.clients.Range(func(key, value interface{}) bool {
-		otherClient := value.(*Client)
-		select {
-		case otherClient.send <- msgBytes:
-		default:
-		}
-		return true
-	})
 }
 
 func (h *Hub) Shutdown() {
@@ -9,6 +9,8 @@ type HubInterface interface {
 	SendToUser(userID string, message *WSMessage) bool
 	SendToUserWithContext(ctx context.Context, userID string, message *WSMessage) error
 	IsUserOnline(userID string) bool
-	HandleMessage(client *Client, msg *WSMessage)
+	HandleMessage(ctx context.Context, client *Client, msg *WSMessage)
+	IsDraining() bool
+	Drain(ctx context.Context)
 	Shutdown()
 }
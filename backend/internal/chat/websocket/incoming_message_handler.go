@@ -2,14 +2,14 @@ package websocket
 
 import (
 	"context"
-	"encoding/json"
 	"strconv"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket/middleware"
 )
 
 type IncomingMessageHandler interface {
-	HandleMessage(client *Client, msg *WSMessage)
+	HandleMessage(ctx context.Context, client *Client, msg *WSMessage)
+	Flush(ctx context.Context) int
 	Shutdown()
 }
 
@@ -27,7 +27,7 @@ func NewIncomingMessageHandler(idempotency *IdempotencyTracker, idempotencyMiddl
 	}
 }
 
-func (h *incomingMessageHandler) HandleMessage(client *Client, msg *WSMessage) {
+func (h *incomingMessageHandler) HandleMessage(ctx context.Context, client *Client, msg *WSMessage) {
 	handler := func(ctx context.Context, c middleware.Client, m *middleware.WSMessage) error {
 		h.processor.Submit(ctx, client, msg)
 		return nil
@@ -35,39 +35,55 @@ func (h *incomingMessageHandler) HandleMessage(client *Client, msg *WSMessage) {
 
 	middlewareMsg := &middleware.WSMessage{
 		Type:    string(msg.Type),
-		Payload: msg.Payload,
+		Payload: msg.payloadKey(),
 	}
 
 	switch msg.Type {
 	case TypeEphemeralKey:
-		if err := h.idempotencyMiddleware.Handle(client.ctx, client, middlewareMsg, handler); err != nil {
+		if err := h.idempotencyMiddleware.Handle(ctx, client, middlewareMsg, handler); err != nil {
 			return
 		}
 		return
 
 	case TypeMessage:
 		var payload MessagePayload
-		if err := json.Unmarshal(msg.Payload, &payload); err == nil && payload.MessageID != "" {
-			operationID := h.idempotency.GenerateOperationID(client.userID+":"+payload.MessageID, msg.Type, msg.Payload)
-			if err := h.idempotencyMiddleware.HandleWithOperationID(client.ctx, client, middlewareMsg, operationID, handler); err != nil {
+		if err := msg.DecodePayload(&payload); err == nil && payload.MessageID != "" {
+			operationID := h.idempotency.GenerateOperationID(client.userID+":"+payload.MessageID, msg.Type, msg.payloadKey())
+			if err := h.idempotencyMiddleware.HandleWithOperationID(ctx, client, middlewareMsg, operationID, handler); err != nil {
 				return
 			}
 			return
 		}
 
 	case TypeFileChunk:
+		if msg.Frame != nil {
+			middlewareMsg.Payload = msg.Frame.Bytes()
+			chunkKey := client.userID + ":" + msg.Frame.FileID() + ":" + strconv.Itoa(msg.Frame.ChunkIndex())
+			operationID := h.idempotency.GenerateOperationID(chunkKey, msg.Type, msg.Frame.Bytes())
+			if err := h.idempotencyMiddleware.HandleWithOperationID(ctx, client, middlewareMsg, operationID, handler); err != nil {
+				return
+			}
+			return
+		}
 		var payload FileChunkPayload
-		if err := json.Unmarshal(msg.Payload, &payload); err == nil && payload.FileID != "" {
+		if err := msg.DecodePayload(&payload); err == nil && payload.FileID != "" {
 			chunkKey := client.userID + ":" + payload.FileID + ":" + strconv.Itoa(payload.ChunkIndex)
-			operationID := h.idempotency.GenerateOperationID(chunkKey, msg.Type, msg.Payload)
-			if err := h.idempotencyMiddleware.HandleWithOperationID(client.ctx, client, middlewareMsg, operationID, handler); err != nil {
+			operationID := h.idempotency.GenerateOperationID(chunkKey, msg.Type, msg.payloadKey())
+			if err := h.idempotencyMiddleware.HandleWithOperationID(ctx, client, middlewareMsg, operationID, handler); err != nil {
 				return
 			}
 			return
 		}
 	}
 
-	h.processor.Submit(client.ctx, client, msg)
+	h.processor.Submit(ctx, client, msg)
+}
+
+func (h *incomingMessageHandler) Flush(ctx context.Context) int {
+	if h.processor == nil {
+		return 0
+	}
+	return h.processor.Flush(ctx)
 }
 
 func (h *incomingMessageHandler) Shutdown() {
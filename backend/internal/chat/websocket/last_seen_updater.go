@@ -19,6 +19,7 @@ type LastSeenUpdater struct {
 	repo           userrepo.Repository
 	log            *logger.Logger
 	circuitBreaker *resilience.CircuitBreaker
+	bulkhead       *resilience.Bulkhead
 	clock          clock.Clock
 	updateInterval time.Duration
 	queue          chan string
@@ -27,7 +28,7 @@ type LastSeenUpdater struct {
 	wg             sync.WaitGroup
 }
 
-func NewLastSeenUpdater(ctx context.Context, repo userrepo.Repository, log *logger.Logger, updateInterval time.Duration, circuitBreaker *resilience.CircuitBreaker, clock clock.Clock) *LastSeenUpdater {
+func NewLastSeenUpdater(ctx context.Context, repo userrepo.Repository, log *logger.Logger, updateInterval time.Duration, circuitBreaker *resilience.CircuitBreaker, bulkhead *resilience.Bulkhead, clock clock.Clock) *LastSeenUpdater {
 	updateCtx, cancel := context.WithCancel(ctx)
 	updater := &LastSeenUpdater{
 		ctx:            updateCtx,
@@ -35,6 +36,7 @@ func NewLastSeenUpdater(ctx context.Context, repo userrepo.Repository, log *logg
 		repo:           repo,
 		log:            log,
 		circuitBreaker: circuitBreaker,
+		bulkhead:       bulkhead,
 		clock:          clock,
 		updateInterval: updateInterval,
 		queue:          make(chan string, constants.LastSeenQueueSize),
@@ -110,9 +112,11 @@ func (u *LastSeenUpdater) flush(pending map[string]struct{}) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.LastSeenUpdateTimeout)
 	defer cancel()
 
-	var err error
-	if u.circuitBreaker != nil {
-		err = u.circuitBreaker.CallWithFallback(ctx, func(callCtx context.Context) error {
+	update := func(ctx context.Context) error {
+		if u.circuitBreaker == nil {
+			return u.repo.UpdateLastSeenBatch(ctx, ids)
+		}
+		return u.circuitBreaker.CallWithFallback(ctx, func(callCtx context.Context) error {
 			return u.repo.UpdateLastSeenBatch(callCtx, ids)
 		}, func() error {
 			if u.log.ShouldLog(logger.DEBUG) {
@@ -123,8 +127,13 @@ func (u *LastSeenUpdater) flush(pending map[string]struct{}) {
 			}
 			return nil
 		})
+	}
+
+	var err error
+	if u.bulkhead != nil {
+		err = u.bulkhead.Call(ctx, update)
 	} else {
-		err = u.repo.UpdateLastSeenBatch(ctx, ids)
+		err = update(ctx)
 	}
 
 	if err != nil {
@@ -1,6 +1,9 @@
 package websocket
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type MessageType string
 
@@ -21,6 +24,12 @@ const (
 	TypeMessageEdit        MessageType = "message_edit"
 	TypeMessageRead        MessageType = "message_read"
 	TypeError              MessageType = "error"
+	TypeServerRestarting   MessageType = "server_restarting"
+	TypeAuthExpiring       MessageType = "auth_expiring"
+	TypeBackpressure       MessageType = "backpressure"
+	TypePresenceSubscribe  MessageType = "presence_subscribe"
+	TypePresence           MessageType = "presence"
+	TypeDisappearingTimer  MessageType = "disappearing_timer"
 )
 
 func (mt MessageType) String() string {
@@ -32,7 +41,9 @@ func (mt MessageType) IsValid() bool {
 	case TypeAuth, TypeEphemeralKey, TypeMessage, TypeSessionEstablished,
 		TypePeerOffline, TypePeerDisconnected, TypeFileStart, TypeFileChunk,
 		TypeFileComplete, TypeAck, TypeTyping, TypeReaction, TypeMessageDelete,
-		TypeMessageEdit, TypeMessageRead, TypeError:
+		TypeMessageEdit, TypeMessageRead, TypeError, TypeServerRestarting,
+		TypeAuthExpiring, TypeBackpressure, TypePresenceSubscribe, TypePresence,
+		TypeDisappearingTimer:
 		return true
 	default:
 		return false
@@ -40,120 +51,180 @@ func (mt MessageType) IsValid() bool {
 }
 
 type WSMessage struct {
-	Type    MessageType     `json:"type"`
-	Payload json.RawMessage `json:"payload"`
+	Type        MessageType     `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	TraceParent string          `json:"traceparent,omitempty"`
+	Frame       *BinaryFrame    `json:"-"`
+	ExpiresAt   time.Time       `json:"-"`
+	decoded     interface{}
+	raw         []byte
 }
 
 type EphemeralKeyPayload struct {
-	To          string `json:"to"`
-	From        string `json:"from,omitempty"`
-	PublicKey   string `json:"public_key"`
-	Signature   string `json:"signature"`
-	MessageID   string `json:"message_id"`
-	RequiresAck bool   `json:"requires_ack"`
+	Sequence
+	To          string `json:"to" pb:"1"`
+	From        string `json:"from,omitempty" pb:"2"`
+	PublicKey   string `json:"public_key" pb:"3,bytes"`
+	Signature   string `json:"signature" pb:"4,bytes"`
+	MessageID   string `json:"message_id" pb:"5"`
+	RequiresAck bool   `json:"requires_ack" pb:"6"`
 }
 
 type AckPayload struct {
-	To        string `json:"to"`
-	MessageID string `json:"message_id"`
+	To        string `json:"to" pb:"1"`
+	MessageID string `json:"message_id" pb:"2"`
 }
 
 type MessagePayload struct {
-	To         string `json:"to"`
-	From       string `json:"from,omitempty"`
-	MessageID  string `json:"message_id"`
-	Ciphertext string `json:"ciphertext"`
-	Nonce      string `json:"nonce"`
-	ReplyToID  string `json:"reply_to_message_id,omitempty"`
+	Sequence
+	Expiry
+	To         string `json:"to" pb:"1"`
+	From       string `json:"from,omitempty" pb:"2"`
+	MessageID  string `json:"message_id" pb:"3"`
+	Ciphertext string `json:"ciphertext" pb:"4,bytes"`
+	Nonce      string `json:"nonce" pb:"5,bytes"`
+	ReplyToID  string `json:"reply_to_message_id,omitempty" pb:"6"`
 }
 
 type SessionEstablishedPayload struct {
-	To     string `json:"to"`
-	PeerID string `json:"peer_id"`
+	To     string `json:"to" pb:"1"`
+	PeerID string `json:"peer_id" pb:"2"`
 }
 
 type PeerOfflinePayload struct {
-	PeerID string `json:"peer_id"`
+	PeerID string `json:"peer_id" pb:"1"`
 }
 
 type PeerDisconnectedPayload struct {
-	PeerID string `json:"peer_id"`
+	PeerID string `json:"peer_id" pb:"1"`
 }
 
 type FileStartPayload struct {
-	To          string `json:"to"`
-	From        string `json:"from,omitempty"`
-	FileID      string `json:"file_id"`
-	Filename    string `json:"filename"`
-	MimeType    string `json:"mime_type"`
-	TotalSize   int64  `json:"total_size"`
-	TotalChunks int    `json:"total_chunks"`
-	ChunkSize   int    `json:"chunk_size"`
-	AccessMode  string `json:"access_mode,omitempty"`
+	Sequence
+	Expiry
+	To          string `json:"to" pb:"1"`
+	From        string `json:"from,omitempty" pb:"2"`
+	FileID      string `json:"file_id" pb:"3"`
+	Filename    string `json:"filename" pb:"4"`
+	MimeType    string `json:"mime_type" pb:"5"`
+	TotalSize   int64  `json:"total_size" pb:"6"`
+	TotalChunks int    `json:"total_chunks" pb:"7"`
+	ChunkSize   int    `json:"chunk_size" pb:"8"`
+	AccessMode  string `json:"access_mode,omitempty" pb:"9"`
 }
 
 type FileChunkPayload struct {
-	To          string `json:"to"`
-	From        string `json:"from,omitempty"`
-	FileID      string `json:"file_id"`
-	ChunkIndex  int    `json:"chunk_index"`
-	TotalChunks int    `json:"total_chunks"`
-	Ciphertext  string `json:"ciphertext"`
-	Nonce       string `json:"nonce"`
+	Sequence
+	To          string `json:"to" pb:"1"`
+	From        string `json:"from,omitempty" pb:"2"`
+	FileID      string `json:"file_id" pb:"3"`
+	ChunkIndex  int    `json:"chunk_index" pb:"4"`
+	TotalChunks int    `json:"total_chunks" pb:"5"`
+	Ciphertext  string `json:"ciphertext" pb:"6,bytes"`
+	Nonce       string `json:"nonce" pb:"7,bytes"`
 }
 
 type FileCompletePayload struct {
-	To     string `json:"to"`
-	From   string `json:"from,omitempty"`
-	FileID string `json:"file_id"`
+	Sequence
+	To     string `json:"to" pb:"1"`
+	From   string `json:"from,omitempty" pb:"2"`
+	FileID string `json:"file_id" pb:"3"`
 }
 
 type AuthPayload struct {
-	Token string `json:"token"`
+	Token           string   `json:"token" pb:"1"`
+	BinaryFrames    bool     `json:"binary_frames,omitempty" pb:"2"`
+	ProtocolVersion int      `json:"protocol_version,omitempty" pb:"7"`
+	Features        []string `json:"features,omitempty" pb:"8"`
 }
 
 type AuthResponsePayload struct {
-	Authenticated bool   `json:"authenticated"`
-	Code          string `json:"code,omitempty"`
-	Message       string `json:"message,omitempty"`
+	Authenticated   bool     `json:"authenticated" pb:"3"`
+	Code            string   `json:"code,omitempty" pb:"4"`
+	Message         string   `json:"message,omitempty" pb:"5"`
+	ExpiresAt       string   `json:"expires_at,omitempty" pb:"6"`
+	BinaryFrames    bool     `json:"binary_frames,omitempty" pb:"2"`
+	ProtocolVersion int      `json:"protocol_version,omitempty" pb:"7"`
+	Features        []string `json:"features,omitempty" pb:"8"`
+}
+
+type AuthExpiringPayload struct {
+	ExpiresAt        string `json:"expires_at" pb:"1"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds" pb:"2"`
 }
 
 type TypingPayload struct {
-	To       string `json:"to"`
-	From     string `json:"from,omitempty"`
-	IsTyping bool   `json:"is_typing"`
+	Sequence
+	To       string `json:"to" pb:"1"`
+	From     string `json:"from,omitempty" pb:"2"`
+	IsTyping bool   `json:"is_typing" pb:"3"`
 }
 
 type ReactionPayload struct {
-	To        string `json:"to"`
-	From      string `json:"from,omitempty"`
-	MessageID string `json:"message_id"`
-	Emoji     string `json:"emoji"`
-	Action    string `json:"action"`
+	Sequence
+	To        string `json:"to" pb:"1"`
+	From      string `json:"from,omitempty" pb:"2"`
+	MessageID string `json:"message_id" pb:"3"`
+	Emoji     string `json:"emoji" pb:"4"`
+	Action    string `json:"action" pb:"5"`
 }
 
 type MessageDeletePayload struct {
-	To        string `json:"to"`
-	From      string `json:"from,omitempty"`
-	MessageID string `json:"message_id"`
-	Scope     string `json:"scope,omitempty"`
+	Sequence
+	To        string `json:"to" pb:"1"`
+	From      string `json:"from,omitempty" pb:"2"`
+	MessageID string `json:"message_id" pb:"3"`
+	Scope     string `json:"scope,omitempty" pb:"4"`
 }
 
 type MessageEditPayload struct {
-	To         string `json:"to"`
-	From       string `json:"from,omitempty"`
-	MessageID  string `json:"message_id"`
-	Ciphertext string `json:"ciphertext"`
-	Nonce      string `json:"nonce"`
+	Sequence
+	To         string `json:"to" pb:"1"`
+	From       string `json:"from,omitempty" pb:"2"`
+	MessageID  string `json:"message_id" pb:"3"`
+	Ciphertext string `json:"ciphertext" pb:"4,bytes"`
+	Nonce      string `json:"nonce" pb:"5,bytes"`
+	EditCount  int    `json:"edit_count,omitempty" pb:"6"`
 }
 
 type MessageReadPayload struct {
-	To        string `json:"to"`
-	From      string `json:"from,omitempty"`
-	MessageID string `json:"message_id"`
+	Sequence
+	To        string `json:"to" pb:"1"`
+	From      string `json:"from,omitempty" pb:"2"`
+	MessageID string `json:"message_id" pb:"3"`
+}
+
+type ServerRestartingPayload struct {
+	Reason           string `json:"reason" pb:"1"`
+	ReconnectAfterMs int64  `json:"reconnect_after_ms" pb:"2"`
+}
+
+type BackpressurePayload struct {
+	State        string `json:"state" pb:"1"`
+	Queued       int    `json:"queued" pb:"2"`
+	Limit        int    `json:"limit" pb:"3"`
+	RejectedType string `json:"rejected_type,omitempty" pb:"4"`
+	RetryAfterMs int64  `json:"retry_after_ms,omitempty" pb:"5"`
+}
+
+type PresenceSubscribePayload struct {
+	UserIDs []string `json:"user_ids" pb:"1"`
+}
+
+type PresencePayload struct {
+	UserID     string `json:"user_id" pb:"1"`
+	Status     string `json:"status" pb:"2"`
+	LastSeenAt string `json:"last_seen_at,omitempty" pb:"3"`
+}
+
+type DisappearingTimerPayload struct {
+	Sequence
+	To         string `json:"to" pb:"1"`
+	From       string `json:"from,omitempty" pb:"2"`
+	TTLSeconds int64  `json:"ttl_seconds" pb:"3"`
 }
 
 type ErrorPayload struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string `json:"code" pb:"1"`
+	Message string `json:"message" pb:"2"`
 }
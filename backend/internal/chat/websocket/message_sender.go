@@ -6,4 +6,5 @@ type MessageSender interface {
 	SendToUserWithContext(ctx context.Context, userID string, message *WSMessage) error
 	SendErrorToUser(userID string, err error)
 	IsUserOnline(userID string) bool
+	SupportsMessageType(userID string, msgType MessageType) bool
 }
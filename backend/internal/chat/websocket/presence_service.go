@@ -16,6 +16,21 @@ import (
 	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
 )
 
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+)
+
+type OnlineDirectory interface {
+	OnlineUserIDs() []string
+}
+
+type presenceEvent struct {
+	userID string
+	online bool
+	at     time.Time
+}
+
 type presenceCacheEntry struct {
 	exists    bool
 	expiresAt time.Time
@@ -26,36 +41,50 @@ type PresenceService struct {
 	userRepo       userrepo.Repository
 	lastSeen       *LastSeenUpdater
 	existenceCache sync.Map
+	privacy        PrivacyPolicy
+	directory      OnlineDirectory
+	subsMu         sync.Mutex
+	subscriptions  map[string]map[string]struct{}
+	subscribers    map[string]map[string]struct{}
+	events         chan presenceEvent
 	log            *logger.Logger
 	clock          clock.Clock
 	ctx            context.Context
 }
 
 type PresenceServiceDeps struct {
-	Sender   MessageSender
-	UserRepo userrepo.Repository
-	Log      *logger.Logger
-	Clock    clock.Clock
+	Sender    MessageSender
+	UserRepo  userrepo.Repository
+	Privacy   PrivacyPolicy
+	Directory OnlineDirectory
+	Log       *logger.Logger
+	Clock     clock.Clock
 }
 
 type PresenceServiceConfig struct {
 	LastSeenUpdateInterval time.Duration
 	CircuitBreaker         *resilience.CircuitBreaker
+	Bulkhead               *resilience.Bulkhead
 }
 
 func NewPresenceService(ctx context.Context, deps PresenceServiceDeps, config PresenceServiceConfig) *PresenceService {
 	var lastSeen *LastSeenUpdater
 	if deps.UserRepo != nil && config.LastSeenUpdateInterval > 0 {
-		lastSeen = NewLastSeenUpdater(ctx, deps.UserRepo, deps.Log, config.LastSeenUpdateInterval, config.CircuitBreaker, deps.Clock)
+		lastSeen = NewLastSeenUpdater(ctx, deps.UserRepo, deps.Log, config.LastSeenUpdateInterval, config.CircuitBreaker, config.Bulkhead, deps.Clock)
 	}
 
 	return &PresenceService{
-		sender:   deps.Sender,
-		userRepo: deps.UserRepo,
-		lastSeen: lastSeen,
-		log:      deps.Log,
-		clock:    deps.Clock,
-		ctx:      ctx,
+		sender:        deps.Sender,
+		userRepo:      deps.UserRepo,
+		lastSeen:      lastSeen,
+		privacy:       deps.Privacy,
+		directory:     deps.Directory,
+		subscriptions: make(map[string]map[string]struct{}),
+		subscribers:   make(map[string]map[string]struct{}),
+		events:        make(chan presenceEvent, constants.PresenceEventQueueSize),
+		log:           deps.Log,
+		clock:         deps.Clock,
+		ctx:           ctx,
 	}
 }
 
@@ -92,6 +121,11 @@ func (s *PresenceService) CheckUserExists(ctx context.Context, userID string) (b
 }
 
 func (s *PresenceService) SendPeerOffline(ctx context.Context, fromUserID, peerID string) error {
+	if !s.canSee(ctx, fromUserID, peerID) {
+		observabilitymetrics.ChatWebSocketPresenceUpdates.WithLabelValues("hidden").Inc()
+		return nil
+	}
+
 	msg, err := marshalMessage(TypePeerOffline, PeerOfflinePayload{PeerID: peerID})
 	if err != nil {
 		return commonerrors.ErrMarshalError.WithCause(err)
@@ -136,3 +170,182 @@ func (s *PresenceService) StartCleanup() {
 		}
 	}
 }
+
+func (s *PresenceService) Subscribe(ctx context.Context, subscriberID string, targetIDs []string) error {
+	if len(targetIDs) > constants.MaxPresenceSubscriptions {
+		return commonerrors.ErrTooManyPresenceSubscriptions
+	}
+
+	targets := make(map[string]struct{}, len(targetIDs))
+	for _, targetID := range targetIDs {
+		if targetID != subscriberID {
+			targets[targetID] = struct{}{}
+		}
+	}
+
+	s.subsMu.Lock()
+	s.unsubscribeLocked(subscriberID)
+	if len(targets) > 0 {
+		s.subscriptions[subscriberID] = targets
+		for targetID := range targets {
+			if s.subscribers[targetID] == nil {
+				s.subscribers[targetID] = make(map[string]struct{})
+			}
+			s.subscribers[targetID][subscriberID] = struct{}{}
+		}
+	}
+	s.subsMu.Unlock()
+
+	for targetID := range targets {
+		if s.sender.IsUserOnline(targetID) {
+			s.notify(ctx, subscriberID, targetID, true, nil)
+		} else {
+			s.notify(ctx, subscriberID, targetID, false, s.lastSeenAt(ctx, targetID))
+		}
+	}
+	return nil
+}
+
+func (s *PresenceService) Unsubscribe(subscriberID string) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	s.unsubscribeLocked(subscriberID)
+}
+
+func (s *PresenceService) UserOnline(userID string) {
+	s.enqueue(presenceEvent{userID: userID, online: true, at: s.clock.Now()})
+}
+
+func (s *PresenceService) UserOffline(userID string) {
+	s.enqueue(presenceEvent{userID: userID, online: false, at: s.clock.Now()})
+}
+
+func (s *PresenceService) StartNotifier() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case event := <-s.events:
+			s.publish(event)
+		}
+	}
+}
+
+func (s *PresenceService) unsubscribeLocked(subscriberID string) {
+	for targetID := range s.subscriptions[subscriberID] {
+		delete(s.subscribers[targetID], subscriberID)
+		if len(s.subscribers[targetID]) == 0 {
+			delete(s.subscribers, targetID)
+		}
+	}
+	delete(s.subscriptions, subscriberID)
+}
+
+func (s *PresenceService) subscribersOf(targetID string) []string {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	subscribers := make([]string, 0, len(s.subscribers[targetID]))
+	for subscriberID := range s.subscribers[targetID] {
+		subscribers = append(subscribers, subscriberID)
+	}
+	return subscribers
+}
+
+func (s *PresenceService) enqueue(event presenceEvent) {
+	select {
+	case s.events <- event:
+	default:
+		observabilitymetrics.ChatWebSocketPresenceUpdates.WithLabelValues("dropped").Inc()
+		s.log.WithFields(context.Background(), logger.Fields{
+			"user_id": event.userID,
+			"online":  event.online,
+			"action":  "ws_presence_event_dropped",
+		}).Warn("websocket presence queue is full, dropping event")
+	}
+}
+
+func (s *PresenceService) publish(event presenceEvent) {
+	if s.sender.IsUserOnline(event.userID) != event.online {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, constants.PresenceNotifyTimeout)
+	defer cancel()
+
+	var lastSeen *time.Time
+	if !event.online {
+		lastSeen = &event.at
+		s.Unsubscribe(event.userID)
+		s.broadcastDisconnected(ctx, event.userID)
+	}
+
+	for _, subscriberID := range s.subscribersOf(event.userID) {
+		s.notify(ctx, subscriberID, event.userID, event.online, lastSeen)
+	}
+}
+
+func (s *PresenceService) broadcastDisconnected(ctx context.Context, userID string) {
+	if s.directory == nil {
+		return
+	}
+
+	msg, err := marshalMessage(TypePeerDisconnected, PeerDisconnectedPayload{PeerID: userID})
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "ws_marshal_peer_disconnected",
+		}).Errorf("websocket marshal peer_disconnected failed: %v", err)
+		return
+	}
+
+	for _, viewerID := range s.directory.OnlineUserIDs() {
+		if viewerID == userID || !s.canSee(ctx, viewerID, userID) {
+			continue
+		}
+		_ = s.sender.SendToUserWithContext(ctx, viewerID, msg)
+	}
+}
+
+func (s *PresenceService) canSee(ctx context.Context, viewerID, targetID string) bool {
+	return s.privacy == nil || s.privacy.CanSeePresence(ctx, viewerID, targetID)
+}
+
+func (s *PresenceService) notify(ctx context.Context, subscriberID, targetID string, online bool, lastSeen *time.Time) {
+	if !s.canSee(ctx, subscriberID, targetID) {
+		observabilitymetrics.ChatWebSocketPresenceUpdates.WithLabelValues("hidden").Inc()
+		return
+	}
+
+	payload := PresencePayload{UserID: targetID, Status: PresenceOffline}
+	if online {
+		payload.Status = PresenceOnline
+	} else if lastSeen != nil {
+		payload.LastSeenAt = lastSeen.UTC().Format(time.RFC3339)
+	}
+
+	msg, err := marshalMessage(TypePresence, payload)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": subscriberID,
+			"action":  "ws_marshal_presence",
+		}).Errorf("websocket marshal presence failed: %v", err)
+		return
+	}
+	if err := s.sender.SendToUserWithContext(ctx, subscriberID, msg); err != nil {
+		observabilitymetrics.ChatWebSocketPresenceUpdates.WithLabelValues("failed").Inc()
+		return
+	}
+	observabilitymetrics.ChatWebSocketPresenceUpdates.WithLabelValues("sent").Inc()
+}
+
+func (s *PresenceService) lastSeenAt(ctx context.Context, userID string) *time.Time {
+	if s.userRepo == nil {
+		return nil
+	}
+	user, err := s.userRepo.FindByID(ctx, userdomain.ID(userID))
+	if err != nil {
+		return nil
+	}
+	return user.LastSeenAt
+}
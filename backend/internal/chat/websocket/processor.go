@@ -2,57 +2,278 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
+)
+
+type MessagePriority int
+
+const (
+	PriorityControl MessagePriority = iota
+	PriorityNormal
+	PriorityBulk
+	priorityLevels
+)
+
+func MessagePriorityOf(msgType MessageType) MessagePriority {
+	switch msgType {
+	case TypeAck, TypeEphemeralKey, TypeSessionEstablished:
+		return PriorityControl
+	case TypeFileChunk, TypeFileComplete:
+		return PriorityBulk
+	default:
+		return PriorityNormal
+	}
+}
+
+const (
+	BackpressureThrottle = "throttle"
+	BackpressureResume   = "resume"
+	BackpressureRejected = "rejected"
 )
 
+type BackpressureNotifier func(client *Client, payload BackpressurePayload)
+
 type messageTask struct {
 	client *Client
 	msg    *WSMessage
 	ctx    context.Context
+	key    string
+	seq    uint64
+}
+
+type clientQueue struct {
+	client    *Client
+	tasks     [priorityLevels][]messageTask
+	size      int
+	next      uint64
+	throttled bool
+}
+
+func (q *clientQueue) oldestForKey(task messageTask) bool {
+	for _, lane := range q.tasks {
+		for _, other := range lane {
+			if other.key == task.key {
+				if other.seq < task.seq {
+					return false
+				}
+				break
+			}
+		}
+	}
+	return true
+}
+
+func orderingKey(userID string, msg *WSMessage) string {
+	if msg.Frame != nil {
+		return userID + "|" + msg.Frame.Peer()
+	}
+	if target, ok := msg.decoded.(payloadWithTo); ok {
+		if to := target.GetTo(); to != "" {
+			return userID + "|" + to
+		}
+		return userID
+	}
+	var target struct {
+		To string `json:"to"`
+	}
+	if err := json.Unmarshal(msg.Payload, &target); err != nil || target.To == "" {
+		return userID
+	}
+	return userID + "|" + target.To
+}
+
+type MessageProcessorDeps struct {
+	Router   MessageRouter
+	Log      *logger.Logger
+	Notifier BackpressureNotifier
+}
+
+type MessageProcessorConfig struct {
+	Workers         int
+	QueueSize       int
+	ClientQueueSize int
 }
 
 type MessageProcessor struct {
-	workers   int
-	queue     chan messageTask
-	router    MessageRouter
-	log       *logger.Logger
-	queueSize int
+	router          MessageRouter
+	log             *logger.Logger
+	notifier        BackpressureNotifier
+	queueSize       int
+	clientQueueSize int
+	mu              sync.Mutex
+	cond            *sync.Cond
+	closed          bool
+	queued          int
+	clients         map[*Client]*clientQueue
+	order           []*Client
+	cursor          int
+	busy            map[string]bool
+	workers         int
+	target          int
+	pending         atomic.Int64
+	discard         atomic.Bool
 }
 
-func NewMessageProcessor(workers int, router MessageRouter, log *logger.Logger, queueSize int) *MessageProcessor {
+func NewMessageProcessor(deps MessageProcessorDeps, config MessageProcessorConfig) *MessageProcessor {
+	queueSize := config.QueueSize
 	if queueSize <= 0 {
 		queueSize = constants.WebSocketProcessorDefaultQueueSize
 	}
+	clientQueueSize := config.ClientQueueSize
+	if clientQueueSize <= 0 || clientQueueSize > queueSize {
+		clientQueueSize = min(constants.WebSocketProcessorClientQueueSize, queueSize)
+	}
+	notifier := deps.Notifier
+	if notifier == nil {
+		notifier = func(client *Client, payload BackpressurePayload) {
+			client.queueDirect(TypeBackpressure, payload)
+		}
+	}
 
 	p := &MessageProcessor{
-		workers:   workers,
-		queue:     make(chan messageTask, queueSize),
-		router:    router,
-		log:       log,
-		queueSize: queueSize,
+		router:          deps.Router,
+		log:             deps.Log,
+		notifier:        notifier,
+		queueSize:       queueSize,
+		clientQueueSize: clientQueueSize,
+		clients:         make(map[*Client]*clientQueue),
+		busy:            make(map[string]bool),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.Resize(config.Workers)
+
+	return p
+}
+
+func (p *MessageProcessor) Resize(workers int) {
+	if workers <= 0 {
+		return
 	}
 
-	for i := 0; i < workers; i++ {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	previous := p.target
+	p.target = workers
+	for p.workers < p.target {
+		p.workers++
 		go p.worker()
 	}
+	p.cond.Broadcast()
 
-	return p
+	if previous != 0 && previous != workers {
+		p.log.Infof("websocket message processor resized: %d -> %d workers", previous, workers)
+	}
+}
+
+func (p *MessageProcessor) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
 }
 
 func (p *MessageProcessor) worker() {
+	for {
+		task, resumed, ok := p.take()
+		if !ok {
+			return
+		}
+		if resumed != nil {
+			p.signal(resumed, BackpressurePayload{State: BackpressureResume, Limit: p.clientQueueSize})
+		}
+		p.run(task)
+	}
+}
+
+func (p *MessageProcessor) take() (messageTask, *Client, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if p.workers > p.target {
+			p.workers--
+			return messageTask{}, nil, false
+		}
+		if task, resumed, ok := p.nextLocked(); ok {
+			metrics.ChatWebSocketMessageProcessorQueueSize.Set(float64(p.queued))
+			return task, resumed, true
+		}
+		if p.closed && p.queued == 0 {
+			p.workers--
+			return messageTask{}, nil, false
+		}
+		p.cond.Wait()
+	}
+}
+
+func (p *MessageProcessor) nextLocked() (messageTask, *Client, bool) {
+	count := len(p.order)
+	for priority := MessagePriority(0); priority < priorityLevels; priority++ {
+		for i := 0; i < count; i++ {
+			index := (p.cursor + i) % count
+			queue := p.clients[p.order[index]]
+			for j, task := range queue.tasks[priority] {
+				if p.busy[task.key] || !queue.oldestForKey(task) {
+					continue
+				}
+				queue.tasks[priority] = append(queue.tasks[priority][:j], queue.tasks[priority][j+1:]...)
+				queue.size--
+				p.queued--
+				p.busy[task.key] = true
+				p.cursor = index + 1
+
+				var resumed *Client
+				if queue.throttled && queue.size <= p.clientQueueSize/4 {
+					queue.throttled = false
+					resumed = queue.client
+				}
+				if queue.size == 0 {
+					p.removeClientLocked(index)
+				}
+				return task, resumed, true
+			}
+		}
+	}
+	return messageTask{}, nil, false
+}
+
+func (p *MessageProcessor) removeClientLocked(index int) {
+	delete(p.clients, p.order[index])
+	p.order = append(p.order[:index], p.order[index+1:]...)
+	if p.cursor > index {
+		p.cursor--
+	}
+	if len(p.order) == 0 || p.cursor >= len(p.order) {
+		p.cursor = 0
+	}
+}
+
+func (p *MessageProcessor) run(task messageTask) {
 	defer func() {
 		if r := recover(); r != nil {
 			p.log.Errorf("websocket message processor worker panic: %v", r)
 		}
+		p.pending.Add(-1)
+		p.mu.Lock()
+		delete(p.busy, task.key)
+		p.cond.Broadcast()
+		p.mu.Unlock()
 	}()
-	for task := range p.queue {
-		metrics.ChatWebSocketMessageProcessorQueueSize.Set(float64(len(p.queue)))
-		p.process(task.ctx, task.client, task.msg)
+
+	if p.discard.Load() {
+		metrics.ChatWebSocketDrainDropped.WithLabelValues("queued_message").Inc()
+		return
 	}
+	p.process(task.ctx, task.client, task.msg)
 }
 
 func (p *MessageProcessor) process(ctx context.Context, client *Client, msg *WSMessage) {
@@ -60,7 +281,14 @@ func (p *MessageProcessor) process(ctx context.Context, client *Client, msg *WSM
 	ctx, cancel := context.WithTimeout(ctx, constants.WebSocketProcessorTimeout)
 	defer cancel()
 
-	if err := p.router.Route(ctx, client, msg); err != nil {
+	ctx, span := tracing.StartSpan(ctx, "ws.route "+string(msg.Type), trace.SpanKindConsumer,
+		attribute.String("messaging.system", "websocket"),
+		attribute.String("ws.message_type", string(msg.Type)),
+		attribute.String("enduser.id", client.userID),
+	)
+	err := p.router.Route(ctx, client, msg)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		p.log.WithFields(ctx, logger.Fields{
 			"user_id": client.userID,
 			"type":    string(msg.Type),
@@ -77,21 +305,130 @@ func (p *MessageProcessor) Submit(ctx context.Context, client *Client, msg *WSMe
 		client: client,
 		msg:    msg,
 		ctx:    ctx,
+		key:    orderingKey(client.userID, msg),
 	}
+	priority := MessagePriorityOf(msg.Type)
 
-	select {
-	case p.queue <- task:
-		metrics.ChatWebSocketMessageProcessorQueueSize.Set(float64(len(p.queue)))
-	default:
-		p.log.WithFields(ctx, logger.Fields{
-			"user_id": client.userID,
-			"type":    string(msg.Type),
-			"action":  "ws_queue_full",
-		}).Warn("websocket message queue full")
-		metrics.ChatWebSocketMessageProcessorQueueSize.Set(float64(len(p.queue)))
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		metrics.ChatWebSocketDrainDropped.WithLabelValues("rejected_message").Inc()
+		return
+	}
+
+	queue, ok := p.clients[client]
+	if !ok {
+		queue = &clientQueue{client: client}
+	}
+	reason := ""
+	switch {
+	case queue.size >= p.clientQueueSize:
+		reason = "client_queue_full"
+	case p.queued >= p.queueSize:
+		reason = "queue_full"
+	}
+	if reason != "" {
+		queued := queue.size
+		p.mu.Unlock()
+		p.reject(ctx, client, msg, reason, queued)
+		return
+	}
+
+	if !ok {
+		p.clients[client] = queue
+		p.order = append(p.order, client)
+	}
+	task.seq = queue.next
+	queue.next++
+	queue.tasks[priority] = append(queue.tasks[priority], task)
+	queue.size++
+	p.queued++
+	p.pending.Add(1)
+	throttle := !queue.throttled && queue.size >= p.clientQueueSize*3/4
+	if throttle {
+		queue.throttled = true
+	}
+	queued := queue.size
+	metrics.ChatWebSocketMessageProcessorQueueSize.Set(float64(p.queued))
+	p.cond.Signal()
+	p.mu.Unlock()
+
+	if throttle {
+		p.signal(client, BackpressurePayload{
+			State:        BackpressureThrottle,
+			Queued:       queued,
+			Limit:        p.clientQueueSize,
+			RetryAfterMs: constants.WebSocketBackpressureRetryAfter.Milliseconds(),
+		})
+	}
+}
+
+func (p *MessageProcessor) reject(ctx context.Context, client *Client, msg *WSMessage, reason string, queued int) {
+	p.log.WithFields(ctx, logger.Fields{
+		"user_id": client.userID,
+		"type":    string(msg.Type),
+		"reason":  reason,
+		"action":  "ws_queue_full",
+	}).Warn("websocket message queue full")
+	metrics.ChatWebSocketDroppedMessages.WithLabelValues(string(msg.Type)).Inc()
+
+	p.signal(client, BackpressurePayload{
+		State:        BackpressureRejected,
+		Queued:       queued,
+		Limit:        p.clientQueueSize,
+		RejectedType: string(msg.Type),
+		RetryAfterMs: constants.WebSocketBackpressureRetryAfter.Milliseconds(),
+	})
+}
+
+func (p *MessageProcessor) signal(client *Client, payload BackpressurePayload) {
+	metrics.ChatWebSocketBackpressureSignals.WithLabelValues(payload.State).Inc()
+	p.notifier(client, payload)
+}
+
+func (p *MessageProcessor) QueueLen() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queued
+}
+
+func (p *MessageProcessor) QueueCapacity() int {
+	return p.queueSize
+}
+
+func (p *MessageProcessor) ClientQueueLen(client *Client) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if queue, ok := p.clients[client]; ok {
+		return queue.size
+	}
+	return 0
+}
+
+func (p *MessageProcessor) Flush(ctx context.Context) int {
+	ticker := time.NewTicker(constants.WebSocketDrainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := int(p.pending.Load())
+		if pending == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			p.discard.Store(true)
+			return pending
+		case <-ticker.C:
+		}
 	}
 }
 
 func (p *MessageProcessor) Shutdown() {
-	close(p.queue)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	p.cond.Broadcast()
 }
@@ -0,0 +1,16 @@
+package websocket
+
+type PushNotifier interface {
+	NotifyOffline(userID string)
+}
+
+func (r *messageRouter) notifyOffline(msgType MessageType, userID string) {
+	if r.push == nil {
+		return
+	}
+
+	switch msgType {
+	case TypeMessage, TypeFileStart:
+		r.push.NotifyOffline(userID)
+	}
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	reactiondomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+)
+
+type ReactionStore interface {
+	Apply(ctx context.Context, reaction reactiondomain.Reaction) error
+}
+
+func (r *messageRouter) routeReaction(ctx context.Context, client *Client, msg *WSMessage) error {
+	var payload ReactionPayload
+	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "reaction"); err != nil {
+		return err
+	}
+
+	reaction := reactiondomain.Reaction{
+		MessageID: payload.MessageID,
+		UserID:    client.userID,
+		PeerID:    payload.To,
+		Emoji:     payload.Emoji,
+		Action:    reactiondomain.Action(payload.Action),
+	}
+	if reaction.MessageID == "" || !reaction.Action.Valid() || !reactiondomain.ValidEmoji(reaction.Emoji) {
+		return r.handleError(ctx, client, commonerrors.ErrInvalidReaction, "reaction", errorHandlerConfig{
+			err:              commonerrors.ErrInvalidReaction,
+			action:           "ws_invalid_reaction",
+			metricLabel:      "invalid_reaction",
+			sendToUser:       true,
+			logMessage:       "websocket invalid reaction: %v",
+			additionalFields: logger.Fields{"to": payload.To, "message_id": payload.MessageID},
+		})
+	}
+	if err := r.applyReaction(ctx, client, reaction); err != nil {
+		return err
+	}
+
+	payload.From = client.userID
+	r.stampSequence(client.userID, &payload)
+	return r.marshalAndForward(ctx, client, msg, &payload, "reaction", true)
+}
+
+func (r *messageRouter) applyReaction(ctx context.Context, client *Client, reaction reactiondomain.Reaction) error {
+	if r.reactions == nil || reaction.PeerID == client.userID {
+		return nil
+	}
+	if reaction.Action == reactiondomain.ActionAdd {
+		reaction.ExpiresAt = r.reactionExpiry(ctx, client.userID, reaction.PeerID)
+	}
+
+	err := r.reactions.Apply(ctx, reaction)
+	if errors.Is(err, commonerrors.ErrReactionLimit) {
+		return r.handleError(ctx, client, err, "reaction", errorHandlerConfig{
+			err:              commonerrors.ErrReactionLimit,
+			action:           "ws_reaction_limit",
+			metricLabel:      "reaction_limit",
+			sendToUser:       true,
+			logMessage:       "websocket reaction rejected: %v",
+			additionalFields: logger.Fields{"to": reaction.PeerID, "message_id": reaction.MessageID},
+		})
+	}
+	if err != nil {
+		r.log.WithFields(ctx, logger.Fields{
+			"user_id":    client.userID,
+			"to":         reaction.PeerID,
+			"message_id": reaction.MessageID,
+			"action":     "ws_reaction_persist_failed",
+		}).Warnf("websocket failed to persist reaction: %v", err)
+	}
+	return nil
+}
+
+func (r *messageRouter) reactionExpiry(ctx context.Context, userID, peerID string) *time.Time {
+	if r.timers == nil {
+		return nil
+	}
+	ttl, err := r.timers.TTL(ctx, userID, peerID)
+	if err != nil || ttl <= 0 {
+		return nil
+	}
+	expiresAt := r.clock.Now().Add(ttl).UTC().Truncate(time.Second)
+	return &expiresAt
+}
@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"context"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	observabilitymetrics "github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+)
+
+type ReceiptRecorder interface {
+	Record(receipt receiptdomain.Receipt)
+	RecordEdit(ctx context.Context, senderID, recipientID, messageID string) (int, error)
+	RecordDelete(ctx context.Context, senderID, recipientID, messageID string) error
+}
+
+type PrivacyPolicy interface {
+	ReadReceiptsEnabled(ctx context.Context, userID string) bool
+	CanSeePresence(ctx context.Context, viewerID, targetID string) bool
+}
+
+func (r *messageRouter) readReceiptsSuppressed(ctx context.Context, client *Client) bool {
+	if r.privacy == nil || r.privacy.ReadReceiptsEnabled(ctx, client.userID) {
+		return false
+	}
+
+	observabilitymetrics.ChatReceiptUpdates.WithLabelValues(string(receiptdomain.StatusRead), "suppressed").Inc()
+	if r.log.ShouldLog(logger.DEBUG) && r.log.ShouldSample(r.debugSampleRate) {
+		r.log.WithFields(ctx, logger.Fields{
+			"user_id": client.userID,
+			"action":  "ws_read_receipt_suppressed",
+		}).Debug("websocket read receipt suppressed by privacy settings")
+	}
+	return true
+}
+
+func (r *messageRouter) recordReceipt(from string, payload payloadWithTo, forwarded bool) {
+	if r.receipts == nil || payload.GetTo() == from {
+		return
+	}
+
+	switch p := payload.(type) {
+	case *MessagePayload:
+		if !forwarded {
+			return
+		}
+		r.receipts.Record(receiptdomain.Receipt{MessageID: p.MessageID, SenderID: from, RecipientID: p.To, Status: receiptdomain.StatusSent, ExpiresAt: p.Deadline()})
+	case *AckPayload:
+		r.receipts.Record(receiptdomain.Receipt{MessageID: p.MessageID, SenderID: p.To, RecipientID: from, Status: receiptdomain.StatusDelivered})
+	case *MessageReadPayload:
+		r.receipts.Record(receiptdomain.Receipt{MessageID: p.MessageID, SenderID: p.To, RecipientID: from, Status: receiptdomain.StatusRead})
+	}
+}
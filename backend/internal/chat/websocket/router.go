@@ -2,17 +2,19 @@ package websocket
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	observabilitymetrics "github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
 type MessageRouter interface {
 	Route(ctx context.Context, client *Client, msg *WSMessage) error
+	Deliver(ctx context.Context, fromUserID string, msg *WSMessage) (bool, error)
 }
 
 type messageRouter struct {
@@ -20,18 +22,61 @@ type messageRouter struct {
 	presence        *PresenceService
 	fileService     *FileTransferService
 	validator       MessageValidator
+	sequences       *SequenceTracker
+	receipts        ReceiptRecorder
+	privacy         PrivacyPolicy
+	conversations   ConversationRecorder
+	timers          DisappearingTimers
+	push            PushNotifier
+	webhooks        WebhookDispatcher
+	reactions       ReactionStore
+	clock           clock.Clock
 	log             *logger.Logger
 	debugSampleRate float64
 }
 
-func NewMessageRouter(sender MessageSender, presence *PresenceService, fileService *FileTransferService, validator MessageValidator, log *logger.Logger, debugSampleRate float64) MessageRouter {
+type MessageRouterDeps struct {
+	Sender        MessageSender
+	Presence      *PresenceService
+	FileService   *FileTransferService
+	Validator     MessageValidator
+	Sequences     *SequenceTracker
+	Receipts      ReceiptRecorder
+	Privacy       PrivacyPolicy
+	Conversations ConversationRecorder
+	Timers        DisappearingTimers
+	Push          PushNotifier
+	Webhooks      WebhookDispatcher
+	Reactions     ReactionStore
+	Clock         clock.Clock
+	Log           *logger.Logger
+}
+
+type MessageRouterConfig struct {
+	DebugSampleRate float64
+}
+
+func NewMessageRouter(deps MessageRouterDeps, config MessageRouterConfig) MessageRouter {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
 	return &messageRouter{
-		sender:          sender,
-		presence:        presence,
-		fileService:     fileService,
-		validator:       validator,
-		log:             log,
-		debugSampleRate: debugSampleRate,
+		sender:          deps.Sender,
+		presence:        deps.Presence,
+		fileService:     deps.FileService,
+		validator:       deps.Validator,
+		sequences:       deps.Sequences,
+		receipts:        deps.Receipts,
+		privacy:         deps.Privacy,
+		conversations:   deps.Conversations,
+		timers:          deps.Timers,
+		push:            deps.Push,
+		webhooks:        deps.Webhooks,
+		reactions:       deps.Reactions,
+		clock:           timeClock,
+		log:             deps.Log,
+		debugSampleRate: config.DebugSampleRate,
 	}
 }
 
@@ -51,6 +96,7 @@ func (p ReactionPayload) GetTo() string           { return p.To }
 func (p MessageDeletePayload) GetTo() string      { return p.To }
 func (p MessageEditPayload) GetTo() string        { return p.To }
 func (p MessageReadPayload) GetTo() string        { return p.To }
+func (p DisappearingTimerPayload) GetTo() string  { return p.To }
 
 type errorHandlerConfig struct {
 	err              commonerrors.DomainError
@@ -135,6 +181,15 @@ func (p *MessageDeletePayload) SetFrom(from string) { p.From = from }
 func (p *MessageEditPayload) SetFrom(from string)   { p.From = from }
 func (p *MessageReadPayload) SetFrom(from string)   { p.From = from }
 
+func (r *messageRouter) stampSequence(from string, payload payloadWithTo) {
+	if r.sequences == nil {
+		return
+	}
+	if p, ok := payload.(payloadWithSequence); ok {
+		p.SetSequence(r.sequences.Next(from, payload.GetTo()))
+	}
+}
+
 func (r *messageRouter) Route(ctx context.Context, client *Client, msg *WSMessage) error {
 	switch msg.Type {
 	case TypeEphemeralKey:
@@ -162,17 +217,27 @@ func (r *messageRouter) Route(ctx context.Context, client *Client, msg *WSMessag
 		return r.routeWithModifiedPayload(ctx, client, msg, &TypingPayload{}, "typing", true)
 
 	case TypeReaction:
-		return r.routeWithModifiedPayload(ctx, client, msg, &ReactionPayload{}, "reaction", true)
+		return r.routeReaction(ctx, client, msg)
 
 	case TypeMessageDelete:
-		return r.routeWithModifiedPayload(ctx, client, msg, &MessageDeletePayload{}, "message_delete", true)
+		return r.routeMessageDelete(ctx, client, msg)
 
 	case TypeMessageEdit:
-		return r.routeWithModifiedPayload(ctx, client, msg, &MessageEditPayload{}, "message_edit", true)
+		return r.routeMessageEdit(ctx, client, msg)
 
 	case TypeMessageRead:
+		if r.readReceiptsSuppressed(ctx, client) {
+			r.markConversationRead(client, msg)
+			return nil
+		}
 		return r.routeWithModifiedPayload(ctx, client, msg, &MessageReadPayload{}, "message_read", true)
 
+	case TypePresenceSubscribe:
+		return r.routePresenceSubscribe(ctx, client, msg)
+
+	case TypeDisappearingTimer:
+		return r.routeDisappearingTimer(ctx, client, msg)
+
 	default:
 		r.log.WithFields(ctx, logger.Fields{
 			"user_id": client.userID,
@@ -208,6 +273,10 @@ func (r *messageRouter) forwardMessage(ctx context.Context, msg *WSMessage, payl
 	}
 
 	if requireOnline && !r.sender.IsUserOnline(to) {
+		if r.dispatchWebhook(ctx, msg, to) {
+			return true
+		}
+		r.notifyOffline(msg.Type, to)
 		if fromUserID != "" {
 			if err := r.presence.SendPeerOffline(ctx, fromUserID, to); err != nil {
 				r.log.WithFields(ctx, logger.Fields{
@@ -250,6 +319,26 @@ func (r *messageRouter) forwardMessage(ctx context.Context, msg *WSMessage, payl
 		}
 	}
 
+	if !r.sender.SupportsMessageType(to, msg.Type) {
+		fallback, ok := fallbackMessage(payload)
+		if !ok || !r.sender.SupportsMessageType(to, fallback.Type) {
+			r.log.WithFields(ctx, logger.Fields{
+				"from":   fromUserID,
+				"to":     to,
+				"type":   string(msg.Type),
+				"action": "ws_message_unsupported_by_peer",
+			}).Info("websocket message type not supported by recipient")
+			observabilitymetrics.ChatWebSocketUnsupportedMessages.WithLabelValues(string(msg.Type), "rejected").Inc()
+			if fromUserID != "" {
+				r.sender.SendErrorToUser(fromUserID, commonerrors.ErrMessageTypeUnsupported)
+			}
+			return false
+		}
+		observabilitymetrics.ChatWebSocketUnsupportedMessages.WithLabelValues(string(msg.Type), "fallback").Inc()
+		msg = fallback
+	}
+
+	msg.TraceParent = tracing.TraceParent(ctx)
 	if err := r.sender.SendToUserWithContext(ctx, to, msg); err != nil {
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			r.log.WithFields(ctx, logger.Fields{
@@ -282,40 +371,63 @@ func (r *messageRouter) routeWithModifiedPayload(ctx context.Context, client *Cl
 }
 
 func (r *messageRouter) routePayload(ctx context.Context, client *Client, msg *WSMessage, payload payloadWithTo, msgType string, requireOnline bool, fromUserID string, modifyPayload bool) error {
-	if err := json.Unmarshal(msg.Payload, payload); err != nil {
-		return r.handleUnmarshalError(ctx, client, err, msgType)
+	_, err := r.deliverPayload(ctx, client, msg, payload, msgType, requireOnline, fromUserID, modifyPayload)
+	return err
+}
+
+func (r *messageRouter) deliverPayload(ctx context.Context, client *Client, msg *WSMessage, payload payloadWithTo, msgType string, requireOnline bool, fromUserID string, modifyPayload bool) (bool, error) {
+	if err := msg.DecodePayload(payload); err != nil {
+		return false, r.handleUnmarshalError(ctx, client, err, msgType)
 	}
 
 	to := payload.GetTo()
 	if err := r.handleValidateUserIDError(ctx, client, to, msgType); err != nil {
-		return err
+		return false, err
 	}
 
 	if modifyPayload {
 		if p, ok := payload.(payloadWithFrom); ok {
 			p.SetFrom(client.userID)
 		}
+		r.stampSequence(client.userID, payload)
+		if err := r.stampExpiry(ctx, client, msg, payload, msgType); err != nil {
+			return false, err
+		}
 
-		payloadBytes, err := json.Marshal(payload)
-		if err != nil {
-			return r.handleMarshalError(ctx, client, err, msgType)
+		if err := msg.setPayload(payload); err != nil {
+			return false, r.handleMarshalError(ctx, client, err, msgType)
 		}
-		msg.Payload = payloadBytes
 	}
 
-	if r.forwardMessage(ctx, msg, payload, requireOnline, fromUserID) {
-		observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues(msgType).Inc()
+	forwarded := r.forwardMessage(ctx, msg, payload, requireOnline, fromUserID)
+	r.recordReceipt(client.userID, payload, forwarded)
+	r.recordConversation(client.userID, payload, forwarded)
+	if !forwarded {
+		return false, nil
 	}
-	return nil
+	observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues(msgType).Inc()
+	return true, nil
 }
 
+type recipient string
+
+func (r recipient) GetTo() string { return string(r) }
+
 func (r *messageRouter) routeFileChunk(ctx context.Context, client *Client, msg *WSMessage) error {
+	if msg.Frame != nil {
+		return r.routeBinaryFileChunk(ctx, client, msg)
+	}
+
 	var payload FileChunkPayload
 	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "file_chunk"); err != nil {
 		return err
 	}
+	if r.fileService.IsExpired(payload.FileID) {
+		return r.rejectExpiredTransfer(ctx, client, payload.FileID, "file_chunk")
+	}
 
 	payload.From = client.userID
+	r.stampSequence(client.userID, &payload)
 	if err := r.marshalAndForward(ctx, client, msg, &payload, "file_chunk", true); err != nil {
 		return err
 	}
@@ -325,13 +437,42 @@ func (r *messageRouter) routeFileChunk(ctx context.Context, client *Client, msg
 	return nil
 }
 
+func (r *messageRouter) routeBinaryFileChunk(ctx context.Context, client *Client, msg *WSMessage) error {
+	frame := msg.Frame
+	to := frame.Peer()
+	if err := r.handleValidateUserIDError(ctx, client, to, "file_chunk"); err != nil {
+		return err
+	}
+	if r.fileService.IsExpired(frame.FileID()) {
+		return r.rejectExpiredTransfer(ctx, client, frame.FileID(), "file_chunk")
+	}
+	if err := frame.SetPeer(client.userID); err != nil {
+		return r.handleMarshalError(ctx, client, err, "file_chunk")
+	}
+	if r.sequences != nil {
+		frame.SetSequence(r.sequences.Next(client.userID, to))
+	}
+
+	if r.forwardMessage(ctx, msg, recipient(to), true, client.userID) {
+		observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues("file_chunk").Inc()
+	}
+
+	observabilitymetrics.ChatWebSocketFilesChunksTotal.Inc()
+	r.fileService.UpdateProgress(frame.FileID(), frame.ChunkIndex())
+	return nil
+}
+
 func (r *messageRouter) routeFileComplete(ctx context.Context, client *Client, msg *WSMessage) error {
 	var payload FileCompletePayload
 	if err := r.unmarshalAndValidate(ctx, client, msg, &payload, "file_complete"); err != nil {
 		return err
 	}
+	if r.fileService.IsExpired(payload.FileID) {
+		return r.rejectExpiredTransfer(ctx, client, payload.FileID, "file_complete")
+	}
 
 	payload.From = client.userID
+	r.stampSequence(client.userID, &payload)
 	if err := r.marshalAndForward(ctx, client, msg, &payload, "file_complete", true); err != nil {
 		return err
 	}
@@ -341,19 +482,17 @@ func (r *messageRouter) routeFileComplete(ctx context.Context, client *Client, m
 }
 
 func (r *messageRouter) unmarshalAndValidate(ctx context.Context, client *Client, msg *WSMessage, payload payloadWithTo, msgType string) error {
-	if err := json.Unmarshal(msg.Payload, payload); err != nil {
+	if err := msg.DecodePayload(payload); err != nil {
 		return r.handleUnmarshalError(ctx, client, err, msgType)
 	}
 	return r.handleValidateUserIDError(ctx, client, payload.GetTo(), msgType)
 }
 
 func (r *messageRouter) marshalAndForward(ctx context.Context, client *Client, msg *WSMessage, payload payloadWithTo, msgType string, requireOnline bool) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+	if err := msg.setPayload(payload); err != nil {
 		return r.handleMarshalError(ctx, client, err, msgType)
 	}
 
-	msg.Payload = payloadBytes
 	if r.forwardMessage(ctx, msg, payload, requireOnline, client.userID) {
 		observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues(msgType).Inc()
 	}
@@ -382,12 +521,15 @@ func (r *messageRouter) routeFileStart(ctx context.Context, client *Client, msg
 	}
 
 	payload.From = client.userID
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+	r.stampSequence(client.userID, &payload)
+	forwardMsg := &WSMessage{Type: msg.Type}
+	if err := r.stampExpiry(ctx, client, forwardMsg, &payload, "file_start"); err != nil {
+		return err
+	}
+	if err := forwardMsg.setPayload(&payload); err != nil {
 		return r.handleMarshalError(ctx, client, err, "file_start")
 	}
 
-	forwardMsg := &WSMessage{Type: msg.Type, Payload: payloadBytes}
 	if r.log.ShouldLog(logger.DEBUG) {
 		r.log.WithFields(ctx, logger.Fields{
 			"from":     client.userID,
@@ -402,6 +544,35 @@ func (r *messageRouter) routeFileStart(ctx context.Context, client *Client, msg
 		observabilitymetrics.ChatWebSocketFilesTotal.Inc()
 		observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues("file_start").Inc()
 		r.fileService.Track(payload)
+		r.recordConversation(client.userID, &payload, true)
+	}
+	return nil
+}
+
+func (r *messageRouter) routePresenceSubscribe(ctx context.Context, client *Client, msg *WSMessage) error {
+	var payload PresenceSubscribePayload
+	if err := msg.DecodePayload(&payload); err != nil {
+		return r.handleUnmarshalError(ctx, client, err, "presence_subscribe")
+	}
+	for _, userID := range payload.UserIDs {
+		if err := r.handleValidateUserIDError(ctx, client, userID, "presence_subscribe"); err != nil {
+			return err
+		}
+	}
+	if r.presence == nil {
+		return nil
+	}
+
+	if err := r.presence.Subscribe(ctx, client.userID, payload.UserIDs); err != nil {
+		return r.handleError(ctx, client, err, "presence_subscribe", errorHandlerConfig{
+			err:              commonerrors.ErrTooManyPresenceSubscriptions,
+			action:           "ws_presence_subscribe_rejected",
+			metricLabel:      "presence_subscribe_rejected",
+			sendToUser:       true,
+			logMessage:       "websocket presence subscription rejected: %v",
+			additionalFields: logger.Fields{"count": len(payload.UserIDs)},
+		})
 	}
+	observabilitymetrics.ChatWebSocketMessagesTotal.WithLabelValues("presence_subscribe").Inc()
 	return nil
 }
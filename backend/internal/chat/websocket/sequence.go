@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+)
+
+type Sequence struct {
+	Seq      uint64 `json:"seq,omitempty" pb:"14"`
+	SeqEpoch int64  `json:"seq_epoch,omitempty" pb:"15"`
+}
+
+func (s *Sequence) SetSequence(seq Sequence) { *s = seq }
+
+type payloadWithSequence interface {
+	SetSequence(seq Sequence)
+}
+
+type sequenceCounter struct {
+	epoch    int64
+	value    uint64
+	lastUsed time.Time
+}
+
+type SequenceTracker struct {
+	mu       sync.Mutex
+	counters map[string]*sequenceCounter
+	idleTTL  time.Duration
+	clock    clock.Clock
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func NewSequenceTracker(ctx context.Context, idleTTL time.Duration, clk clock.Clock) *SequenceTracker {
+	if idleTTL <= 0 {
+		idleTTL = constants.WebSocketSequenceIdleTTL
+	}
+	if clk == nil {
+		clk = clock.NewRealClock()
+	}
+
+	trackerCtx, cancel := context.WithCancel(ctx)
+	tracker := &SequenceTracker{
+		counters: make(map[string]*sequenceCounter),
+		idleTTL:  idleTTL,
+		clock:    clk,
+		ctx:      trackerCtx,
+		cancel:   cancel,
+	}
+
+	go tracker.cleanup()
+
+	return tracker
+}
+
+func (t *SequenceTracker) Next(from, to string) Sequence {
+	key := from + "|" + to
+	now := t.clock.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counter, ok := t.counters[key]
+	if !ok {
+		counter = &sequenceCounter{epoch: now.UnixMicro()}
+		t.counters[key] = counter
+	}
+	counter.value++
+	counter.lastUsed = now
+	return Sequence{Seq: counter.value, SeqEpoch: counter.epoch}
+}
+
+func (t *SequenceTracker) cleanup() {
+	ticker := time.NewTicker(t.idleTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.evictIdle()
+		}
+	}
+}
+
+func (t *SequenceTracker) evictIdle() {
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, counter := range t.counters {
+		if now.Sub(counter.lastUsed) > t.idleTTL {
+			delete(t.counters, key)
+		}
+	}
+}
+
+func (t *SequenceTracker) Shutdown() {
+	t.cancel()
+}
@@ -0,0 +1,268 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	gorillaWS "github.com/gorilla/websocket"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type authFailure struct {
+	code      string
+	message   string
+	closeCode int
+}
+
+func (c *Client) verifyToken(token string) (jwtverify.Claims, *authFailure) {
+	claims, err := jwtverify.ParseToken(token, c.jwtSecret)
+	if err != nil {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"action": "ws_auth_failed",
+		}).Warnf("websocket authentication failed: %v", err)
+		return jwtverify.Claims{}, &authFailure{code: "INVALID_TOKEN", message: "invalid token", closeCode: gorillaWS.ClosePolicyViolation}
+	}
+
+	if !claims.HasScope(jwtverify.ScopeChatConnect) {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": claims.UserID,
+			"action":  "ws_auth_insufficient_scope",
+		}).Warn("websocket authentication failed: token lacks chat:connect scope")
+		return jwtverify.Claims{}, &authFailure{code: "INSUFFICIENT_SCOPE", message: "insufficient scope", closeCode: gorillaWS.ClosePolicyViolation}
+	}
+
+	if c.revokedTokenChecker != nil && claims.JTI != "" {
+		revoked, err := c.revokedTokenChecker.IsRevoked(c.ctx, claims.JTI)
+		if err != nil {
+			c.log.WithFields(c.ctx, logger.Fields{
+				"jti":    claims.JTI,
+				"action": "ws_auth_revoked_check_failed",
+			}).Errorf("websocket authentication failed: failed to check revoked token: %v", err)
+			return jwtverify.Claims{}, &authFailure{code: "INTERNAL_ERROR", message: "internal error", closeCode: gorillaWS.CloseInternalServerErr}
+		}
+		if revoked {
+			c.log.WithFields(c.ctx, logger.Fields{
+				"jti":    claims.JTI,
+				"action": "ws_auth_token_revoked",
+			}).Warn("websocket authentication failed: token revoked")
+			return jwtverify.Claims{}, &authFailure{code: "TOKEN_REVOKED", message: "token revoked", closeCode: constants.WebSocketCloseTokenRevoked}
+		}
+	}
+
+	return claims, nil
+}
+
+func (c *Client) reauthenticate(msg *WSMessage) {
+	var authPayload AuthPayload
+	if err := msg.DecodePayload(&authPayload); err != nil {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": c.userID,
+			"action":  "ws_invalid_reauth_payload",
+		}).Warnf("websocket invalid re-auth payload: %v", err)
+		c.queueDirect(TypeAuth, AuthResponsePayload{Authenticated: false, Code: "INVALID_AUTH_PAYLOAD", Message: "invalid auth payload"})
+		return
+	}
+
+	claims, failure := c.verifyToken(authPayload.Token)
+	if failure != nil {
+		c.queueDirect(TypeAuth, AuthResponsePayload{Authenticated: false, Code: failure.code, Message: failure.message})
+		return
+	}
+
+	if claims.UserID != c.userID {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id":       c.userID,
+			"token_user_id": claims.UserID,
+			"action":        "ws_reauth_user_mismatch",
+		}).Warn("websocket re-authentication rejected: token belongs to another user")
+		metrics.ChatWebSocketDisconnections.WithLabelValues("reauth_user_mismatch").Inc()
+		c.Disconnect(gorillaWS.ClosePolicyViolation, "user mismatch")
+		return
+	}
+
+	c.setSession(claims)
+	response := c.negotiateCapabilities(authPayload)
+	response.ExpiresAt = formatExpiry(claims.ExpiresAt)
+	c.queueDirect(TypeAuth, response)
+	c.log.WithFields(c.ctx, logger.Fields{
+		"user_id":    c.userID,
+		"expires_at": formatExpiry(claims.ExpiresAt),
+		"action":     "ws_reauthenticated",
+	}).Info("websocket session extended")
+}
+
+func (c *Client) setSession(claims jwtverify.Claims) {
+	c.sessionMu.Lock()
+	c.tokenJTI = claims.JTI
+	c.expiresAt = claims.ExpiresAt
+	c.sessionMu.Unlock()
+
+	select {
+	case c.sessionExtended <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) TokenJTI() string {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.tokenJTI
+}
+
+func (c *Client) ExpiresAt() time.Time {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.expiresAt
+}
+
+func (c *Client) watchSession() {
+	warnedFor := time.Time{}
+	for {
+		expiresAt := c.ExpiresAt()
+		var timerC <-chan time.Time
+		var timer *time.Timer
+
+		if !expiresAt.IsZero() {
+			now := c.clock.Now()
+			warnAt := expiresAt.Add(-constants.WebSocketAuthExpiryWarning)
+			switch {
+			case !now.Before(expiresAt):
+				c.expire()
+				return
+			case now.Before(warnAt):
+				timer = time.NewTimer(warnAt.Sub(now))
+			case !warnedFor.Equal(expiresAt):
+				warnedFor = expiresAt
+				c.queueDirect(TypeAuthExpiring, AuthExpiringPayload{
+					ExpiresAt:        formatExpiry(expiresAt),
+					ExpiresInSeconds: int64(expiresAt.Sub(now).Seconds()),
+				})
+				continue
+			default:
+				timer = time.NewTimer(expiresAt.Sub(now))
+			}
+			timerC = timer.C
+		}
+
+		select {
+		case <-c.ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-c.sessionExtended:
+		case <-timerC:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (c *Client) expire() {
+	c.log.WithFields(c.ctx, logger.Fields{
+		"user_id": c.userID,
+		"action":  "ws_token_expired",
+	}).Info("websocket session closed: access token expired")
+	metrics.ChatWebSocketDisconnections.WithLabelValues("token_expired").Inc()
+	c.Disconnect(constants.WebSocketCloseTokenExpired, "token expired")
+}
+
+func (c *Client) Disconnect(closeCode int, reason string) {
+	c.closeFrame.CompareAndSwap(nil, gorillaWS.FormatCloseMessage(closeCode, reason))
+	c.cancel()
+}
+
+func (c *Client) closeMessage() []byte {
+	if frame, ok := c.closeFrame.Load().([]byte); ok {
+		return frame
+	}
+	return []byte{}
+}
+
+func (c *Client) queueDirect(msgType MessageType, payload interface{}) {
+	msg, err := marshalMessage(msgType, payload)
+	if err != nil {
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": c.userID,
+			"type":    string(msgType),
+			"action":  "ws_direct_marshal",
+		}).Errorf("websocket failed to marshal message: %v", err)
+		return
+	}
+	frame, err := c.encode(msg)
+	if err != nil {
+		return
+	}
+	if c.closed.Load() {
+		return
+	}
+	select {
+	case c.send <- frame:
+	default:
+		c.log.WithFields(c.ctx, logger.Fields{
+			"user_id": c.userID,
+			"type":    string(msgType),
+			"action":  "ws_direct_buffer_full",
+		}).Warn("websocket direct message send buffer full")
+	}
+}
+
+func (c *Client) queueError(err commonerrors.DomainError) {
+	c.queueDirect(TypeError, ErrorPayload{Code: err.Code(), Message: err.Message()})
+}
+
+func formatExpiry(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return ""
+	}
+	return expiresAt.UTC().Format(time.RFC3339)
+}
+
+type RevocationListenFunc func(ctx context.Context, onRevoked func(jti, userID string)) error
+
+func (h *Hub) WatchRevocations(listen RevocationListenFunc) {
+	for {
+		err := listen(h.ctx, h.DisconnectRevoked)
+		select {
+		case <-h.ctx.Done():
+			return
+		default:
+		}
+		h.log.WithFields(h.ctx, logger.Fields{
+			"action": "ws_revocation_listener_failed",
+		}).Warnf("websocket revocation listener stopped, retrying: %v", err)
+
+		timer := time.NewTimer(constants.WebSocketRevocationRetryDelay)
+		select {
+		case <-h.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (h *Hub) DisconnectRevoked(jti, userID string) {
+	value, ok := h.clients.Load(userID)
+	if !ok {
+		return
+	}
+	client := value.(*Client)
+	if jti != "" && client.TokenJTI() != jti {
+		return
+	}
+
+	h.log.WithFields(client.ctx, logger.Fields{
+		"user_id": userID,
+		"jti":     jti,
+		"action":  "ws_token_revoked_disconnect",
+	}).Info("websocket session closed: access token revoked")
+	metrics.ChatWebSocketDisconnections.WithLabelValues("token_revoked").Inc()
+	client.Disconnect(constants.WebSocketCloseTokenRevoked, "token revoked")
+}
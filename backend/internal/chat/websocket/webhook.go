@@ -0,0 +1,40 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, ownerID, event string, payload []byte, expiresAt time.Time) bool
+}
+
+func (r *messageRouter) dispatchWebhook(ctx context.Context, msg *WSMessage, userID string) bool {
+	if r.webhooks == nil {
+		return false
+	}
+
+	switch msg.Type {
+	case TypeMessage, TypeReaction, TypeFileComplete:
+	default:
+		return false
+	}
+	payload, err := msg.PayloadJSON()
+	if err != nil {
+		return false
+	}
+	if !r.webhooks.Dispatch(ctx, userID, string(msg.Type), payload, msg.ExpiresAt) {
+		return false
+	}
+
+	if r.log.ShouldLog(logger.DEBUG) && r.log.ShouldSample(r.debugSampleRate) {
+		r.log.WithFields(ctx, logger.Fields{
+			"to":     userID,
+			"type":   string(msg.Type),
+			"action": "ws_message_webhook_dispatched",
+		}).Debug("websocket message dispatched to webhook")
+	}
+	return true
+}
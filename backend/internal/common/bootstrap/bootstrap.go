@@ -1,23 +1,32 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 
+	auditexporter "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/exporter"
+	auditrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/repository"
+	auditservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/service"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	identityrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/repository"
 	identityservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/health"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
 )
 
 type App struct {
 	Log             *logger.Logger
 	Pool            *pgxpool.Pool
+	AuditService    auditservice.Service
+	ShutdownTracing tracing.ShutdownFunc
+	Health          *health.Registry
 	UserRepo        userrepo.Repository
 	IdentityRepo    identityrepo.Repository
 	IdentityService identityservice.Service
@@ -45,7 +54,7 @@ func NewAuthApp() (*AuthApp, error) {
 		return nil, err
 	}
 
-	app, err := initializeApp(log, cfg.DatabaseURL)
+	app, err := initializeApp(log, "auth", cfg.BaseConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +77,7 @@ func NewChatApp() (*ChatApp, error) {
 		return nil, err
 	}
 
-	app, err := initializeApp(log, cfg.DatabaseURL)
+	app, err := initializeApp(log, "chat", cfg.BaseConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -79,8 +88,19 @@ func NewChatApp() (*ChatApp, error) {
 	}, nil
 }
 
-func initializeApp(log *logger.Logger, databaseURL string) (*App, error) {
-	pool := db.NewPool(log, databaseURL)
+func initializeApp(log *logger.Logger, serviceName string, cfg config.BaseConfig) (*App, error) {
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.TracingEnabled,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+		ServiceName: serviceName,
+	}, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+
+	pool := db.NewPool(log, cfg.DatabaseURL)
 	if pool == nil {
 		return nil, fmt.Errorf("failed to initialize database pool")
 	}
@@ -89,14 +109,29 @@ func initializeApp(log *logger.Logger, databaseURL string) (*App, error) {
 
 	userRepo := userrepo.NewPgRepository(pool)
 	identityRepo := identityrepo.NewPgRepository(pool)
-	identityService := identityservice.NewIdentityService(identityservice.IdentityServiceDeps{
-		Repo: identityRepo,
+	auditDeps := auditservice.AuditServiceDeps{
+		Repo: auditrepo.NewPgRepository(pool),
 		Log:  log,
+	}
+	if cfg.AuditExportPath != "" {
+		auditDeps.Exporter = auditexporter.NewJSONLinesExporter(cfg.AuditExportPath, serviceName)
+	}
+	auditService := auditservice.NewAuditService(auditDeps)
+	identityService := identityservice.NewIdentityService(identityservice.IdentityServiceDeps{
+		Repo:  identityRepo,
+		Audit: auditService,
+		Log:   log,
 	})
 
+	healthRegistry := health.NewRegistry(health.RegistryDeps{Log: log}, health.RegistryConfig{})
+	healthRegistry.AddReadiness(health.PoolChecker(pool))
+
 	return &App{
 		Log:             log,
 		Pool:            pool,
+		AuditService:    auditService,
+		ShutdownTracing: shutdownTracing,
+		Health:          healthRegistry,
 		UserRepo:        userRepo,
 		IdentityRepo:    identityRepo,
 		IdentityService: identityService,
@@ -104,5 +139,15 @@ func initializeApp(log *logger.Logger, databaseURL string) (*App, error) {
 }
 
 func initializeLogger(serviceName string) (*logger.Logger, error) {
-	return logger.New(os.Getenv("LOG_DIR"), serviceName, os.Getenv("LOG_LEVEL"))
+	return logger.NewWithConfig(logger.Config{
+		Dir:             os.Getenv("LOG_DIR"),
+		ServiceName:     serviceName,
+		Level:           os.Getenv("LOG_LEVEL"),
+		ComponentLevels: os.Getenv("LOG_COMPONENT_LEVELS"),
+		LevelFile:       os.Getenv("LOG_LEVEL_FILE"),
+		Sinks:           os.Getenv("LOG_OUTPUT"),
+		SyslogAddress:   os.Getenv("LOG_SYSLOG_ADDR"),
+		RedactionRules:  os.Getenv("LOG_REDACTION_RULES"),
+		RedactionSalt:   os.Getenv("LOG_REDACTION_SALT"),
+	})
 }
@@ -18,6 +18,15 @@ type BaseConfig struct {
 	CircuitBreakerThreshold int32         `validate:"gt=0"`
 	CircuitBreakerTimeout   time.Duration `validate:"gt=0"`
 	CircuitBreakerReset     time.Duration `validate:"gt=0"`
+	CircuitBreakerWindow    time.Duration `validate:"gt=0"`
+	CircuitBreakerRate      float64       `validate:"gt=0,lte=1"`
+	CircuitBreakerHalfOpen  int32         `validate:"gt=0"`
+	AuditExportPath         string
+	TracingEnabled          bool
+	TracingEndpoint         string `validate:"required_if=TracingEnabled true"`
+	TracingInsecure         bool
+	TracingSampleRatio      float64 `validate:"gte=0,lte=1"`
+	ConfigFile              string
 }
 
 type AuthConfig struct {
@@ -26,27 +35,38 @@ type AuthConfig struct {
 	AccessTokenTTL          time.Duration `validate:"gt=0"`
 	RefreshTokenTTL         time.Duration `validate:"gt=0"`
 	MaxRefreshTokensPerUser int           `validate:"gt=0"`
+	ScopedTokenMaxTTL       time.Duration `validate:"gt=0"`
+	BotTokenMaxTTL          time.Duration `validate:"gtefield=ScopedTokenMaxTTL"`
 }
 
 type ChatConfig struct {
 	BaseConfig
-	WebSocketWriteWait      time.Duration `validate:"gt=0"`
-	WebSocketPongWait       time.Duration `validate:"gt=0"`
-	WebSocketPingPeriod     time.Duration `validate:"gt=0"`
-	WebSocketMaxMsgSize     int64         `validate:"gt=0"`
-	WebSocketSendBufSize    int           `validate:"gt=0"`
-	WebSocketAuthTimeout    time.Duration `validate:"gt=0"`
-	WebSocketSendTimeout    time.Duration `validate:"gt=0"`
-	LastSeenUpdateInterval  time.Duration `validate:"gt=0"`
-	RequestTimeout          time.Duration `validate:"gt=0"`
-	SearchTimeout           time.Duration `validate:"gt=0"`
-	WebSocketMaxConnections int           `validate:"gt=0"`
+	WebSocketWriteWait          time.Duration `validate:"gt=0" reload:"live"`
+	WebSocketPongWait           time.Duration `validate:"gt=0" reload:"live"`
+	WebSocketPingPeriod         time.Duration `validate:"gt=0,ltfield=WebSocketPongWait" reload:"live"`
+	WebSocketMaxMsgSize         int64         `validate:"gt=0"`
+	WebSocketSendBufSize        int           `validate:"gt=0"`
+	WebSocketAuthTimeout        time.Duration `validate:"gt=0" reload:"live"`
+	WebSocketSendTimeout        time.Duration `validate:"gt=0" reload:"live"`
+	LastSeenUpdateInterval      time.Duration `validate:"gt=0"`
+	RequestTimeout              time.Duration `validate:"gt=0"`
+	SearchTimeout               time.Duration `validate:"gt=0"`
+	WebSocketMaxConnections     int           `validate:"gt=0" reload:"live"`
+	WebSocketProcessorWorkers   int           `validate:"gt=0,lte=1024" reload:"live"`
+	WebSocketProcessorQueueSize int           `validate:"gt=0"`
+	SearchMaxConcurrent         int           `validate:"gt=0,lte=1024" reload:"live"`
+	PushWebhookURL              string        `validate:"omitempty,url"`
+	PushVAPIDPrivateKey         string
+	PushVAPIDSubject            string        `validate:"required_with=PushVAPIDPrivateKey"`
+	PushTimeout                 time.Duration `validate:"gt=0"`
+	WebhookTimeout              time.Duration `validate:"gt=0"`
+	MessageEditWindow           time.Duration `validate:"gt=0" reload:"live"`
 }
 
 var validate = validator.New()
 
-func loadBaseConfig(prefix string, defaultPort string) (BaseConfig, error) {
-	jwtSecret, err := mustEnv("JWT_SECRET")
+func loadBaseConfig(src source, prefix string, defaultPort string) (BaseConfig, error) {
+	jwtSecret, err := src.mustEnv("JWT_SECRET")
 	if err != nil {
 		return BaseConfig{}, err
 	}
@@ -55,7 +75,7 @@ func loadBaseConfig(prefix string, defaultPort string) (BaseConfig, error) {
 		return BaseConfig{}, err
 	}
 
-	databaseURL, err := mustEnv("DATABASE_URL")
+	databaseURL, err := src.mustEnv("DATABASE_URL")
 	if err != nil {
 		return BaseConfig{}, err
 	}
@@ -63,25 +83,41 @@ func loadBaseConfig(prefix string, defaultPort string) (BaseConfig, error) {
 	return BaseConfig{
 		DatabaseURL:             databaseURL,
 		JWTSecret:               jwtSecret,
-		HTTPPort:                getEnv(prefix+"_HTTP_PORT", defaultPort),
-		CircuitBreakerThreshold: int32(getIntEnv(prefix+"_CIRCUIT_BREAKER_THRESHOLD", constants.DefaultCircuitBreakerThreshold)),
-		CircuitBreakerTimeout:   getDurationEnv(prefix+"_CIRCUIT_BREAKER_TIMEOUT", constants.DefaultCircuitBreakerTimeout),
-		CircuitBreakerReset:     getDurationEnv(prefix+"_CIRCUIT_BREAKER_RESET", constants.DefaultCircuitBreakerReset),
+		HTTPPort:                src.getEnv(prefix+"_HTTP_PORT", defaultPort),
+		CircuitBreakerThreshold: int32(src.getIntEnv(prefix+"_CIRCUIT_BREAKER_THRESHOLD", constants.DefaultCircuitBreakerThreshold)),
+		CircuitBreakerTimeout:   src.getDurationEnv(prefix+"_CIRCUIT_BREAKER_TIMEOUT", constants.DefaultCircuitBreakerTimeout),
+		CircuitBreakerReset:     src.getDurationEnv(prefix+"_CIRCUIT_BREAKER_RESET", constants.DefaultCircuitBreakerReset),
+		CircuitBreakerWindow:    src.getDurationEnv(prefix+"_CIRCUIT_BREAKER_WINDOW", constants.DefaultCircuitBreakerWindow),
+		CircuitBreakerRate:      src.getFloatEnv(prefix+"_CIRCUIT_BREAKER_FAILURE_RATE", constants.DefaultCircuitBreakerFailureRate),
+		CircuitBreakerHalfOpen:  int32(src.getIntEnv(prefix+"_CIRCUIT_BREAKER_HALF_OPEN_CALLS", constants.DefaultCircuitBreakerHalfOpenCalls)),
+		AuditExportPath:         src.getEnv(prefix+"_AUDIT_EXPORT_PATH", ""),
+		TracingEnabled:          src.getBoolEnv("TRACING_ENABLED", false),
+		TracingEndpoint:         src.getEnv("TRACING_OTLP_ENDPOINT", constants.DefaultTracingEndpoint),
+		TracingInsecure:         src.getBoolEnv("TRACING_INSECURE", true),
+		TracingSampleRatio:      src.getFloatEnv("TRACING_SAMPLE_RATIO", constants.DefaultTracingSampleRatio),
+		ConfigFile:              src.path,
 	}, nil
 }
 
 func LoadAuthConfig() (AuthConfig, error) {
-	base, err := loadBaseConfig("AUTH", constants.DefaultAuthHTTPPort)
+	src, err := newSource(os.Getenv("AUTH_CONFIG_FILE"))
+	if err != nil {
+		return AuthConfig{}, err
+	}
+
+	base, err := loadBaseConfig(src, "AUTH", constants.DefaultAuthHTTPPort)
 	if err != nil {
 		return AuthConfig{}, err
 	}
 
 	cfg := AuthConfig{
 		BaseConfig:              base,
-		RequestTimeout:          getDurationEnv("AUTH_REQUEST_TIMEOUT", constants.DefaultAuthRequestTimeout),
-		AccessTokenTTL:          getDurationEnv("AUTH_ACCESS_TOKEN_TTL", constants.DefaultAccessTokenTTL),
-		RefreshTokenTTL:         getDurationEnv("AUTH_REFRESH_TOKEN_TTL", constants.DefaultRefreshTokenTTL),
-		MaxRefreshTokensPerUser: getIntEnv("AUTH_MAX_REFRESH_TOKENS_PER_USER", constants.DefaultMaxRefreshTokensPerUser),
+		RequestTimeout:          src.getDurationEnv("AUTH_REQUEST_TIMEOUT", constants.DefaultAuthRequestTimeout),
+		AccessTokenTTL:          src.getDurationEnv("AUTH_ACCESS_TOKEN_TTL", constants.DefaultAccessTokenTTL),
+		RefreshTokenTTL:         src.getDurationEnv("AUTH_REFRESH_TOKEN_TTL", constants.DefaultRefreshTokenTTL),
+		MaxRefreshTokensPerUser: src.getIntEnv("AUTH_MAX_REFRESH_TOKENS_PER_USER", constants.DefaultMaxRefreshTokensPerUser),
+		ScopedTokenMaxTTL:       src.getDurationEnv("AUTH_SCOPED_TOKEN_MAX_TTL", constants.DefaultScopedTokenMaxTTL),
+		BotTokenMaxTTL:          src.getDurationEnv("AUTH_BOT_TOKEN_MAX_TTL", constants.DefaultBotTokenMaxTTL),
 	}
 
 	if err := validate.Struct(cfg); err != nil {
@@ -92,24 +128,42 @@ func LoadAuthConfig() (AuthConfig, error) {
 }
 
 func LoadChatConfig() (ChatConfig, error) {
-	base, err := loadBaseConfig("CHAT", constants.DefaultChatHTTPPort)
+	return LoadChatConfigFile(os.Getenv("CHAT_CONFIG_FILE"))
+}
+
+func LoadChatConfigFile(path string) (ChatConfig, error) {
+	src, err := newSource(path)
+	if err != nil {
+		return ChatConfig{}, err
+	}
+
+	base, err := loadBaseConfig(src, "CHAT", constants.DefaultChatHTTPPort)
 	if err != nil {
 		return ChatConfig{}, err
 	}
 
 	cfg := ChatConfig{
-		BaseConfig:              base,
-		WebSocketWriteWait:      getDurationEnv("CHAT_WS_WRITE_WAIT", constants.DefaultWebSocketWriteWait),
-		WebSocketPongWait:       getDurationEnv("CHAT_WS_PONG_WAIT", constants.DefaultWebSocketPongWait),
-		WebSocketPingPeriod:     getDurationEnv("CHAT_WS_PING_PERIOD", constants.DefaultWebSocketPingPeriod),
-		WebSocketMaxMsgSize:     getInt64Env("CHAT_WS_MAX_MSG_SIZE", constants.DefaultWebSocketMaxMsgSize),
-		WebSocketSendBufSize:    getIntEnv("CHAT_WS_SEND_BUF_SIZE", constants.DefaultWebSocketSendBufSize),
-		WebSocketAuthTimeout:    getDurationEnv("CHAT_WS_AUTH_TIMEOUT", constants.DefaultWebSocketAuthTimeout),
-		WebSocketSendTimeout:    getDurationEnv("CHAT_WS_SEND_TIMEOUT", constants.DefaultWebSocketSendTimeout),
-		LastSeenUpdateInterval:  getDurationEnv("CHAT_LAST_SEEN_INTERVAL", constants.DefaultLastSeenUpdateInterval),
-		RequestTimeout:          getDurationEnv("CHAT_REQUEST_TIMEOUT", constants.DefaultChatRequestTimeout),
-		SearchTimeout:           getDurationEnv("CHAT_SEARCH_TIMEOUT", constants.DefaultSearchTimeout),
-		WebSocketMaxConnections: getIntEnv("CHAT_WS_MAX_CONNECTIONS", constants.DefaultWebSocketMaxConnections),
+		BaseConfig:                  base,
+		WebSocketWriteWait:          src.getDurationEnv("CHAT_WS_WRITE_WAIT", constants.DefaultWebSocketWriteWait),
+		WebSocketPongWait:           src.getDurationEnv("CHAT_WS_PONG_WAIT", constants.DefaultWebSocketPongWait),
+		WebSocketPingPeriod:         src.getDurationEnv("CHAT_WS_PING_PERIOD", constants.DefaultWebSocketPingPeriod),
+		WebSocketMaxMsgSize:         src.getInt64Env("CHAT_WS_MAX_MSG_SIZE", constants.DefaultWebSocketMaxMsgSize),
+		WebSocketSendBufSize:        src.getIntEnv("CHAT_WS_SEND_BUF_SIZE", constants.DefaultWebSocketSendBufSize),
+		WebSocketAuthTimeout:        src.getDurationEnv("CHAT_WS_AUTH_TIMEOUT", constants.DefaultWebSocketAuthTimeout),
+		WebSocketSendTimeout:        src.getDurationEnv("CHAT_WS_SEND_TIMEOUT", constants.DefaultWebSocketSendTimeout),
+		LastSeenUpdateInterval:      src.getDurationEnv("CHAT_LAST_SEEN_INTERVAL", constants.DefaultLastSeenUpdateInterval),
+		RequestTimeout:              src.getDurationEnv("CHAT_REQUEST_TIMEOUT", constants.DefaultChatRequestTimeout),
+		SearchTimeout:               src.getDurationEnv("CHAT_SEARCH_TIMEOUT", constants.DefaultSearchTimeout),
+		WebSocketMaxConnections:     src.getIntEnv("CHAT_WS_MAX_CONNECTIONS", constants.DefaultWebSocketMaxConnections),
+		WebSocketProcessorWorkers:   src.getIntEnv("CHAT_WS_PROCESSOR_WORKERS", constants.WebSocketProcessorWorkers),
+		WebSocketProcessorQueueSize: src.getIntEnv("CHAT_WS_PROCESSOR_QUEUE_SIZE", constants.WebSocketProcessorQueueSize),
+		SearchMaxConcurrent:         src.getIntEnv("CHAT_SEARCH_MAX_CONCURRENT", constants.SearchBulkheadConcurrency),
+		PushWebhookURL:              src.getEnv("CHAT_PUSH_WEBHOOK_URL", ""),
+		PushVAPIDPrivateKey:         src.getEnv("CHAT_PUSH_VAPID_PRIVATE_KEY", ""),
+		PushVAPIDSubject:            src.getEnv("CHAT_PUSH_VAPID_SUBJECT", ""),
+		PushTimeout:                 src.getDurationEnv("CHAT_PUSH_TIMEOUT", constants.DefaultPushTimeout),
+		WebhookTimeout:              src.getDurationEnv("CHAT_WEBHOOK_TIMEOUT", constants.DefaultWebhookTimeout),
+		MessageEditWindow:           src.getDurationEnv("CHAT_MESSAGE_EDIT_WINDOW", constants.DefaultMessageEditWindow),
 	}
 
 	if err := validate.Struct(cfg); err != nil {
@@ -126,23 +180,23 @@ func validateJWTSecret(secret string) error {
 	return nil
 }
 
-func getEnv(key, fallback string) string {
-	if v, ok := os.LookupEnv(key); ok {
+func (s source) getEnv(key, fallback string) string {
+	if v, ok := s.lookup(key); ok {
 		return v
 	}
 	return fallback
 }
 
-func mustEnv(key string) (string, error) {
-	v, ok := os.LookupEnv(key)
+func (s source) mustEnv(key string) (string, error) {
+	v, ok := s.lookup(key)
 	if !ok || v == "" {
 		return "", commonerrors.ErrMissingRequiredEnv
 	}
 	return v, nil
 }
 
-func getDurationEnv(key string, fallback time.Duration) time.Duration {
-	v, ok := os.LookupEnv(key)
+func (s source) getDurationEnv(key string, fallback time.Duration) time.Duration {
+	v, ok := s.lookup(key)
 	if !ok || v == "" {
 		return fallback
 	}
@@ -153,8 +207,8 @@ func getDurationEnv(key string, fallback time.Duration) time.Duration {
 	return d
 }
 
-func getIntEnv(key string, fallback int) int {
-	v, ok := os.LookupEnv(key)
+func (s source) getIntEnv(key string, fallback int) int {
+	v, ok := s.lookup(key)
 	if !ok || v == "" {
 		return fallback
 	}
@@ -165,8 +219,8 @@ func getIntEnv(key string, fallback int) int {
 	return i
 }
 
-func getInt64Env(key string, fallback int64) int64 {
-	v, ok := os.LookupEnv(key)
+func (s source) getInt64Env(key string, fallback int64) int64 {
+	v, ok := s.lookup(key)
 	if !ok || v == "" {
 		return fallback
 	}
@@ -176,3 +230,27 @@ func getInt64Env(key string, fallback int64) int64 {
 	}
 	return i
 }
+
+func (s source) getBoolEnv(key string, fallback bool) bool {
+	v, ok := s.lookup(key)
+	if !ok || v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+func (s source) getFloatEnv(key string, fallback float64) float64 {
+	v, ok := s.lookup(key)
+	if !ok || v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+)
+
+type source struct {
+	path   string
+	values map[string]string
+}
+
+func newSource(path string) (source, error) {
+	if path == "" {
+		return source{}, nil
+	}
+	values, err := readConfigFile(path)
+	if err != nil {
+		return source{}, err
+	}
+	return source{path: path, values: values}, nil
+}
+
+func (s source) lookup(key string) (string, bool) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func readConfigFile(path string) (map[string]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return nil, commonerrors.ErrInvalidConfigFile.WithCause(fmt.Errorf("unsupported config file format %q", filepath.Ext(path)))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, commonerrors.ErrInvalidConfigFile.WithCause(err)
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, commonerrors.ErrInvalidConfigFile.WithCause(err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenConfig("", document, values); err != nil {
+		return nil, commonerrors.ErrInvalidConfigFile.WithCause(err)
+	}
+	return values, nil
+}
+
+func flattenConfig(prefix string, node map[string]interface{}, values map[string]string) error {
+	for key, value := range node {
+		name := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), "-", "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch typed := value.(type) {
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{}, len(typed))
+			for k, v := range typed {
+				nested[fmt.Sprint(k)] = v
+			}
+			if err := flattenConfig(name, nested, values); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("config key %s: lists are not supported", name)
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(typed)
+		}
+	}
+	return nil
+}
+
+func RestartRequiredChanges(current, next interface{}) []string {
+	return diffFields(reflect.ValueOf(current), reflect.ValueOf(next), nil)
+}
+
+func diffFields(current, next reflect.Value, changed []string) []string {
+	if current.Kind() == reflect.Pointer {
+		current, next = current.Elem(), next.Elem()
+	}
+	structType := current.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			changed = diffFields(current.Field(i), next.Field(i), changed)
+			continue
+		}
+		if field.Tag.Get("reload") == "live" {
+			continue
+		}
+		if !reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			changed = append(changed, field.Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type ChatConfigSubscriber func(ChatConfig)
+
+type ChatConfigWatcher struct {
+	mu          sync.Mutex
+	reloadMu    sync.Mutex
+	path        string
+	current     ChatConfig
+	modTime     time.Time
+	subscribers []ChatConfigSubscriber
+	interval    time.Duration
+	log         *logger.Logger
+}
+
+func NewChatConfigWatcher(current ChatConfig, log *logger.Logger) *ChatConfigWatcher {
+	w := &ChatConfigWatcher{
+		path:     current.ConfigFile,
+		current:  current,
+		interval: constants.ConfigWatchInterval,
+		log:      log,
+	}
+	w.modTime = w.fileModTime()
+	return w
+}
+
+func (w *ChatConfigWatcher) Current() ChatConfig {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+func (w *ChatConfigWatcher) Subscribe(fn ChatConfigSubscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+func (w *ChatConfigWatcher) Reload() (ChatConfig, error) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	current := w.Current()
+	next, err := LoadChatConfigFile(w.path)
+	if err != nil {
+		metrics.ConfigReloadsTotal.WithLabelValues("invalid").Inc()
+		return current, err
+	}
+
+	if changed := RestartRequiredChanges(current, next); len(changed) > 0 {
+		metrics.ConfigReloadsTotal.WithLabelValues("rejected").Inc()
+		return current, commonerrors.ErrConfigReloadRejected.WithCause(
+			fmt.Errorf("fields require restart: %s", strings.Join(changed, ", ")),
+		)
+	}
+
+	if reflect.DeepEqual(current, next) {
+		metrics.ConfigReloadsTotal.WithLabelValues("unchanged").Inc()
+		return current, nil
+	}
+
+	w.mu.Lock()
+	w.current = next
+	subscribers := append([]ChatConfigSubscriber(nil), w.subscribers...)
+	w.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(next)
+	}
+	metrics.ConfigReloadsTotal.WithLabelValues("applied").Inc()
+	return next, nil
+}
+
+func (w *ChatConfigWatcher) Watch(ctx context.Context) {
+	if w.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime := w.fileModTime()
+			if modTime.IsZero() || modTime.Equal(w.modTime) {
+				continue
+			}
+			w.modTime = modTime
+
+			if _, err := w.Reload(); err != nil {
+				w.log.WithFields(ctx, logger.Fields{
+					"path":   w.path,
+					"action": "config_reload_failed",
+				}).Warnf("config reload failed, keeping current config: %v", err)
+				continue
+			}
+			w.log.WithFields(ctx, logger.Fields{
+				"path":   w.path,
+				"action": "config_reloaded",
+			}).Info("config reloaded from file")
+		}
+	}
+}
+
+func (w *ChatConfigWatcher) fileModTime() time.Time {
+	if w.path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -22,6 +22,10 @@ const (
 	UserExistenceCacheTTL             = 5 * time.Minute
 	UserExistenceCacheCleanupInterval = 1 * time.Minute
 
+	MaxPresenceSubscriptions = 500
+	PresenceEventQueueSize   = 1000
+	PresenceNotifyTimeout    = 5 * time.Second
+
 	RefreshTokenCacheTTL             = 1 * time.Minute
 	RefreshTokenCacheCleanupInterval = 30 * time.Second
 
@@ -32,6 +36,9 @@ const (
 	WebSocketProcessorQueueSize          = 10000
 	WebSocketProcessorDefaultQueueSize   = 1000
 	WebSocketProcessorTimeout            = 30 * time.Second
+	WebSocketProcessorClientQueueSize    = 256
+	WebSocketBackpressureRetryAfter      = 500 * time.Millisecond
+	WebSocketSequenceIdleTTL             = time.Hour
 	WebSocketDebugSampleRate             = 0.01
 	WebSocketShutdownNotificationTimeout = 5 * time.Second
 	WebSocketClientShutdownTimeout       = 2 * time.Second
@@ -43,6 +50,59 @@ const (
 	LastSeenFlushEvery    = 500 * time.Millisecond
 	LastSeenUpdateTimeout = 3 * time.Second
 
+	ReceiptQueueSize         = 1000
+	ReceiptBatchSize         = 200
+	ReceiptFlushEvery        = 500 * time.Millisecond
+	ReceiptWriteTimeout      = 3 * time.Second
+	DefaultReceiptListLimit  = 100
+	MaxReceiptListLimit      = 500
+	DefaultMessageEditWindow = 48 * time.Hour
+
+	MaxReactionsPerMessage = 3
+	MaxReactionEmojiBytes  = 64
+	MaxReactionMessageIDs  = 100
+
+	ConversationQueueSize        = 1000
+	ConversationBatchSize        = 200
+	ConversationFlushEvery       = 500 * time.Millisecond
+	ConversationWriteTimeout     = 3 * time.Second
+	DefaultConversationListLimit = 50
+	MaxConversationListLimit     = 200
+
+	MinDisappearingTTL        = 5 * time.Second
+	MaxDisappearingTTL        = 28 * 24 * time.Hour
+	DisappearingTimerCacheTTL = 1 * time.Minute
+	DisappearingReapInterval  = 30 * time.Second
+
+	PushQueueSize         = 1000
+	PushWorkers           = 4
+	PushThrottleInterval  = 30 * time.Second
+	PushMessageTTL        = 1 * time.Hour
+	PushVAPIDTokenTTL     = 12 * time.Hour
+	MaxPushDevicesPerUser = 10
+	MaxPushTokenLength    = 2048
+	DefaultPushTimeout    = 5 * time.Second
+	PushResponseBodyLimit = 4 * 1024
+
+	WebhookQueueSize                 = 1000
+	WebhookWorkers                   = 4
+	WebhookMaxAttempts               = 5
+	WebhookRetryBaseDelay            = 2 * time.Second
+	WebhookRetryMaxDelay             = 1 * time.Minute
+	WebhookCacheTTL                  = 1 * time.Minute
+	WebhookSecretBytes               = 32
+	WebhookResponseBodyLimit         = 4 * 1024
+	WebhookStopTimeout               = 5 * time.Second
+	WebhookDeadLetterCleanupInterval = 30 * time.Second
+	MaxWebhooksPerUser               = 5
+	MaxWebhookURLLength              = 2048
+	DefaultWebhookTimeout            = 10 * time.Second
+	DefaultWebhookDeadLetterLimit    = 50
+	MaxWebhookDeadLetterLimit        = 200
+
+	PrivacyCacheTTL             = 1 * time.Minute
+	PrivacyCacheCleanupInterval = 1 * time.Minute
+
 	DBPoolMaxOpenConns    = 50
 	DBPoolMinOpenConns    = 10
 	DBPoolConnMaxLifetime = 5 * time.Minute
@@ -61,7 +121,23 @@ const (
 	ServerIdleTimeout       = 120 * time.Second
 
 	ShutdownTimeout = 30 * time.Second
-	DrainTimeout    = 10 * time.Second
+	DrainTimeout    = 20 * time.Second
+
+	HealthCheckTimeout                    = 2 * time.Second
+	ReadinessDrainDelay                   = 3 * time.Second
+	WebSocketHubHeartbeatInterval         = 5 * time.Second
+	WebSocketHubHeartbeatMaxAge           = 15 * time.Second
+	WebSocketProcessorSaturationThreshold = 0.9
+	WebSocketDrainTimeout                 = 5 * time.Second
+	WebSocketDrainPollInterval            = 50 * time.Millisecond
+	WebSocketDrainFlushReserveDivisor     = 4
+	WebSocketReconnectHintMin             = 1 * time.Second
+	WebSocketReconnectHintJitter          = 4 * time.Second
+	WebSocketAuthExpiryWarning            = 2 * time.Minute
+	WebSocketRevocationRetryDelay         = 5 * time.Second
+	WebSocketCloseTokenExpired            = 4001
+	WebSocketCloseTokenRevoked            = 4003
+	TokenRevokedChannel                   = "token_revoked"
 
 	IdentityRequestTimeout = 5 * time.Second
 
@@ -73,10 +149,44 @@ const (
 	DefaultCircuitBreakerReset     = 10 * time.Second
 	CircuitBreakerDatabaseName     = "database"
 
+	DefaultCircuitBreakerWindow        = 60 * time.Second
+	DefaultCircuitBreakerFailureRate   = 0.5
+	DefaultCircuitBreakerMinCalls      = 20
+	DefaultCircuitBreakerHalfOpenCalls = 3
+	CircuitBreakerWindowBuckets        = 10
+
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 50 * time.Millisecond
+	DefaultRetryMaxBackoff     = time.Second
+	DefaultRetryMultiplier     = 2.0
+	DefaultRetryJitter         = 0.2
+
+	DefaultBulkheadMaxConcurrent  = 16
+	DefaultBulkheadMinConcurrent  = 2
+	DefaultBulkheadQueueTimeout   = 500 * time.Millisecond
+	DefaultBulkheadTargetLatency  = 100 * time.Millisecond
+	DefaultBulkheadDecreaseFactor = 0.9
+
+	AuthWriteBulkheadName          = "auth_write"
+	AuthWriteBulkheadConcurrency   = 16
+	AuthWriteBulkheadMaxLimit      = 32
+	AuthWriteBulkheadQueue         = 64
+	AuthWriteBulkheadTargetLatency = 150 * time.Millisecond
+	SearchBulkheadName             = "search"
+	SearchBulkheadConcurrency      = 4
+	SearchBulkheadMaxLimit         = 8
+	SearchBulkheadQueue            = 16
+	SearchBulkheadTargetLatency    = 250 * time.Millisecond
+	PresenceBulkheadName           = "presence_batch"
+	PresenceBulkheadConcurrency    = 2
+	PresenceBulkheadQueue          = 8
+
 	DefaultAuthRequestTimeout      = 30 * time.Second
 	DefaultAccessTokenTTL          = 30 * time.Minute
 	DefaultRefreshTokenTTL         = 7 * 24 * time.Hour
 	DefaultMaxRefreshTokensPerUser = 5
+	DefaultScopedTokenMaxTTL       = 30 * 24 * time.Hour
+	DefaultBotTokenMaxTTL          = 365 * 24 * time.Hour
 
 	DefaultWebSocketWriteWait      = 10 * time.Second
 	DefaultWebSocketPongWait       = 60 * time.Second
@@ -92,6 +202,8 @@ const (
 
 	DefaultSearchUsersLimit = 20
 
+	ConfigWatchInterval = 5 * time.Second
+
 	WebSocketReadBufferSize  = 1024
 	WebSocketWriteBufferSize = 1024
 
@@ -99,6 +211,17 @@ const (
 	LoggerMaxBackups = 3
 	LoggerMaxAge     = 28
 
+	TracerName                = "github.com/AlibekovAA/dh-secure-chat/backend"
+	TraceParentHeader         = "traceparent"
+	DefaultTracingEndpoint    = "localhost:4318"
+	DefaultTracingSampleRatio = 1.0
+
+	AuditExportMaxSize    = 100
+	AuditExportMaxBackups = 10
+	AuditExportMaxAge     = 90
+	DefaultAuditListLimit = 50
+	MaxAuditListLimit     = 200
+
 	TestJWTSecret               = "test-secret-key-must-be-at-least-32-bytes-long"
 	TestAccessTokenTTL          = 15 * time.Minute
 	TestCircuitBreakerThreshold = 5
@@ -116,3 +239,10 @@ const (
 type TraceIDKeyType string
 
 const TraceIDKey TraceIDKeyType = "trace_id"
+
+type RequestMetaKeyType string
+
+const (
+	ClientIPKey  RequestMetaKeyType = "client_ip"
+	UserAgentKey RequestMetaKeyType = "user_agent"
+)
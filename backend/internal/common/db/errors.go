@@ -1,11 +1,16 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/jackc/pgconn"
 	pgx "github.com/jackc/pgx/v4"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
@@ -14,6 +19,9 @@ import (
 func extractTableFromOperation(operation string) string {
 	operation = strings.ToLower(operation)
 
+	if strings.Contains(operation, "audit") {
+		return "audit_events"
+	}
 	if strings.Contains(operation, "revoked") {
 		return "revoked_tokens"
 	}
@@ -23,6 +31,33 @@ func extractTableFromOperation(operation string) string {
 	if strings.Contains(operation, "identity") || strings.Contains(operation, "key") {
 		return "identity_keys"
 	}
+	if strings.Contains(operation, "role") {
+		return "user_roles"
+	}
+	if strings.Contains(operation, "receipt") {
+		return "message_receipts"
+	}
+	if strings.Contains(operation, "reaction") {
+		return "message_reactions"
+	}
+	if strings.Contains(operation, "disappearing") {
+		return "disappearing_timers"
+	}
+	if strings.Contains(operation, "conversation") {
+		return "conversations"
+	}
+	if strings.Contains(operation, "dead letter") {
+		return "webhook_dead_letters"
+	}
+	if strings.Contains(operation, "webhook") {
+		return "webhooks"
+	}
+	if strings.Contains(operation, "push") {
+		return "push_devices"
+	}
+	if strings.Contains(operation, "privacy") {
+		return "user_privacy_settings"
+	}
 	if strings.Contains(operation, "user") {
 		return "users"
 	}
@@ -61,3 +96,53 @@ func MeasureQueryDuration(operation string, startTime time.Time) {
 	table := extractTableFromOperation(operation)
 	measureQueryDuration(operation, table, startTime)
 }
+
+const (
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+	pgCodeTooManyConnections   = "53300"
+	pgCodeAdminShutdown        = "57P01"
+	pgCodeCrashShutdown        = "57P02"
+	pgCodeCannotConnectNow     = "57P03"
+	pgClassConnectionException = "08"
+)
+
+func IsSerializationError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgCodeSerializationFailure || pgErr.Code == pgCodeDeadlockDetected
+}
+
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if IsSerializationError(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgCodeTooManyConnections, pgCodeAdminShutdown, pgCodeCrashShutdown, pgCodeCannotConnectNow:
+			return true
+		}
+		return strings.HasPrefix(pgErr.Code, pgClassConnectionException)
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func IsRetryableTxError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return IsSerializationError(err) || pgconn.SafeToRetry(err)
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"time"
 
+	pgx "github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
 type PoolConfig struct {
@@ -53,6 +55,8 @@ func NewPoolWithConfig(ctx context.Context, log *logger.Logger, databaseURL stri
 	pgxCfg.ConnConfig.RuntimeParams = map[string]string{
 		"application_name": "dh-secure-chat",
 	}
+	pgxCfg.ConnConfig.Logger = tracing.NewPgxLogger(nil)
+	pgxCfg.ConnConfig.LogLevel = pgx.LogLevelInfo
 
 	if cfg.MaxAttempts <= 0 {
 		cfg.MaxAttempts = 1
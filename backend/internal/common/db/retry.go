@@ -0,0 +1,21 @@
+package db
+
+import (
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+)
+
+func NewRetryPolicy(name string, retryable resilience.ErrorClassifier) *resilience.RetryPolicy {
+	if retryable == nil {
+		retryable = IsTransientError
+	}
+	return resilience.NewRetryPolicy(resilience.RetryConfig{
+		MaxAttempts:    constants.DefaultRetryMaxAttempts,
+		InitialBackoff: constants.DefaultRetryInitialBackoff,
+		MaxBackoff:     constants.DefaultRetryMaxBackoff,
+		Multiplier:     constants.DefaultRetryMultiplier,
+		Jitter:         constants.DefaultRetryJitter,
+		Retryable:      retryable,
+		Name:           name,
+	})
+}
@@ -14,6 +14,7 @@ const (
 	CategoryNotFound     ErrorCategory = "NOT_FOUND"
 	CategoryConflict     ErrorCategory = "CONFLICT"
 	CategoryUnauthorized ErrorCategory = "UNAUTHORIZED"
+	CategoryForbidden    ErrorCategory = "FORBIDDEN"
 	CategoryInternal     ErrorCategory = "INTERNAL"
 	CategoryExternal     ErrorCategory = "EXTERNAL"
 )
@@ -129,6 +130,20 @@ var (
 		"JWT_SECRET must be at least 32 bytes",
 	)
 
+	ErrInvalidConfigFile = NewDomainError(
+		"INVALID_CONFIG_FILE",
+		CategoryValidation,
+		http.StatusInternalServerError,
+		"config file could not be loaded",
+	)
+
+	ErrConfigReloadRejected = NewDomainError(
+		"CONFIG_RELOAD_REJECTED",
+		CategoryValidation,
+		http.StatusConflict,
+		"config change requires a restart",
+	)
+
 	ErrUserNotConnected = NewDomainError(
 		"USER_NOT_CONNECTED",
 		CategoryNotFound,
@@ -192,6 +207,13 @@ var (
 		"circuit breaker is open",
 	)
 
+	ErrBulkheadFull = NewDomainError(
+		"BULKHEAD_FULL",
+		CategoryExternal,
+		http.StatusServiceUnavailable,
+		"too many concurrent requests, try again later",
+	)
+
 	ErrInvalidToken = NewDomainError(
 		"INVALID_TOKEN",
 		CategoryUnauthorized,
@@ -241,6 +263,13 @@ var (
 		"client is too slow to receive messages",
 	)
 
+	ErrServerRestarting = NewDomainError(
+		"SERVER_RESTARTING",
+		CategoryExternal,
+		http.StatusServiceUnavailable,
+		"server is restarting, reconnect to continue",
+	)
+
 	ErrInvalidPayload = NewDomainError(
 		"INVALID_PAYLOAD",
 		CategoryValidation,
@@ -283,6 +312,41 @@ var (
 		"mime type not allowed",
 	)
 
+	ErrInvalidBinaryFrame = NewDomainError(
+		"INVALID_BINARY_FRAME",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"invalid binary frame",
+	)
+
+	ErrBinaryFramesNotNegotiated = NewDomainError(
+		"BINARY_FRAMES_NOT_NEGOTIATED",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"binary frames were not negotiated for this connection",
+	)
+
+	ErrMessageTypeUnsupported = NewDomainError(
+		"MESSAGE_TYPE_UNSUPPORTED",
+		CategoryValidation,
+		http.StatusUnprocessableEntity,
+		"recipient does not support this message type",
+	)
+
+	ErrTooManyPresenceSubscriptions = NewDomainError(
+		"TOO_MANY_PRESENCE_SUBSCRIPTIONS",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"too many presence subscriptions",
+	)
+
+	ErrInvalidPresenceVisibility = NewDomainError(
+		"INVALID_PRESENCE_VISIBILITY",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"presence_visibility must be one of everyone, contacts, nobody",
+	)
+
 	ErrUnknownMessageType = NewDomainError(
 		"UNKNOWN_MESSAGE_TYPE",
 		CategoryValidation,
@@ -359,4 +423,186 @@ var (
 		http.StatusInternalServerError,
 		"failed to search users",
 	)
+
+	ErrAuditListFailed = NewDomainError(
+		"AUDIT_LIST_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to list audit events",
+	)
+
+	ErrReceiptsListFailed = NewDomainError(
+		"RECEIPTS_LIST_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to list message receipts",
+	)
+
+	ErrPrivacySettingsFailed = NewDomainError(
+		"PRIVACY_SETTINGS_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to access privacy settings",
+	)
+
+	ErrConversationNotFound = NewDomainError(
+		"CONVERSATION_NOT_FOUND",
+		CategoryNotFound,
+		http.StatusNotFound,
+		"conversation not found",
+	)
+
+	ErrConversationsListFailed = NewDomainError(
+		"CONVERSATIONS_LIST_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to list conversations",
+	)
+
+	ErrConversationUpdateFailed = NewDomainError(
+		"CONVERSATION_UPDATE_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to update conversation",
+	)
+
+	ErrInvalidDisappearingTimer = NewDomainError(
+		"INVALID_DISAPPEARING_TIMER",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"ttl_seconds must be 0 or between 5 seconds and 28 days",
+	)
+
+	ErrDisappearingTimerFailed = NewDomainError(
+		"DISAPPEARING_TIMER_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to access disappearing messages timer",
+	)
+
+	ErrMessageExpired = NewDomainError(
+		"MESSAGE_EXPIRED",
+		CategoryValidation,
+		http.StatusGone,
+		"message has expired",
+	)
+
+	ErrInvalidPushDevice = NewDomainError(
+		"INVALID_PUSH_DEVICE",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"push device token is invalid",
+	)
+
+	ErrPushProviderUnavailable = NewDomainError(
+		"PUSH_PROVIDER_UNAVAILABLE",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"push provider is not configured",
+	)
+
+	ErrPushDeviceLimit = NewDomainError(
+		"PUSH_DEVICE_LIMIT",
+		CategoryConflict,
+		http.StatusConflict,
+		"too many push devices registered",
+	)
+
+	ErrPushDeviceNotFound = NewDomainError(
+		"PUSH_DEVICE_NOT_FOUND",
+		CategoryNotFound,
+		http.StatusNotFound,
+		"push device not found",
+	)
+
+	ErrPushDeviceFailed = NewDomainError(
+		"PUSH_DEVICE_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to update push devices",
+	)
+
+	ErrInvalidWebhook = NewDomainError(
+		"INVALID_WEBHOOK",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"invalid webhook url or events",
+	)
+
+	ErrWebhookLimit = NewDomainError(
+		"WEBHOOK_LIMIT",
+		CategoryConflict,
+		http.StatusConflict,
+		"too many webhooks registered",
+	)
+
+	ErrWebhookNotFound = NewDomainError(
+		"WEBHOOK_NOT_FOUND",
+		CategoryNotFound,
+		http.StatusNotFound,
+		"webhook not found",
+	)
+
+	ErrWebhookFailed = NewDomainError(
+		"WEBHOOK_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to update webhooks",
+	)
+
+	ErrBotMessageFailed = NewDomainError(
+		"BOT_MESSAGE_FAILED",
+		CategoryInternal,
+		http.StatusServiceUnavailable,
+		"message delivery is unavailable",
+	)
+
+	ErrInvalidReaction = NewDomainError(
+		"INVALID_REACTION",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"reaction must be a single emoji with action add or remove",
+	)
+
+	ErrReactionLimit = NewDomainError(
+		"REACTION_LIMIT",
+		CategoryConflict,
+		http.StatusConflict,
+		"too many reactions on message",
+	)
+
+	ErrReactionFailed = NewDomainError(
+		"REACTION_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to update message reactions",
+	)
+
+	ErrMessageNotOwned = NewDomainError(
+		"MESSAGE_NOT_OWNED",
+		CategoryForbidden,
+		http.StatusForbidden,
+		"message was not sent by this user",
+	)
+
+	ErrEditWindowExpired = NewDomainError(
+		"EDIT_WINDOW_EXPIRED",
+		CategoryConflict,
+		http.StatusConflict,
+		"message can no longer be edited",
+	)
+
+	ErrInvalidDeleteScope = NewDomainError(
+		"INVALID_DELETE_SCOPE",
+		CategoryValidation,
+		http.StatusBadRequest,
+		"delete scope must be me or everyone",
+	)
+
+	ErrMessageUpdateFailed = NewDomainError(
+		"MESSAGE_UPDATE_FAILED",
+		CategoryInternal,
+		http.StatusInternalServerError,
+		"failed to update message metadata",
+	)
 )
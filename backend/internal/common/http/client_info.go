@@ -0,0 +1,16 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+)
+
+func ClientInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), constants.ClientIPKey, GetClientIP(r))
+		ctx = context.WithValue(ctx, constants.UserAgentKey, r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -13,4 +13,6 @@ const (
 	CodeMissingAuthorization     = "MISSING_AUTHORIZATION"
 	CodeInvalidToken             = "INVALID_TOKEN"
 	CodeTokenMissingJTI          = "TOKEN_MISSING_JTI"
+	CodeInsufficientScope        = "INSUFFICIENT_SCOPE"
+	CodeInvalidLogLevel          = "INVALID_LOG_LEVEL"
 )
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/health"
 )
 
 func HealthHandler(log *logger.Logger) http.HandlerFunc {
@@ -16,3 +18,27 @@ func HealthHandler(log *logger.Logger) http.HandlerFunc {
 		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	}
 }
+
+func LivenessHandler(registry *health.Registry) http.HandlerFunc {
+	return probeHandler(registry.Liveness)
+}
+
+func ReadinessHandler(registry *health.Registry) http.HandlerFunc {
+	return probeHandler(registry.Readiness)
+}
+
+func probeHandler(probe func(ctx context.Context) health.Report) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		report := probe(r.Context())
+		status := http.StatusOK
+		if report.Status == health.StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+		WriteJSON(w, status, report)
+	}
+}
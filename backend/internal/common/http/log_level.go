@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+func LogLevelHandler(log *logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		switch r.Method {
+		case http.MethodGet:
+			WriteJSON(w, http.StatusOK, log.Levels())
+		case http.MethodPut:
+			var req logger.Levels
+			if err := DecodeJSON(r, &req); err != nil {
+				log.Warnf("set log level failed: invalid json: %v", err)
+				WriteErrorEnvelope(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json", nil, "")
+				return
+			}
+			levels, err := log.ApplyLevels(req)
+			if err != nil {
+				WriteErrorEnvelope(w, http.StatusBadRequest, CodeInvalidLogLevel, err.Error(), nil, "")
+				return
+			}
+			log.WithFields(r.Context(), logger.Fields{
+				"log_level":  levels.Level,
+				"components": levels.Components,
+				"action":     "log_level_changed",
+			}).Warn("log levels changed via admin endpoint")
+			WriteJSON(w, http.StatusOK, levels)
+		default:
+			WriteErrorEnvelope(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed", nil, "")
+		}
+	}
+}
@@ -6,15 +6,18 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/httpmetrics"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
 func BuildBaseHandler(appName string, log *logger.Logger, handler http.Handler) http.Handler {
 	metrics := httpmetrics.New(appName)
 	recovery := RecoveryMiddleware(log)
+	tracingMw := tracing.HTTPMiddleware
 	traceID := TraceIDMiddleware
+	clientInfo := ClientInfoMiddleware
 	maxRequestSize := MaxRequestSizeMiddleware(constants.DefaultMaxRequestSize)
 	securityHeaders := SecurityHeadersMiddleware
 	csp := ContentSecurityPolicyMiddleware("")
 
-	return securityHeaders(csp(recovery(traceID(maxRequestSize(metrics.Wrap(handler))))))
+	return securityHeaders(csp(recovery(tracingMw(traceID(clientInfo(maxRequestSize(metrics.Wrap(handler))))))))
 }
@@ -7,13 +7,17 @@ import (
 	"net/http"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
 const traceIDHeader = "X-Trace-ID"
 
 func TraceIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		traceID := r.Header.Get(traceIDHeader)
+		traceID := tracing.TraceID(r.Context())
+		if traceID == "" {
+			traceID = r.Header.Get(traceIDHeader)
+		}
 		if traceID == "" {
 			traceID = generateTraceID()
 		}
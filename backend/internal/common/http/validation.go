@@ -23,6 +23,10 @@ func ExtractUserIDFromPath(path string) (string, bool) {
 		prefix = "/api/identity/users/"
 	} else if strings.HasPrefix(path, "/api/chat/users/") {
 		prefix = "/api/chat/users/"
+	} else if strings.HasPrefix(path, "/api/chat/conversations/") {
+		prefix = "/api/chat/conversations/"
+	} else if strings.HasPrefix(path, "/api/auth/users/") {
+		prefix = "/api/auth/users/"
 	} else {
 		return "", false
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
@@ -19,9 +20,13 @@ type RevokedTokenChecker interface {
 }
 
 type Claims struct {
-	UserID   string
-	Username string
-	JTI      string
+	UserID    string
+	Username  string
+	JTI       string
+	Roles     []string
+	Scopes    []string
+	ExpiresAt time.Time
+	Derived   bool
 }
 
 type contextKey string
@@ -55,13 +60,21 @@ func Middleware(secret string, log *logger.Logger, checker RevokedTokenChecker)
 				revoked, err := checker.IsRevoked(r.Context(), claims.JTI)
 				if err != nil {
 					metrics.JWTValidationsFailed.Inc()
-					log.Errorf("jwt auth failed path=%s: failed to check revoked token jti=%s: %v", r.URL.Path, claims.JTI, err)
+					log.WithFields(r.Context(), logger.Fields{
+						"jti":    claims.JTI,
+						"path":   r.URL.Path,
+						"action": "jwt_revocation_check_failed",
+					}).Errorf("jwt auth failed: failed to check revoked token: %v", err)
 					commonhttp.WriteError(w, http.StatusInternalServerError, "internal error")
 					return
 				}
 				if revoked {
 					metrics.JWTValidationsFailed.Inc()
-					log.Warnf("jwt auth failed path=%s: token revoked jti=%s", r.URL.Path, claims.JTI)
+					log.WithFields(r.Context(), logger.Fields{
+						"jti":    claims.JTI,
+						"path":   r.URL.Path,
+						"action": "jwt_token_revoked",
+					}).Warn("jwt auth failed: token revoked")
 					commonhttp.WriteError(w, http.StatusUnauthorized, "token revoked")
 					return
 				}
@@ -138,9 +151,37 @@ func parseToken(tokenString string, secret []byte) (Claims, error) {
 		return Claims{}, commonerrors.ErrMissingTokenClaims
 	}
 
-	return Claims{
+	claims := Claims{
 		UserID:   sub,
 		Username: username,
 		JTI:      jti,
-	}, nil
+		Roles:    extractStringSlice(mapClaims["roles"]),
+	}
+	claims.Derived, _ = mapClaims["drv"].(bool)
+
+	if rawScope, ok := mapClaims["scope"].(string); ok {
+		claims.Scopes = SplitScopes(rawScope)
+	} else {
+		claims.Scopes = append([]string(nil), DefaultScopes...)
+	}
+
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	return claims, nil
+}
+
+func extractStringSlice(raw any) []string {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
 }
@@ -0,0 +1,94 @@
+package jwtverify
+
+import (
+	"net/http"
+	"strings"
+
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+)
+
+const (
+	ScopeProfileRead    = "profile:read"
+	ScopeUsersRead      = "users:read"
+	ScopeIdentityRead   = "identity:read"
+	ScopeIdentityWrite  = "identity:write"
+	ScopeChatConnect    = "chat:connect"
+	ScopeTokensIssue    = "tokens:issue"
+	ScopeRolesAdmin     = "roles:admin"
+	ScopeLogsAdmin      = "logs:admin"
+	ScopeMessagesSend   = "messages:send"
+	ScopeWebhooksManage = "webhooks:manage"
+)
+
+var DefaultScopes = []string{
+	ScopeProfileRead,
+	ScopeUsersRead,
+	ScopeIdentityRead,
+	ScopeIdentityWrite,
+	ScopeChatConnect,
+	ScopeTokensIssue,
+}
+
+var knownScopes = map[string]bool{
+	ScopeProfileRead:    true,
+	ScopeUsersRead:      true,
+	ScopeIdentityRead:   true,
+	ScopeIdentityWrite:  true,
+	ScopeChatConnect:    true,
+	ScopeTokensIssue:    true,
+	ScopeRolesAdmin:     true,
+	ScopeLogsAdmin:      true,
+	ScopeMessagesSend:   true,
+	ScopeWebhooksManage: true,
+}
+
+func IsKnownScope(scope string) bool {
+	return knownScopes[scope]
+}
+
+func (c Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func RequireScope(scopes ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := FromContext(r.Context())
+			if !ok {
+				commonhttp.WriteErrorEnvelope(w, http.StatusUnauthorized, commonhttp.CodeMissingAuthorization, "unauthorized", nil, "")
+				return
+			}
+			for _, scope := range scopes {
+				if !claims.HasScope(scope) {
+					commonhttp.WriteErrorEnvelope(w, http.StatusForbidden, commonhttp.CodeInsufficientScope, "insufficient scope", map[string]any{
+						"required": scope,
+					}, "")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func SplitScopes(raw string) []string {
+	return strings.Fields(raw)
+}
@@ -0,0 +1,248 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+type Levels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+func (lvl LogLevel) String() string {
+	if name, ok := levelNames[lvl]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+func ParseLevel(value string) (LogLevel, error) {
+	switch strings.TrimSpace(strings.ToUpper(value)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARNING", "WARN":
+		return WARNING, nil
+	case "ERROR":
+		return ERROR, nil
+	case "CRITICAL":
+		return CRITICAL, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level %q", value)
+	}
+}
+
+func ParseComponentLevels(raw string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, part := range strings.FieldsFunc(raw, isLevelSeparator) {
+		component, value, ok := strings.Cut(part, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component level %q, expected component=LEVEL", part)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+func isLevelSeparator(r rune) bool {
+	return r == ',' || r == '\n' || r == ' ' || r == '\t' || r == '\r'
+}
+
+func newLevels(level LogLevel, components map[string]LogLevel) Levels {
+	snapshot := Levels{
+		Level:      level.String(),
+		Components: make(map[string]string, len(components)),
+	}
+	for component, componentLevel := range components {
+		snapshot.Components[component] = componentLevel.String()
+	}
+	return snapshot
+}
+
+func (c *core) levelFor(component string) LogLevel {
+	if component != "" {
+		if level, ok := c.componentLevels[component]; ok {
+			return level
+		}
+	}
+	return c.level
+}
+
+func (l *Logger) Levels() Levels {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+	return newLevels(l.core.level, l.core.componentLevels)
+}
+
+func (l *Logger) SetLevel(level LogLevel) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.level = level
+}
+
+func (l *Logger) SetComponentLevel(component string, level LogLevel) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.componentLevels[component] = level
+}
+
+func (l *Logger) ResetComponentLevel(component string) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	delete(l.core.componentLevels, component)
+}
+
+func (l *Logger) ApplyLevels(update Levels) (Levels, error) {
+	var level *LogLevel
+	if strings.TrimSpace(update.Level) != "" {
+		parsed, err := ParseLevel(update.Level)
+		if err != nil {
+			return Levels{}, err
+		}
+		level = &parsed
+	}
+
+	overrides := make(map[string]LogLevel, len(update.Components))
+	resets := make([]string, 0)
+	for component, value := range update.Components {
+		component = strings.TrimSpace(component)
+		if component == "" {
+			return Levels{}, fmt.Errorf("component name must not be empty")
+		}
+		if strings.TrimSpace(value) == "" {
+			resets = append(resets, component)
+			continue
+		}
+		parsed, err := ParseLevel(value)
+		if err != nil {
+			return Levels{}, err
+		}
+		overrides[component] = parsed
+	}
+
+	l.core.mu.Lock()
+	if level != nil {
+		l.core.level = *level
+	}
+	for component, componentLevel := range overrides {
+		l.core.componentLevels[component] = componentLevel
+	}
+	for _, component := range resets {
+		delete(l.core.componentLevels, component)
+	}
+	snapshot := newLevels(l.core.level, l.core.componentLevels)
+	l.core.mu.Unlock()
+
+	return snapshot, nil
+}
+
+func (l *Logger) Reload() (Levels, error) {
+	l.core.mu.RLock()
+	levelFile := l.core.levelFile
+	defaults := l.core.defaults
+	l.core.mu.RUnlock()
+
+	level, components, err := defaultsToLevels(defaults)
+	if err != nil {
+		return Levels{}, err
+	}
+	if levelFile != "" {
+		level, components, err = readLevelFile(levelFile)
+		if err != nil {
+			return Levels{}, err
+		}
+	}
+
+	l.core.mu.Lock()
+	l.core.level = level
+	l.core.componentLevels = components
+	snapshot := newLevels(level, components)
+	l.core.mu.Unlock()
+
+	return snapshot, nil
+}
+
+func defaultsToLevels(defaults Levels) (LogLevel, map[string]LogLevel, error) {
+	level, err := ParseLevel(defaults.Level)
+	if err != nil {
+		return INFO, nil, err
+	}
+	components := make(map[string]LogLevel, len(defaults.Components))
+	for component, value := range defaults.Components {
+		componentLevel, err := ParseLevel(value)
+		if err != nil {
+			return INFO, nil, err
+		}
+		components[component] = componentLevel
+	}
+	return level, components, nil
+}
+
+func readLevelFile(path string) (LogLevel, map[string]LogLevel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return INFO, nil, fmt.Errorf("read log level file: %w", err)
+	}
+
+	level := INFO
+	overrides := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, token := range strings.FieldsFunc(line, isLevelSeparator) {
+			if strings.Contains(token, "=") {
+				overrides = append(overrides, token)
+				continue
+			}
+			level, err = ParseLevel(token)
+			if err != nil {
+				return INFO, nil, err
+			}
+		}
+	}
+
+	components, err := ParseComponentLevels(strings.Join(overrides, ","))
+	if err != nil {
+		return INFO, nil, err
+	}
+	return level, components, nil
+}
+
+func (l *Logger) WatchReloadSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			levels, err := l.Reload()
+			if err != nil {
+				l.WithFields(ctx, Fields{
+					"action": "log_level_reload_failed",
+				}).Errorf("failed to reload log levels on SIGHUP: %v", err)
+				continue
+			}
+			l.WithFields(ctx, Fields{
+				"log_level":  levels.Level,
+				"components": levels.Components,
+				"action":     "log_level_reloaded",
+			}).Warn("log levels reloaded on SIGHUP")
+		}
+	}
+}
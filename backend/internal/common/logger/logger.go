@@ -14,10 +14,9 @@ import (
 	"sync"
 	"time"
 
-	"gopkg.in/natefinch/lumberjack.v2"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
-	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 )
 
 type Fields map[string]interface{}
@@ -59,59 +58,83 @@ type LoggerInterface interface {
 }
 
 type Logger struct {
-	level       LogLevel
-	out         *log.Logger
-	serviceName string
-	mu          sync.RWMutex
-	sampler     *rand.Rand
-	samplerMu   sync.Mutex
+	core      *core
+	component string
 }
 
-func New(logDir, serviceName, level string) (*Logger, error) {
-	l := &Logger{
-		level:       INFO,
-		out:         log.New(os.Stderr, "", 0),
-		serviceName: "",
-		sampler:     rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+type core struct {
+	mu              sync.RWMutex
+	level           LogLevel
+	componentLevels map[string]LogLevel
+	defaults        Levels
+	levelFile       string
+	redactor        *Redactor
+	out             *log.Logger
+	serviceName     string
+	sampler         *rand.Rand
+	samplerMu       sync.Mutex
+}
 
-	if err := l.initialize(logDir, serviceName, level); err != nil {
-		return nil, err
-	}
+type Config struct {
+	Dir             string
+	ServiceName     string
+	Level           string
+	ComponentLevels string
+	LevelFile       string
+	Sinks           string
+	SyslogAddress   string
+	RedactionRules  string
+	RedactionSalt   string
+	Writer          io.Writer
+}
 
-	return l, nil
+func New(logDir, serviceName, level string) (*Logger, error) {
+	return NewWithConfig(Config{
+		Dir:         logDir,
+		ServiceName: serviceName,
+		Level:       level,
+	})
 }
 
-func (l *Logger) initialize(logDir, serviceName, level string) error {
-	if logDir == "" {
-		logDir = "/var/log/dh-secure-chat"
+func NewWithConfig(config Config) (*Logger, error) {
+	componentLevels, err := ParseComponentLevels(config.ComponentLevels)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := os.MkdirAll(logDir, 0o755); err != nil {
-		return commonerrors.ErrInternalError.WithCause(err)
+	sinks, err := ParseSinks(config.Sinks)
+	if err != nil {
+		return nil, err
 	}
 
-	logFile := filepath.Join(logDir, "app.log")
-
-	fileWriter := &lumberjack.Logger{
-		Filename:   logFile,
-		MaxSize:    constants.LoggerMaxSize,
-		MaxBackups: constants.LoggerMaxBackups,
-		MaxAge:     constants.LoggerMaxAge,
-		Compress:   true,
+	redactionRules, err := ParseRedactionRules(config.RedactionRules)
+	if err != nil {
+		return nil, err
 	}
 
-	multiWriter := io.MultiWriter(os.Stdout, fileWriter)
-
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	writer := config.Writer
+	if writer == nil {
+		writer, err = openSinks(sinks, config)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	l.out = log.New(multiWriter, "", 0)
-	l.level = parseLevel(level)
-	l.serviceName = serviceName
-	l.sampler = rand.New(rand.NewSource(time.Now().UnixNano()))
+	level := parseLevel(config.Level)
+	c := &core{
+		level:           level,
+		componentLevels: componentLevels,
+		defaults:        newLevels(level, componentLevels),
+		levelFile:       config.LevelFile,
+		redactor:        NewRedactor(config.RedactionSalt, MergeRedactionRules(DefaultRedactionRules, redactionRules)),
+		out:             log.New(writer, "", 0),
+		serviceName:     config.ServiceName,
+		sampler:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	return &Logger{core: c}, nil
+}
 
-	return nil
+func (l *Logger) Component(name string) *Logger {
+	return &Logger{core: l.core, component: name}
 }
 
 func (l *Logger) log(level LogLevel, msg string) {
@@ -123,19 +146,21 @@ func (l *Logger) logWithContext(level LogLevel, ctx context.Context, msg string)
 }
 
 func (l *Logger) logWithFields(level LogLevel, ctx context.Context, msg string, fields Fields) {
-	l.mu.RLock()
-	currentLevel := l.level
-	service := l.serviceName
-	l.mu.RUnlock()
+	l.core.mu.RLock()
+	currentLevel := l.core.levelFor(l.component)
+	service := l.core.serviceName
+	out := l.core.out
+	redactor := l.core.redactor
+	l.core.mu.RUnlock()
 
 	if level < currentLevel {
 		return
 	}
 
-	l.logJSON(level, ctx, msg, fields, service)
+	l.logJSON(out, level, ctx, msg, redactor.Apply(fields), service)
 }
 
-func (l *Logger) logJSON(level LogLevel, ctx context.Context, msg string, fields Fields, service string) {
+func (l *Logger) logJSON(out *log.Logger, level LogLevel, ctx context.Context, msg string, fields Fields, service string) {
 	logEntry := map[string]interface{}{
 		"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
 		"level":     levelNames[level],
@@ -145,11 +170,19 @@ func (l *Logger) logJSON(level LogLevel, ctx context.Context, msg string, fields
 	if service != "" {
 		logEntry["service"] = service
 	}
+	if l.component != "" {
+		logEntry["component"] = l.component
+	}
 
 	traceID := getTraceIDFromContext(ctx)
 	if traceID != "" {
 		logEntry["trace_id"] = traceID
 	}
+	if ctx != nil {
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasSpanID() {
+			logEntry["span_id"] = spanContext.SpanID().String()
+		}
+	}
 
 	file, line := l.getCallerInfo()
 	logEntry["file"] = file
@@ -169,11 +202,11 @@ func (l *Logger) logJSON(level LogLevel, ctx context.Context, msg string, fields
 			"message":   fmt.Sprintf("failed to marshal log entry: %v", err),
 		}
 		fallbackBytes, _ := json.Marshal(fallbackEntry)
-		_ = l.out.Output(0, string(fallbackBytes))
+		_ = out.Output(0, string(fallbackBytes))
 		return
 	}
 
-	_ = l.out.Output(0, string(jsonBytes))
+	_ = out.Output(0, string(jsonBytes))
 }
 
 func getTraceIDFromContext(ctx context.Context) string {
@@ -183,6 +216,9 @@ func getTraceIDFromContext(ctx context.Context) string {
 
 	val := ctx.Value(constants.TraceIDKey)
 	if val == nil {
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			return spanContext.TraceID().String()
+		}
 		return ""
 	}
 
@@ -244,9 +280,9 @@ func (l *Logger) WithFields(ctx context.Context, fields Fields) *Entry {
 }
 
 func (l *Logger) ShouldLog(level LogLevel) bool {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-	return level >= l.level
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+	return level >= l.core.levelFor(l.component)
 }
 
 func (l *Logger) ShouldSample(prob float64) bool {
@@ -291,21 +327,11 @@ func (e *Entry) Criticalf(format string, args ...any) {
 }
 
 func parseLevel(value string) LogLevel {
-	value = strings.TrimSpace(strings.ToUpper(value))
-	switch value {
-	case "DEBUG":
-		return DEBUG
-	case "INFO":
-		return INFO
-	case "WARNING", "WARN":
-		return WARNING
-	case "ERROR":
-		return ERROR
-	case "CRITICAL":
-		return CRITICAL
-	default:
+	level, err := ParseLevel(value)
+	if err != nil {
 		return INFO
 	}
+	return level
 }
 
 func (l *Logger) sample(prob float64) bool {
@@ -315,9 +341,9 @@ func (l *Logger) sample(prob float64) bool {
 	if prob >= 1 {
 		return true
 	}
-	l.samplerMu.Lock()
-	defer l.samplerMu.Unlock()
-	return l.sampler.Float64() < prob
+	l.core.samplerMu.Lock()
+	defer l.core.samplerMu.Unlock()
+	return l.core.sampler.Float64() < prob
 }
 
 func (l *Logger) getCallerInfo() (string, int) {
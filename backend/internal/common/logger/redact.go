@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type RedactAction string
+
+const (
+	RedactDrop     RedactAction = "drop"
+	RedactHash     RedactAction = "hash"
+	RedactTruncate RedactAction = "truncate"
+)
+
+type RedactionRule struct {
+	Field  string
+	Action RedactAction
+	Keep   int
+}
+
+var DefaultRedactionRules = []RedactionRule{
+	{Field: "jti", Action: RedactHash},
+	{Field: "username", Action: RedactHash},
+	{Field: "client_ip", Action: RedactHash},
+	{Field: "ip", Action: RedactHash},
+	{Field: "remote_addr", Action: RedactHash},
+	{Field: "user_agent", Action: RedactTruncate, Keep: 64},
+	{Field: "query", Action: RedactTruncate, Keep: 3},
+	{Field: "public_key", Action: RedactTruncate, Keep: 16},
+}
+
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)(tokens?$|password|passwd|secret|ciphertext|private_key|authorization|cookie)`)
+
+func IsSensitiveFieldName(name string) bool {
+	return sensitiveFieldPattern.MatchString(name)
+}
+
+type Redactor struct {
+	rules map[string]RedactionRule
+	salt  []byte
+}
+
+func NewRedactor(salt string, rules []RedactionRule) *Redactor {
+	saltBytes := []byte(salt)
+	if len(saltBytes) == 0 {
+		saltBytes = make([]byte, 32)
+		_, _ = rand.Read(saltBytes)
+	}
+
+	byField := make(map[string]RedactionRule, len(rules))
+	for _, rule := range rules {
+		byField[strings.ToLower(rule.Field)] = rule
+	}
+	return &Redactor{rules: byField, salt: saltBytes}
+}
+
+func ParseRedactionRules(raw string) ([]RedactionRule, error) {
+	rules := make([]RedactionRule, 0)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, spec, ok := strings.Cut(part, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid redaction rule %q, expected field=action", part)
+		}
+
+		action, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		rule := RedactionRule{Field: field, Action: RedactAction(strings.ToLower(action))}
+		switch rule.Action {
+		case RedactDrop, RedactHash:
+		case RedactTruncate:
+			keep, err := strconv.Atoi(arg)
+			if err != nil || keep < 0 {
+				return nil, fmt.Errorf("invalid truncate length in redaction rule %q", part)
+			}
+			rule.Keep = keep
+		default:
+			return nil, fmt.Errorf("unknown redaction action %q", action)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func MergeRedactionRules(base, overrides []RedactionRule) []RedactionRule {
+	merged := make([]RedactionRule, 0, len(base)+len(overrides))
+	index := make(map[string]int, len(base)+len(overrides))
+	for _, rule := range append(append([]RedactionRule(nil), base...), overrides...) {
+		key := strings.ToLower(rule.Field)
+		if i, ok := index[key]; ok {
+			merged[i] = rule
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, rule)
+	}
+	return merged
+}
+
+func (r *Redactor) Apply(fields Fields) Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make(Fields, len(fields))
+	for key, value := range fields {
+		rule, ok := r.rules[strings.ToLower(key)]
+		if !ok {
+			if IsSensitiveFieldName(key) {
+				continue
+			}
+			redacted[key] = value
+			continue
+		}
+
+		switch rule.Action {
+		case RedactDrop:
+		case RedactHash:
+			redacted[key] = r.hash(value)
+		case RedactTruncate:
+			redacted[key] = truncate(value, rule.Keep)
+		default:
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+func (r *Redactor) hash(value interface{}) string {
+	text := fmt.Sprint(value)
+	if text == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(text))
+	return "h:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+func truncate(value interface{}, keep int) string {
+	runes := []rune(fmt.Sprint(value))
+	if len(runes) <= keep {
+		return string(runes)
+	}
+	return string(runes[:keep]) + "…"
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+)
+
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+var DefaultSinks = []string{SinkStdout, SinkFile}
+
+func ParseSinks(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return DefaultSinks, nil
+	}
+
+	seen := make(map[string]bool)
+	sinks := make([]string, 0, 3)
+	for _, part := range strings.Split(raw, ",") {
+		sink := strings.ToLower(strings.TrimSpace(part))
+		if sink == "" || seen[sink] {
+			continue
+		}
+		switch sink {
+		case SinkStdout, SinkFile, SinkSyslog:
+		default:
+			return nil, fmt.Errorf("unknown log sink %q", sink)
+		}
+		seen[sink] = true
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return DefaultSinks, nil
+	}
+	return sinks, nil
+}
+
+func openSinks(sinks []string, config Config) (io.Writer, error) {
+	writers := make([]io.Writer, 0, len(sinks))
+
+	for _, sink := range sinks {
+		switch sink {
+		case SinkStdout:
+			writers = append(writers, os.Stdout)
+		case SinkFile:
+			fileWriter, err := openFileSink(config.Dir)
+			if err != nil {
+				return nil, err
+			}
+			writers = append(writers, fileWriter)
+		case SinkSyslog:
+			syslogWriter, err := openSyslogSink(config.SyslogAddress, config.ServiceName)
+			if err != nil {
+				return nil, err
+			}
+			writers = append(writers, syslogWriter)
+		}
+	}
+
+	return io.MultiWriter(writers...), nil
+}
+
+func openFileSink(logDir string) (*lumberjack.Logger, error) {
+	if logDir == "" {
+		logDir = "/var/log/dh-secure-chat"
+	}
+
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return nil, commonerrors.ErrInternalError.WithCause(err)
+	}
+
+	return &lumberjack.Logger{
+		Filename:   filepath.Join(logDir, "app.log"),
+		MaxSize:    constants.LoggerMaxSize,
+		MaxBackups: constants.LoggerMaxBackups,
+		MaxAge:     constants.LoggerMaxAge,
+		Compress:   true,
+	}, nil
+}
+
+func openSyslogSink(address, serviceName string) (*syslog.Writer, error) {
+	tag := serviceName
+	if tag == "" {
+		tag = "dh-secure-chat"
+	}
+	priority := syslog.LOG_INFO | syslog.LOG_DAEMON
+
+	if address == "" {
+		writer, err := syslog.New(priority, tag)
+		if err != nil {
+			return nil, commonerrors.ErrInternalError.WithCause(err)
+		}
+		return writer, nil
+	}
+
+	network, raddr, ok := strings.Cut(address, "://")
+	if !ok {
+		network, raddr = "udp", address
+	}
+	writer, err := syslog.Dial(network, raddr, priority, tag)
+	if err != nil {
+		return nil, commonerrors.ErrInternalError.WithCause(err)
+	}
+	return writer, nil
+}
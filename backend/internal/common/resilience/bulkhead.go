@@ -0,0 +1,292 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type Bulkhead struct {
+	mu            sync.Mutex
+	limit         float64
+	minLimit      float64
+	maxLimit      float64
+	inFlight      int
+	waiters       []chan struct{}
+	maxQueue      int
+	queueTimeout  time.Duration
+	adaptive      bool
+	targetLatency time.Duration
+	decrease      float64
+	clock         clock.Clock
+	name          string
+	log           *logger.Logger
+}
+
+type BulkheadConfig struct {
+	MaxConcurrent  int
+	MinConcurrent  int
+	MaxLimit       int
+	MaxQueue       int
+	QueueTimeout   time.Duration
+	Adaptive       bool
+	TargetLatency  time.Duration
+	DecreaseFactor float64
+	Clock          clock.Clock
+	Name           string
+	Logger         *logger.Logger
+}
+
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	timeClock := config.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	limit := config.MaxConcurrent
+	if limit <= 0 {
+		limit = constants.DefaultBulkheadMaxConcurrent
+	}
+	minLimit := config.MinConcurrent
+	if minLimit <= 0 || minLimit > limit {
+		minLimit = min(constants.DefaultBulkheadMinConcurrent, limit)
+	}
+	maxLimit := config.MaxLimit
+	if maxLimit < limit {
+		maxLimit = limit
+	}
+	maxQueue := config.MaxQueue
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	queueTimeout := config.QueueTimeout
+	if queueTimeout <= 0 {
+		queueTimeout = constants.DefaultBulkheadQueueTimeout
+	}
+	targetLatency := config.TargetLatency
+	if targetLatency <= 0 {
+		targetLatency = constants.DefaultBulkheadTargetLatency
+	}
+	decrease := config.DecreaseFactor
+	if decrease <= 0 || decrease >= 1 {
+		decrease = constants.DefaultBulkheadDecreaseFactor
+	}
+
+	bulkhead := &Bulkhead{
+		limit:         float64(limit),
+		minLimit:      float64(minLimit),
+		maxLimit:      float64(maxLimit),
+		maxQueue:      maxQueue,
+		queueTimeout:  queueTimeout,
+		adaptive:      config.Adaptive,
+		targetLatency: targetLatency,
+		decrease:      decrease,
+		clock:         timeClock,
+		name:          config.Name,
+		log:           config.Logger,
+	}
+	bulkhead.setLimitMetric(bulkhead.limit)
+	return bulkhead
+}
+
+func (b *Bulkhead) Limit() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.limit)
+}
+
+func (b *Bulkhead) SetLimit(limit int) {
+	if limit <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = float64(limit)
+	b.maxLimit = math.Max(b.maxLimit, b.limit)
+	b.minLimit = math.Min(b.minLimit, b.limit)
+	b.grantLocked()
+	b.setLimitMetric(b.limit)
+	b.observeLocked()
+}
+
+func (b *Bulkhead) InFlight() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight
+}
+
+func (b *Bulkhead) QueueLen() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.waiters)
+}
+
+func (b *Bulkhead) Call(ctx context.Context, fn func(context.Context) error) error {
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+
+	start := b.clock.Now()
+	err := fn(ctx)
+	b.release(b.clock.Since(start), err)
+	return err
+}
+
+func (b *Bulkhead) Around(breaker CircuitBreakerInterface) CircuitBreakerInterface {
+	return &bulkheadBreaker{bulkhead: b, breaker: breaker}
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	b.mu.Lock()
+	if b.inFlight < int(b.limit) && len(b.waiters) == 0 {
+		b.inFlight++
+		b.observeLocked()
+		b.mu.Unlock()
+		return nil
+	}
+	if len(b.waiters) >= b.maxQueue {
+		b.mu.Unlock()
+		return b.reject(ctx, "queue_full")
+	}
+	ready := make(chan struct{})
+	b.waiters = append(b.waiters, ready)
+	b.observeLocked()
+	b.mu.Unlock()
+
+	start := b.clock.Now()
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		b.observeWait(start)
+		return nil
+	case <-timer.C:
+		if !b.withdraw(ready) {
+			b.observeWait(start)
+			return nil
+		}
+		b.observeWait(start)
+		return b.reject(ctx, "queue_timeout")
+	case <-ctx.Done():
+		if !b.withdraw(ready) {
+			b.release(0, ctx.Err())
+		}
+		b.observeWait(start)
+		b.countRejected("canceled")
+		return ctx.Err()
+	}
+}
+
+func (b *Bulkhead) withdraw(ready chan struct{}) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, waiter := range b.waiters {
+		if waiter == ready {
+			b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+			b.observeLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bulkhead) release(latency time.Duration, err error) {
+	b.mu.Lock()
+	saturated := b.inFlight >= int(b.limit)
+	b.inFlight--
+	if b.adaptive && !errors.Is(err, context.Canceled) {
+		b.adaptLocked(latency, err, saturated)
+	}
+	b.grantLocked()
+	b.observeLocked()
+	b.mu.Unlock()
+}
+
+func (b *Bulkhead) grantLocked() {
+	for len(b.waiters) > 0 && b.inFlight < int(b.limit) {
+		ready := b.waiters[0]
+		b.waiters = b.waiters[1:]
+		b.inFlight++
+		close(ready)
+	}
+}
+
+func (b *Bulkhead) adaptLocked(latency time.Duration, err error, saturated bool) {
+	previous := b.limit
+	switch {
+	case latency > b.targetLatency || errors.Is(err, context.DeadlineExceeded):
+		b.limit = math.Max(b.minLimit, b.limit*b.decrease)
+	case saturated:
+		b.limit = math.Min(b.maxLimit, b.limit+1/b.limit)
+	}
+	if int(previous) != int(b.limit) {
+		b.setLimitMetric(b.limit)
+		if b.log != nil {
+			b.log.Debugf("bulkhead [%s]: concurrency limit changed %d -> %d", b.name, int(previous), int(b.limit))
+		}
+	}
+}
+
+func (b *Bulkhead) reject(ctx context.Context, reason string) error {
+	b.countRejected(reason)
+	trace.SpanFromContext(ctx).AddEvent("bulkhead.rejected", trace.WithAttributes(
+		attribute.String("bulkhead.name", b.name),
+		attribute.String("bulkhead.reason", reason),
+	))
+	if b.log != nil {
+		b.log.WithFields(ctx, logger.Fields{
+			"bulkhead": b.name,
+			"reason":   reason,
+			"action":   "bulkhead_rejected",
+		}).Warnf("bulkhead [%s]: rejecting call (%s)", b.name, reason)
+	}
+	return commonerrors.ErrBulkheadFull
+}
+
+func (b *Bulkhead) countRejected(reason string) {
+	if b.name != "" {
+		metrics.BulkheadRejected.WithLabelValues(b.name, reason).Inc()
+	}
+}
+
+func (b *Bulkhead) observeLocked() {
+	if b.name != "" {
+		metrics.BulkheadInFlight.WithLabelValues(b.name).Set(float64(b.inFlight))
+		metrics.BulkheadQueued.WithLabelValues(b.name).Set(float64(len(b.waiters)))
+	}
+}
+
+func (b *Bulkhead) observeWait(start time.Time) {
+	if b.name != "" {
+		metrics.BulkheadWaitSeconds.WithLabelValues(b.name).Observe(b.clock.Since(start).Seconds())
+	}
+}
+
+func (b *Bulkhead) setLimitMetric(limit float64) {
+	if b.name != "" {
+		metrics.BulkheadLimit.WithLabelValues(b.name).Set(float64(int(limit)))
+	}
+}
+
+type bulkheadBreaker struct {
+	bulkhead *Bulkhead
+	breaker  CircuitBreakerInterface
+}
+
+func (g *bulkheadBreaker) Call(ctx context.Context, fn func(context.Context) error) error {
+	return g.bulkhead.Call(ctx, func(ctx context.Context) error {
+		return g.breaker.Call(ctx, fn)
+	})
+}
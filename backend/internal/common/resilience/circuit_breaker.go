@@ -3,102 +3,316 @@ package resilience
 import (
 	"context"
 	"errors"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	pgx "github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
 )
 
 type CircuitBreakerInterface interface {
 	Call(ctx context.Context, fn func(context.Context) error) error
 }
 
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+type ErrorClassifier func(err error) bool
+
+func DefaultErrorClassifier(err error) bool {
+	if err == nil || errors.Is(err, pgx.ErrNoRows) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if domainErr, ok := commonerrors.AsDomainError(err); ok {
+		switch domainErr.Category() {
+		case commonerrors.CategoryInternal, commonerrors.CategoryExternal:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func IgnoreErrors(classifier ErrorClassifier, ignored ...error) ErrorClassifier {
+	if classifier == nil {
+		classifier = DefaultErrorClassifier
+	}
+	return func(err error) bool {
+		for _, target := range ignored {
+			if errors.Is(err, target) {
+				return false
+			}
+		}
+		return classifier(err)
+	}
+}
+
+type StateChangeFunc func(name string, from, to State)
+
 type CircuitBreaker struct {
-	failures    atomic.Int32
-	lastFailure atomic.Value
-	threshold   int32
-	timeout     time.Duration
-	resetAfter  time.Duration
-	name        string
-	log         *logger.Logger
+	mu               sync.Mutex
+	state            State
+	openedAt         time.Time
+	halfOpenInFlight int32
+	halfOpenSuccess  int32
+	window           *slidingWindow
+	threshold        int32
+	failureRate      float64
+	minCalls         int32
+	halfOpenMaxCalls int32
+	timeout          time.Duration
+	resetAfter       time.Duration
+	classifier       ErrorClassifier
+	onStateChange    []StateChangeFunc
+	clock            clock.Clock
+	name             string
+	log              *logger.Logger
 }
 
 type CircuitBreakerConfig struct {
-	Threshold  int32
-	Timeout    time.Duration
-	ResetAfter time.Duration
-	Name       string
-	Logger     *logger.Logger
+	Threshold        int32
+	Timeout          time.Duration
+	ResetAfter       time.Duration
+	Window           time.Duration
+	FailureRate      float64
+	MinCalls         int32
+	HalfOpenMaxCalls int32
+	Classifier       ErrorClassifier
+	OnStateChange    StateChangeFunc
+	Clock            clock.Clock
+	Name             string
+	Logger           *logger.Logger
 }
 
 func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	timeClock := config.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	window := config.Window
+	if window <= 0 {
+		window = constants.DefaultCircuitBreakerWindow
+	}
+	failureRate := config.FailureRate
+	if failureRate <= 0 {
+		failureRate = constants.DefaultCircuitBreakerFailureRate
+	}
+	minCalls := config.MinCalls
+	if minCalls <= 0 {
+		minCalls = constants.DefaultCircuitBreakerMinCalls
+	}
+	halfOpenMaxCalls := config.HalfOpenMaxCalls
+	if halfOpenMaxCalls <= 0 {
+		halfOpenMaxCalls = constants.DefaultCircuitBreakerHalfOpenCalls
+	}
+	classifier := config.Classifier
+	if classifier == nil {
+		classifier = DefaultErrorClassifier
+	}
+
 	breaker := &CircuitBreaker{
-		threshold:  config.Threshold,
-		timeout:    config.Timeout,
-		resetAfter: config.ResetAfter,
-		name:       config.Name,
-		log:        config.Logger,
+		state:            StateClosed,
+		window:           newSlidingWindow(window, constants.CircuitBreakerWindowBuckets),
+		threshold:        config.Threshold,
+		failureRate:      failureRate,
+		minCalls:         minCalls,
+		halfOpenMaxCalls: halfOpenMaxCalls,
+		timeout:          config.Timeout,
+		resetAfter:       config.ResetAfter,
+		classifier:       classifier,
+		clock:            timeClock,
+		name:             config.Name,
+		log:              config.Logger,
 	}
-	breaker.lastFailure.Store(time.Time{})
+	if config.OnStateChange != nil {
+		breaker.onStateChange = append(breaker.onStateChange, config.OnStateChange)
+	}
+	breaker.setStateMetric(StateClosed)
 	return breaker
 }
 
+func (breaker *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.onStateChange = append(breaker.onStateChange, fn)
+}
+
+func (breaker *CircuitBreaker) State() State {
+	breaker.mu.Lock()
+	transition := breaker.advanceLocked(breaker.clock.Now())
+	state := breaker.state
+	breaker.mu.Unlock()
+	breaker.notify(transition)
+	return state
+}
+
 func (breaker *CircuitBreaker) IsOpen() bool {
-	if breaker.failures.Load() < breaker.threshold {
-		breaker.setState(0)
-		return false
-	}
+	return breaker.State() == StateOpen
+}
 
-	lastFailure := breaker.lastFailure.Load().(time.Time)
-	if lastFailure.IsZero() {
-		breaker.setState(0)
-		return false
+type stateTransition struct {
+	from      State
+	to        State
+	callbacks []StateChangeFunc
+}
+
+func (breaker *CircuitBreaker) transitionLocked(to State, now time.Time) *stateTransition {
+	from := breaker.state
+	if from == to {
+		return nil
 	}
+	breaker.state = to
+	switch to {
+	case StateOpen:
+		breaker.openedAt = now
+	case StateHalfOpen:
+		breaker.halfOpenInFlight = 0
+		breaker.halfOpenSuccess = 0
+	case StateClosed:
+		breaker.window.reset()
+	}
+	return &stateTransition{
+		from:      from,
+		to:        to,
+		callbacks: append([]StateChangeFunc(nil), breaker.onStateChange...),
+	}
+}
 
-	if time.Since(lastFailure) > breaker.resetAfter {
-		breaker.reset()
-		breaker.setState(0)
-		return false
+func (breaker *CircuitBreaker) advanceLocked(now time.Time) *stateTransition {
+	if breaker.state == StateOpen && now.Sub(breaker.openedAt) >= breaker.resetAfter {
+		return breaker.transitionLocked(StateHalfOpen, now)
 	}
+	return nil
+}
 
-	breaker.setState(1)
-	return true
+func (breaker *CircuitBreaker) notify(transition *stateTransition) {
+	if transition == nil {
+		return
+	}
+	breaker.setStateMetric(transition.to)
+	if breaker.name != "" {
+		metrics.CircuitBreakerTransitions.WithLabelValues(breaker.name, transition.from.String(), transition.to.String()).Inc()
+	}
+	if breaker.log != nil {
+		breaker.log.Warnf("circuit breaker [%s]: state changed %s -> %s", breaker.name, transition.from, transition.to)
+	}
+	for _, fn := range transition.callbacks {
+		fn(breaker.name, transition.from, transition.to)
+	}
 }
 
-func (breaker *CircuitBreaker) setState(state float64) {
+func (breaker *CircuitBreaker) setStateMetric(state State) {
 	if breaker.name != "" {
-		metrics.CircuitBreakerState.WithLabelValues(breaker.name).Set(state)
+		metrics.CircuitBreakerState.WithLabelValues(breaker.name).Set(float64(state))
 	}
 }
 
-func (breaker *CircuitBreaker) recordFailure() {
-	breaker.failures.Add(1)
-	breaker.lastFailure.Store(time.Now())
-	if breaker.log != nil {
+func (breaker *CircuitBreaker) acquire() (State, bool) {
+	breaker.mu.Lock()
+	transition := breaker.advanceLocked(breaker.clock.Now())
+	state := breaker.state
+	allowed := true
+	switch state {
+	case StateOpen:
+		allowed = false
+	case StateHalfOpen:
+		if breaker.halfOpenInFlight+breaker.halfOpenSuccess >= breaker.halfOpenMaxCalls {
+			allowed = false
+		} else {
+			breaker.halfOpenInFlight++
+		}
+	}
+	breaker.mu.Unlock()
+	breaker.notify(transition)
+	return state, allowed
+}
+
+func (breaker *CircuitBreaker) record(state State, failed bool) {
+	now := breaker.clock.Now()
+	breaker.mu.Lock()
+	var transition *stateTransition
+	if state == StateHalfOpen && breaker.state == StateHalfOpen {
+		breaker.halfOpenInFlight--
+		if failed {
+			transition = breaker.transitionLocked(StateOpen, now)
+		} else {
+			breaker.halfOpenSuccess++
+			if breaker.halfOpenSuccess >= breaker.halfOpenMaxCalls {
+				transition = breaker.transitionLocked(StateClosed, now)
+			}
+		}
+	} else if breaker.state == StateClosed {
+		breaker.window.record(now, failed)
+		if failed && breaker.shouldTripLocked(now) {
+			transition = breaker.transitionLocked(StateOpen, now)
+		}
+	}
+	breaker.mu.Unlock()
+
+	if failed && breaker.log != nil {
 		breaker.log.Warnf("circuit breaker [%s]: failure recorded", breaker.name)
 	}
+	breaker.notify(transition)
 }
 
-func (breaker *CircuitBreaker) reset() {
-	breaker.failures.Store(0)
-	breaker.lastFailure.Store(time.Time{})
+func (breaker *CircuitBreaker) shouldTripLocked(now time.Time) bool {
+	calls, failures := breaker.window.totals(now)
+	if breaker.threshold > 0 && failures >= breaker.threshold {
+		return true
+	}
+	return calls >= breaker.minCalls && float64(failures)/float64(calls) >= breaker.failureRate
 }
 
 func (breaker *CircuitBreaker) Call(ctx context.Context, fn func(context.Context) error) error {
 	return breaker.CallWithFallback(ctx, fn, nil)
 }
 
-func (breaker *CircuitBreaker) CallWithFallback(ctx context.Context, fn func(context.Context) error, fallback func() error) error {
-	if breaker.IsOpen() {
+func (breaker *CircuitBreaker) CallWithFallback(ctx context.Context, fn func(context.Context) error, fallback func() error) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "circuit_breaker.call", trace.SpanKindInternal,
+		attribute.String("circuit_breaker.name", breaker.name),
+	)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	state, allowed := breaker.acquire()
+	span.SetAttributes(attribute.String("circuit_breaker.state", state.String()))
+	if !allowed {
+		if breaker.name != "" {
+			metrics.CircuitBreakerRejected.WithLabelValues(breaker.name).Inc()
+		}
 		if breaker.log != nil {
 			if fallback != nil {
-				breaker.log.Warnf("circuit breaker [%s]: circuit is open, using fallback", breaker.name)
+				breaker.log.Warnf("circuit breaker [%s]: circuit is %s, using fallback", breaker.name, state)
 			} else {
-				breaker.log.Warnf("circuit breaker [%s]: circuit is open, rejecting request", breaker.name)
+				breaker.log.Warnf("circuit breaker [%s]: circuit is %s, rejecting request", breaker.name, state)
 			}
 		}
 		if fallback != nil {
@@ -110,11 +324,9 @@ func (breaker *CircuitBreaker) CallWithFallback(ctx context.Context, fn func(con
 	callCtx, cancel := context.WithTimeout(ctx, breaker.timeout)
 	defer cancel()
 
-	err := fn(callCtx)
+	err = fn(callCtx)
+	breaker.record(state, breaker.classifier(err))
 	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			breaker.recordFailure()
-		}
 		if fallback != nil {
 			if breaker.log != nil {
 				breaker.log.Infof("circuit breaker [%s]: operation failed, using fallback", breaker.name)
@@ -123,7 +335,5 @@ func (breaker *CircuitBreaker) CallWithFallback(ctx context.Context, fn func(con
 		}
 		return err
 	}
-
-	breaker.reset()
 	return nil
 }
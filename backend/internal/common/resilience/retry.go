@@ -0,0 +1,203 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type SleepFunc func(ctx context.Context, delay time.Duration) error
+
+type RetryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	retryable      ErrorClassifier
+	sleep          SleepFunc
+	name           string
+	log            *logger.Logger
+}
+
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	Retryable      ErrorClassifier
+	Sleep          SleepFunc
+	Name           string
+	Logger         *logger.Logger
+}
+
+func NewRetryPolicy(config RetryConfig) *RetryPolicy {
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = constants.DefaultRetryMaxAttempts
+	}
+	initialBackoff := config.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = constants.DefaultRetryInitialBackoff
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = constants.DefaultRetryMaxBackoff
+	}
+	multiplier := config.Multiplier
+	if multiplier < 1 {
+		multiplier = constants.DefaultRetryMultiplier
+	}
+	jitter := config.Jitter
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	retryable := config.Retryable
+	if retryable == nil {
+		retryable = DefaultErrorClassifier
+	}
+	sleep := config.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	return &RetryPolicy{
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		multiplier:     multiplier,
+		jitter:         jitter,
+		retryable:      retryable,
+		sleep:          sleep,
+		name:           config.Name,
+		log:            config.Logger,
+	}
+}
+
+func (p *RetryPolicy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(p.initialBackoff) * math.Pow(p.multiplier, float64(attempt-1))
+	if delay > float64(p.maxBackoff) {
+		delay = float64(p.maxBackoff)
+	}
+	if p.jitter > 0 {
+		delay -= delay * p.jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+func (p *RetryPolicy) Do(ctx context.Context, fn func(context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				p.observe("recovered")
+			}
+			return nil
+		}
+		if !p.shouldRetry(ctx, err) {
+			if attempt > 1 {
+				p.observe("failed")
+			}
+			return err
+		}
+		if attempt >= p.maxAttempts {
+			p.observe("exhausted")
+			return err
+		}
+
+		delay := p.Backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			p.observe("aborted")
+			return err
+		}
+
+		p.observe("retried")
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.String("retry.name", p.name),
+			attribute.Int("retry.attempt", attempt),
+			attribute.Int64("retry.backoff_ms", delay.Milliseconds()),
+		))
+		if p.log != nil {
+			p.log.WithFields(ctx, logger.Fields{
+				"retry":      p.name,
+				"attempt":    attempt,
+				"backoff_ms": delay.Milliseconds(),
+				"action":     "retry_transient_error",
+			}).Warnf("retrying after transient error: %v", err)
+		}
+
+		if sleepErr := p.sleep(ctx, delay); sleepErr != nil {
+			p.observe("aborted")
+			return fmt.Errorf("retry aborted after %d attempts: %w (last error: %w)", attempt, sleepErr, err)
+		}
+	}
+}
+
+func (p *RetryPolicy) Call(ctx context.Context, fn func(context.Context) error) error {
+	return p.Do(ctx, fn)
+}
+
+func (p *RetryPolicy) Around(breaker CircuitBreakerInterface) CircuitBreakerInterface {
+	return &retryingBreaker{policy: p, breaker: breaker}
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, commonerrors.ErrCircuitOpen) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return p.retryable(err)
+}
+
+func (p *RetryPolicy) observe(outcome string) {
+	if p.name != "" {
+		metrics.RetryAttemptsTotal.WithLabelValues(p.name, outcome).Inc()
+	}
+}
+
+type retryingBreaker struct {
+	policy  *RetryPolicy
+	breaker CircuitBreakerInterface
+}
+
+func (r *retryingBreaker) Call(ctx context.Context, fn func(context.Context) error) error {
+	return r.policy.Do(ctx, func(ctx context.Context) error {
+		return r.breaker.Call(ctx, fn)
+	})
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
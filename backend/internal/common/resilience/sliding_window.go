@@ -0,0 +1,62 @@
+package resilience
+
+import "time"
+
+type windowBucket struct {
+	start     time.Time
+	successes int32
+	failures  int32
+}
+
+type slidingWindow struct {
+	buckets   []windowBucket
+	bucketLen time.Duration
+	window    time.Duration
+}
+
+func newSlidingWindow(window time.Duration, buckets int) *slidingWindow {
+	if buckets <= 0 {
+		buckets = 1
+	}
+	bucketLen := window / time.Duration(buckets)
+	if bucketLen <= 0 {
+		bucketLen = window
+	}
+	return &slidingWindow{
+		buckets:   make([]windowBucket, buckets),
+		bucketLen: bucketLen,
+		window:    window,
+	}
+}
+
+func (w *slidingWindow) record(now time.Time, failed bool) {
+	start := now.Truncate(w.bucketLen)
+	bucket := &w.buckets[(start.UnixNano()/int64(w.bucketLen))%int64(len(w.buckets))]
+	if !bucket.start.Equal(start) {
+		*bucket = windowBucket{start: start}
+	}
+	if failed {
+		bucket.failures++
+	} else {
+		bucket.successes++
+	}
+}
+
+func (w *slidingWindow) totals(now time.Time) (int32, int32) {
+	cutoff := now.Add(-w.window)
+	var calls, failures int32
+	for _, bucket := range w.buckets {
+		if bucket.start.IsZero() || !bucket.start.After(cutoff) {
+			continue
+		}
+		calls += bucket.successes + bucket.failures
+		failures += bucket.failures
+	}
+	return calls, failures
+}
+
+func (w *slidingWindow) reset() {
+	for i := range w.buckets {
+		w.buckets[i] = windowBucket{}
+	}
+}
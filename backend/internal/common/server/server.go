@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 
@@ -74,6 +75,17 @@ func WaitGroupWithTimeout(ctx context.Context, wg *sync.WaitGroup, log *logger.L
 	}
 }
 
+func WaitForDrain(ctx context.Context, delay time.Duration, log *logger.Logger, serviceName string) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		log.Infof("%s service: readiness drain period elapsed", serviceName)
+	case <-ctx.Done():
+		log.Warnf("%s service: readiness drain interrupted", serviceName)
+	}
+}
+
 func ClosePoolWithTimeout(ctx context.Context, pool *pgxpool.Pool, log *logger.Logger, serviceName string) {
 	poolCloseCtx, poolCloseCancel := context.WithTimeout(ctx, constants.DBPoolCloseTimeout)
 	defer poolCloseCancel()
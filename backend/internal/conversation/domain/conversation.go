@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+type Conversation struct {
+	OwnerID        string
+	PeerID         string
+	LastActivityAt time.Time
+	UnreadCount    int
+	Muted          bool
+	Archived       bool
+	Pinned         bool
+}
+
+type Activity struct {
+	OwnerID     string
+	PeerID      string
+	At          time.Time
+	Unread      int
+	ResetUnread bool
+}
+
+type Update struct {
+	Muted    *bool
+	Archived *bool
+	Pinned   *bool
+	MarkRead bool
+}
+
+func (u Update) Empty() bool {
+	return u.Muted == nil && u.Archived == nil && u.Pinned == nil && !u.MarkRead
+}
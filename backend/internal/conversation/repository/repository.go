@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+)
+
+var ErrConversationNotFound = pgx.ErrNoRows
+
+type Repository interface {
+	ApplyActivity(ctx context.Context, activity []domain.Activity) error
+	List(ctx context.Context, ownerID string, archived bool, limit int) ([]domain.Conversation, error)
+	Update(ctx context.Context, ownerID, peerID string, update domain.Update) (domain.Conversation, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("conversation_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) ApplyActivity(ctx context.Context, activity []domain.Activity) error {
+	var resetOwners, resetPeers []string
+	var owners, peers []string
+	var activeAt []time.Time
+	var unread []int32
+	for _, a := range activity {
+		if a.ResetUnread {
+			resetOwners = append(resetOwners, a.OwnerID)
+			resetPeers = append(resetPeers, a.PeerID)
+		}
+		if !a.At.IsZero() {
+			owners = append(owners, a.OwnerID)
+			peers = append(peers, a.PeerID)
+			activeAt = append(activeAt, a.At)
+			unread = append(unread, int32(a.Unread))
+		}
+	}
+	if len(resetOwners) == 0 && len(owners) == 0 {
+		return nil
+	}
+
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		return r.applyActivity(ctx, resetOwners, resetPeers, owners, peers, activeAt, unread)
+	})
+}
+
+func (r *PgRepository) applyActivity(ctx context.Context, resetOwners, resetPeers, owners, peers []string, activeAt []time.Time, unread []int32) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return db.HandleExecError(err, "apply conversation activity", start)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if len(resetOwners) > 0 {
+		if _, err = tx.Exec(
+			ctx,
+			`UPDATE conversations AS c
+			 SET unread_count = 0, updated_at = NOW()
+			 FROM UNNEST($1::text[], $2::text[]) AS r(owner_id, peer_id)
+			 WHERE c.owner_id = r.owner_id::uuid AND c.peer_id = r.peer_id::uuid`,
+			resetOwners,
+			resetPeers,
+		); err != nil {
+			return db.HandleExecError(err, "apply conversation activity", start)
+		}
+	}
+
+	if len(owners) > 0 {
+		if _, err = tx.Exec(
+			ctx,
+			`INSERT INTO conversations (owner_id, peer_id, last_activity_at, unread_count)
+			 SELECT a.owner_id::uuid, a.peer_id::uuid, a.at, a.unread
+			 FROM UNNEST($1::text[], $2::text[], $3::timestamptz[], $4::int[]) AS a(owner_id, peer_id, at, unread)
+			 ON CONFLICT (owner_id, peer_id) DO UPDATE
+			 SET last_activity_at = GREATEST(conversations.last_activity_at, EXCLUDED.last_activity_at),
+			     unread_count = conversations.unread_count + EXCLUDED.unread_count,
+			     updated_at = NOW()`,
+			owners,
+			peers,
+			activeAt,
+			unread,
+		); err != nil {
+			return db.HandleExecError(err, "apply conversation activity", start)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return db.HandleExecError(err, "apply conversation activity", start)
+	}
+	db.MeasureQueryDuration("apply conversation activity", start)
+	return nil
+}
+
+func (r *PgRepository) List(ctx context.Context, ownerID string, archived bool, limit int) ([]domain.Conversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT owner_id, peer_id, last_activity_at, unread_count, muted, archived, pinned
+		 FROM conversations
+		 WHERE owner_id = $1 AND archived = $2
+		 ORDER BY pinned DESC, last_activity_at DESC
+		 LIMIT $3`,
+		ownerID,
+		archived,
+		limit,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list conversations", start)
+	}
+	defer rows.Close()
+
+	conversations := make([]domain.Conversation, 0, limit)
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			return nil, db.HandleQueryError(err, nil, "list conversations", start)
+		}
+		conversations = append(conversations, conversation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list conversations", start)
+	}
+
+	db.MeasureQueryDuration("list conversations", start)
+	return conversations, nil
+}
+
+func (r *PgRepository) Update(ctx context.Context, ownerID, peerID string, update domain.Update) (domain.Conversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	conversation, err := scanConversation(r.pool.QueryRow(
+		ctx,
+		`UPDATE conversations
+		 SET muted = COALESCE($3::boolean, muted),
+		     archived = COALESCE($4::boolean, archived),
+		     pinned = COALESCE($5::boolean, pinned),
+		     unread_count = CASE WHEN $6::boolean THEN 0 ELSE unread_count END,
+		     updated_at = NOW()
+		 WHERE owner_id = $1 AND peer_id = $2
+		 RETURNING owner_id, peer_id, last_activity_at, unread_count, muted, archived, pinned`,
+		ownerID,
+		peerID,
+		update.Muted,
+		update.Archived,
+		update.Pinned,
+		update.MarkRead,
+	))
+	if err != nil {
+		return domain.Conversation{}, db.HandleQueryError(err, ErrConversationNotFound, "update conversation", start)
+	}
+
+	db.MeasureQueryDuration("update conversation", start)
+	return conversation, nil
+}
+
+func scanConversation(row pgx.Row) (domain.Conversation, error) {
+	var conversation domain.Conversation
+	err := row.Scan(
+		&conversation.OwnerID,
+		&conversation.PeerID,
+		&conversation.LastActivityAt,
+		&conversation.UnreadCount,
+		&conversation.Muted,
+		&conversation.Archived,
+		&conversation.Pinned,
+	)
+	return conversation, err
+}
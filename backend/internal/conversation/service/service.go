@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+	conversationrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/repository"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type Service interface {
+	RecordMessage(senderID, recipientID string)
+	MarkRead(userID, peerID string)
+	List(ctx context.Context, userID string, archived bool, limit int) ([]domain.Conversation, error)
+	Update(ctx context.Context, userID, peerID string, update domain.Update) (domain.Conversation, error)
+	Stop()
+}
+
+type conversationKey struct {
+	ownerID string
+	peerID  string
+}
+
+type ConversationService struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	repo   conversationrepo.Repository
+	clock  clock.Clock
+	log    *logger.Logger
+	queue  chan domain.Activity
+	wg     sync.WaitGroup
+}
+
+type ConversationServiceDeps struct {
+	Repo  conversationrepo.Repository
+	Clock clock.Clock
+	Log   *logger.Logger
+}
+
+func NewConversationService(ctx context.Context, deps ConversationServiceDeps) *ConversationService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+
+	serviceCtx, cancel := context.WithCancel(ctx)
+	s := &ConversationService{
+		ctx:    serviceCtx,
+		cancel: cancel,
+		repo:   deps.Repo,
+		clock:  timeClock,
+		log:    deps.Log,
+		queue:  make(chan domain.Activity, constants.ConversationQueueSize),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+func (s *ConversationService) RecordMessage(senderID, recipientID string) {
+	if senderID == "" || recipientID == "" || senderID == recipientID {
+		return
+	}
+	now := s.clock.Now()
+	s.enqueue(domain.Activity{OwnerID: senderID, PeerID: recipientID, At: now})
+	s.enqueue(domain.Activity{OwnerID: recipientID, PeerID: senderID, At: now, Unread: 1})
+}
+
+func (s *ConversationService) MarkRead(userID, peerID string) {
+	if userID == "" || peerID == "" || userID == peerID {
+		return
+	}
+	s.enqueue(domain.Activity{OwnerID: userID, PeerID: peerID, ResetUnread: true})
+}
+
+func (s *ConversationService) List(ctx context.Context, userID string, archived bool, limit int) ([]domain.Conversation, error) {
+	if limit <= 0 || limit > constants.MaxConversationListLimit {
+		limit = constants.DefaultConversationListLimit
+	}
+
+	conversations, err := s.repo.List(ctx, userID, archived, limit)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "conversations_list_failed",
+		}).Errorf("failed to list conversations: %v", err)
+		return nil, commonerrors.ErrConversationsListFailed.WithCause(err)
+	}
+	return conversations, nil
+}
+
+func (s *ConversationService) Update(ctx context.Context, userID, peerID string, update domain.Update) (domain.Conversation, error) {
+	conversation, err := s.repo.Update(ctx, userID, peerID, update)
+	if errors.Is(err, conversationrepo.ErrConversationNotFound) {
+		return domain.Conversation{}, commonerrors.ErrConversationNotFound.WithCause(err)
+	}
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"peer_id": peerID,
+			"action":  "conversation_update_failed",
+		}).Errorf("failed to update conversation: %v", err)
+		return domain.Conversation{}, commonerrors.ErrConversationUpdateFailed.WithCause(err)
+	}
+	return conversation, nil
+}
+
+func (s *ConversationService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *ConversationService) enqueue(activity domain.Activity) {
+	select {
+	case s.queue <- activity:
+	default:
+		metrics.ChatConversationUpdates.WithLabelValues("dropped").Inc()
+		s.log.WithFields(context.Background(), logger.Fields{
+			"user_id": activity.OwnerID,
+			"peer_id": activity.PeerID,
+			"action":  "conversation_enqueue_dropped",
+		}).Warn("conversation queue is full, dropping update")
+	}
+}
+
+func (s *ConversationService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(constants.ConversationFlushEvery)
+	defer ticker.Stop()
+
+	pending := make(map[conversationKey]*domain.Activity)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			for {
+				select {
+				case activity := <-s.queue:
+					merge(pending, activity)
+				default:
+					s.flush(pending)
+					return
+				}
+			}
+		case activity := <-s.queue:
+			merge(pending, activity)
+			if len(pending) >= constants.ConversationBatchSize {
+				s.flush(pending)
+			}
+		case <-ticker.C:
+			s.flush(pending)
+		}
+	}
+}
+
+func merge(pending map[conversationKey]*domain.Activity, activity domain.Activity) {
+	key := conversationKey{ownerID: activity.OwnerID, peerID: activity.PeerID}
+	existing, ok := pending[key]
+	if !ok {
+		pending[key] = &activity
+		return
+	}
+	if activity.ResetUnread {
+		existing.ResetUnread = true
+		existing.Unread = 0
+	}
+	existing.Unread += activity.Unread
+	if activity.At.After(existing.At) {
+		existing.At = activity.At
+	}
+}
+
+func (s *ConversationService) flush(pending map[conversationKey]*domain.Activity) {
+	if len(pending) == 0 {
+		return
+	}
+
+	activity := make([]domain.Activity, 0, len(pending))
+	for _, a := range pending {
+		activity = append(activity, *a)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ConversationWriteTimeout)
+	defer cancel()
+
+	outcome := "recorded"
+	if err := s.repo.ApplyActivity(ctx, activity); err != nil {
+		outcome = "failed"
+		s.log.WithFields(ctx, logger.Fields{
+			"count":  len(activity),
+			"action": "conversation_batch_failed",
+		}).Warnf("failed to persist conversation batch: %v", err)
+	}
+	metrics.ChatConversationUpdates.WithLabelValues(outcome).Add(float64(len(activity)))
+
+	for key := range pending {
+		delete(pending, key)
+	}
+}
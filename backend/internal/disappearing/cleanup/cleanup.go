@@ -0,0 +1,48 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type ExpiredDeleter interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+func StartCleanup(ctx context.Context, repo ExpiredDeleter, log *logger.Logger, kind string) {
+	ticker := time.NewTicker(constants.DisappearingReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				log.Errorf("%s disappearing cleanup failed: %v", kind, err)
+				continue
+			}
+			if deleted > 0 {
+				metrics.ChatDisappearingExpired.WithLabelValues(kind).Add(float64(deleted))
+				log.Infof("%s disappearing cleanup: removed %d expired entries", kind, deleted)
+			}
+		}
+	}
+}
+
+func StartReceiptCleanup(ctx context.Context, repo ExpiredDeleter, log *logger.Logger) {
+	StartCleanup(ctx, repo, log, "receipt")
+}
+
+func StartReactionCleanup(ctx context.Context, repo ExpiredDeleter, log *logger.Logger) {
+	StartCleanup(ctx, repo, log, "reaction")
+}
+
+func StartFileTransferCleanup(ctx context.Context, files ExpiredDeleter, log *logger.Logger) {
+	StartCleanup(ctx, files, log, "file_transfer")
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type Timer struct {
+	UserID    string
+	PeerID    string
+	TTL       time.Duration
+	UpdatedBy string
+}
+
+func Pair(userID, peerID string) (string, string) {
+	userID, peerID = strings.ToLower(userID), strings.ToLower(peerID)
+	if userID < peerID {
+		return userID, peerID
+	}
+	return peerID, userID
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/domain"
+)
+
+type Repository interface {
+	GetTTL(ctx context.Context, userID, peerID string) (time.Duration, error)
+	SaveTimer(ctx context.Context, timer domain.Timer) error
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("disappearing_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) GetTTL(ctx context.Context, userID, peerID string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	userA, userB := domain.Pair(userID, peerID)
+	var seconds int64
+	start := time.Now()
+	err := r.pool.QueryRow(
+		ctx,
+		`SELECT ttl_seconds FROM disappearing_timers WHERE user_a = $1 AND user_b = $2`,
+		userA,
+		userB,
+	).Scan(&seconds)
+	if errors.Is(err, pgx.ErrNoRows) {
+		db.MeasureQueryDuration("get disappearing timer", start)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, db.HandleQueryError(err, nil, "get disappearing timer", start)
+	}
+
+	db.MeasureQueryDuration("get disappearing timer", start)
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func (r *PgRepository) SaveTimer(ctx context.Context, timer domain.Timer) error {
+	userA, userB := domain.Pair(timer.UserID, timer.PeerID)
+
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`INSERT INTO disappearing_timers (user_a, user_b, ttl_seconds, updated_by, updated_at)
+			 VALUES ($1, $2, $3, $4, NOW())
+			 ON CONFLICT (user_a, user_b) DO UPDATE
+			 SET ttl_seconds = EXCLUDED.ttl_seconds,
+			     updated_by = EXCLUDED.updated_by,
+			     updated_at = NOW()`,
+			userA,
+			userB,
+			int64(timer.TTL/time.Second),
+			timer.UpdatedBy,
+		)
+		return db.HandleExecError(err, "save disappearing timer", start)
+	})
+}
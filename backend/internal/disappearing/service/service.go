@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/domain"
+	disappearingrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/repository"
+)
+
+type Service interface {
+	TTL(ctx context.Context, userID, peerID string) (time.Duration, error)
+	SetTTL(ctx context.Context, userID, peerID string, ttl time.Duration) error
+}
+
+type timerKey struct {
+	userA string
+	userB string
+}
+
+type timerCacheEntry struct {
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+type TimerService struct {
+	repo  disappearingrepo.Repository
+	clock clock.Clock
+	log   *logger.Logger
+	cache sync.Map
+}
+
+type TimerServiceDeps struct {
+	Repo  disappearingrepo.Repository
+	Clock clock.Clock
+	Log   *logger.Logger
+}
+
+func NewTimerService(deps TimerServiceDeps) *TimerService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+
+	return &TimerService{
+		repo:  deps.Repo,
+		clock: timeClock,
+		log:   deps.Log,
+	}
+}
+
+func (s *TimerService) TTL(ctx context.Context, userID, peerID string) (time.Duration, error) {
+	key := newTimerKey(userID, peerID)
+	if cached, ok := s.cache.Load(key); ok {
+		entry := cached.(*timerCacheEntry)
+		if s.clock.Now().Before(entry.expiresAt) {
+			return entry.ttl, nil
+		}
+		s.cache.Delete(key)
+	}
+
+	ttl, err := s.repo.GetTTL(ctx, userID, peerID)
+	if err != nil {
+		return 0, commonerrors.ErrDisappearingTimerFailed.WithCause(err)
+	}
+	s.store(key, ttl)
+	return ttl, nil
+}
+
+func (s *TimerService) SetTTL(ctx context.Context, userID, peerID string, ttl time.Duration) error {
+	if ttl != 0 && (ttl < constants.MinDisappearingTTL || ttl > constants.MaxDisappearingTTL) {
+		return commonerrors.ErrInvalidDisappearingTimer
+	}
+
+	timer := domain.Timer{UserID: userID, PeerID: peerID, TTL: ttl, UpdatedBy: userID}
+	if err := s.repo.SaveTimer(ctx, timer); err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"peer_id": peerID,
+			"action":  "disappearing_timer_save_failed",
+		}).Errorf("failed to save disappearing timer: %v", err)
+		return commonerrors.ErrDisappearingTimerFailed.WithCause(err)
+	}
+	s.store(newTimerKey(userID, peerID), ttl)
+	return nil
+}
+
+func (s *TimerService) StartCleanup(ctx context.Context) {
+	ticker := time.NewTicker(constants.DisappearingTimerCacheTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := s.clock.Now()
+			s.cache.Range(func(key, value interface{}) bool {
+				if now.After(value.(*timerCacheEntry).expiresAt) {
+					s.cache.Delete(key)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (s *TimerService) store(key timerKey, ttl time.Duration) {
+	s.cache.Store(key, &timerCacheEntry{
+		ttl:       ttl,
+		expiresAt: s.clock.Now().Add(constants.DisappearingTimerCacheTTL),
+	})
+}
+
+func newTimerKey(userID, peerID string) timerKey {
+	userA, userB := domain.Pair(userID, peerID)
+	return timerKey{userA: userA, userB: userB}
+}
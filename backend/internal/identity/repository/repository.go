@@ -9,6 +9,7 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/domain"
 )
 
@@ -19,11 +20,15 @@ type Repository interface {
 }
 
 type PgRepository struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
 }
 
 func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
-	return &PgRepository{pool: pool}
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("identity_repository", db.IsTransientError),
+	}
 }
 
 func (r *PgRepository) Create(ctx context.Context, key domain.IdentityKey) error {
@@ -41,40 +46,45 @@ func (r *PgRepository) Create(ctx context.Context, key domain.IdentityKey) error
 }
 
 func (r *PgRepository) FindByUserID(ctx context.Context, userID string) (domain.IdentityKey, error) {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
+	var key domain.IdentityKey
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
 
-	start := time.Now()
-	row := r.pool.QueryRow(
-		ctx,
-		`SELECT user_id, public_key, created_at FROM identity_keys WHERE user_id = $1`,
-		userID,
-	)
+		start := time.Now()
+		row := r.pool.QueryRow(
+			ctx,
+			`SELECT user_id, public_key, created_at FROM identity_keys WHERE user_id = $1`,
+			userID,
+		)
 
-	var key domain.IdentityKey
-	err := row.Scan(&key.UserID, &key.PublicKey, &key.CreatedAt)
-	if err := db.HandleQueryError(err, commonerrors.ErrIdentityKeyNotFound, "find identity key", start); err != nil {
+		err := row.Scan(&key.UserID, &key.PublicKey, &key.CreatedAt)
+		return db.HandleQueryError(err, commonerrors.ErrIdentityKeyNotFound, "find identity key", start)
+	})
+	if err != nil {
 		return domain.IdentityKey{}, err
 	}
 	return key, nil
 }
 
 func (r *PgRepository) Update(ctx context.Context, userID string, publicKey []byte) error {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
 
-	start := time.Now()
-	result, err := r.pool.Exec(
-		ctx,
-		`UPDATE identity_keys SET public_key = $2 WHERE user_id = $1`,
-		userID,
-		publicKey,
-	)
-	if err != nil {
-		return db.HandleExecError(err, "update identity key", start)
-	}
-	if result.RowsAffected() == 0 {
-		return commonerrors.ErrIdentityKeyNotFound
-	}
-	return nil
+		start := time.Now()
+		result, err := r.pool.Exec(
+			ctx,
+			`UPDATE identity_keys SET public_key = $2 WHERE user_id = $1`,
+			userID,
+			publicKey,
+		)
+		if err != nil {
+			return db.HandleExecError(err, "update identity key", start)
+		}
+		if result.RowsAffected() == 0 {
+			return commonerrors.ErrIdentityKeyNotFound
+		}
+		return nil
+	})
 }
@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"errors"
 
+	auditdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	auditservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/service"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	identitydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/domain"
@@ -21,19 +23,26 @@ type Service interface {
 }
 
 type IdentityService struct {
-	repo identityrepo.Repository
-	log  *logger.Logger
+	repo  identityrepo.Repository
+	audit auditservice.Service
+	log   *logger.Logger
 }
 
 type IdentityServiceDeps struct {
-	Repo identityrepo.Repository
-	Log  *logger.Logger
+	Repo  identityrepo.Repository
+	Audit auditservice.Service
+	Log   *logger.Logger
 }
 
 func NewIdentityService(deps IdentityServiceDeps) *IdentityService {
+	auditService := deps.Audit
+	if auditService == nil {
+		auditService = auditservice.NopService{}
+	}
 	return &IdentityService{
-		repo: deps.Repo,
-		log:  deps.Log,
+		repo:  deps.Repo,
+		audit: auditService,
+		log:   deps.Log,
 	}
 }
 
@@ -72,6 +81,7 @@ func (s *IdentityService) CreateIdentityKey(ctx context.Context, userID string,
 		"user_id": userID,
 		"action":  "identity_key_created",
 	}).Info("identity key created")
+	s.recordKeyEvent(ctx, auditdomain.EventIdentityKeyCreated, userID, publicKey)
 	return nil
 }
 
@@ -110,6 +120,7 @@ func (s *IdentityService) UpdatePublicKey(ctx context.Context, userID string, pu
 				"user_id": userID,
 				"action":  "identity_key_created_on_update",
 			}).Info("identity key created (no existing row)")
+			s.recordKeyEvent(ctx, auditdomain.EventIdentityKeyChanged, userID, publicKey)
 			return nil
 		}
 		s.log.WithFields(ctx, logger.Fields{
@@ -123,9 +134,21 @@ func (s *IdentityService) UpdatePublicKey(ctx context.Context, userID string, pu
 		"user_id": userID,
 		"action":  "identity_key_updated",
 	}).Info("identity key updated")
+	s.recordKeyEvent(ctx, auditdomain.EventIdentityKeyChanged, userID, publicKey)
 	return nil
 }
 
+func (s *IdentityService) recordKeyEvent(ctx context.Context, eventType auditdomain.EventType, userID string, publicKey []byte) {
+	hash := sha256.Sum256(publicKey)
+	s.audit.Record(ctx, auditdomain.Event{
+		UserID: userID,
+		Type:   eventType,
+		Metadata: map[string]string{
+			"key_sha256": hex.EncodeToString(hash[:]),
+		},
+	})
+}
+
 func (s *IdentityService) GetPublicKey(ctx context.Context, userID string) ([]byte, error) {
 	s.log.WithFields(ctx, logger.Fields{
 		"user_id": userID,
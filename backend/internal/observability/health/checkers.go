@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+)
+
+var (
+	ErrCircuitOpen       = errors.New("circuit breaker is open")
+	ErrHeartbeatStale    = errors.New("heartbeat is stale")
+	ErrHeartbeatMissing  = errors.New("no heartbeat recorded")
+	ErrQueueSaturated    = errors.New("queue is saturated")
+	ErrPoolNotConfigured = errors.New("database pool is not configured")
+)
+
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("check panicked: %v", e.value)
+}
+
+type Heartbeater interface {
+	LastHeartbeat() time.Time
+}
+
+type QueueGauge interface {
+	QueueLen() int
+	QueueCapacity() int
+}
+
+func PoolChecker(pool *pgxpool.Pool) Checker {
+	return NewChecker("postgres", func(ctx context.Context) error {
+		if pool == nil {
+			return ErrPoolNotConfigured
+		}
+		return pool.Ping(ctx)
+	})
+}
+
+func CircuitBreakerChecker(name string, isOpen func() bool) Checker {
+	return NewChecker("circuit_breaker:"+name, func(ctx context.Context) error {
+		if isOpen() {
+			return ErrCircuitOpen
+		}
+		return nil
+	})
+}
+
+func HeartbeatChecker(name string, source Heartbeater, maxAge time.Duration, timeClock clock.Clock) Checker {
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	return NewChecker(name, func(ctx context.Context) error {
+		last := source.LastHeartbeat()
+		if last.IsZero() {
+			return ErrHeartbeatMissing
+		}
+		if age := timeClock.Since(last); age > maxAge {
+			return fmt.Errorf("%w: last beat %s ago", ErrHeartbeatStale, age.Round(time.Millisecond))
+		}
+		return nil
+	})
+}
+
+func QueueSaturationChecker(name string, queue QueueGauge, threshold float64) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		capacity := queue.QueueCapacity()
+		if capacity <= 0 {
+			return nil
+		}
+		length := queue.QueueLen()
+		if float64(length)/float64(capacity) >= threshold {
+			return fmt.Errorf("%w: %d/%d", ErrQueueSaturated, length, capacity)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,215 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFail     Status = "fail"
+)
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+func NewChecker(name string, fn func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+type optionalChecker struct {
+	Checker
+}
+
+func Optional(checker Checker) Checker {
+	return optionalChecker{Checker: checker}
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    Status        `json:"status"`
+	Checks    []CheckResult `json:"checks"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+type Registry struct {
+	mu           sync.RWMutex
+	liveness     []Checker
+	readiness    []Checker
+	shuttingDown atomic.Bool
+	checkTimeout time.Duration
+	clock        clock.Clock
+	log          *logger.Logger
+}
+
+type RegistryDeps struct {
+	Clock clock.Clock
+	Log   *logger.Logger
+}
+
+type RegistryConfig struct {
+	CheckTimeout time.Duration
+}
+
+func NewRegistry(deps RegistryDeps, config RegistryConfig) *Registry {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	checkTimeout := config.CheckTimeout
+	if checkTimeout <= 0 {
+		checkTimeout = constants.HealthCheckTimeout
+	}
+	return &Registry{
+		checkTimeout: checkTimeout,
+		clock:        timeClock,
+		log:          deps.Log,
+	}
+}
+
+func (r *Registry) AddLiveness(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, checkers...)
+}
+
+func (r *Registry) AddReadiness(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, checkers...)
+}
+
+func (r *Registry) MarkShuttingDown() {
+	if r.shuttingDown.CompareAndSwap(false, true) && r.log != nil {
+		r.log.WithFields(context.Background(), logger.Fields{
+			"action": "readiness_shutdown",
+		}).Info("readiness probe switched to failing: service is shutting down")
+	}
+}
+
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+func (r *Registry) Liveness(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.liveness...)
+	r.mu.RUnlock()
+	return r.run(ctx, checkers, nil)
+}
+
+func (r *Registry) Readiness(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.readiness...)
+	r.mu.RUnlock()
+
+	var extra []CheckResult
+	if r.ShuttingDown() {
+		extra = append(extra, CheckResult{
+			Name:   "shutdown",
+			Status: StatusFail,
+			Error:  "service is shutting down",
+		})
+	}
+	return r.run(ctx, checkers, extra)
+}
+
+func (r *Registry) run(ctx context.Context, checkers []Checker, extra []CheckResult) Report {
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = r.runCheck(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	results = append(results, extra...)
+	report := Report{
+		Status:    StatusOK,
+		Checks:    results,
+		CheckedAt: r.clock.Now().UTC(),
+	}
+	for _, result := range results {
+		switch result.Status {
+		case StatusFail:
+			report.Status = StatusFail
+		case StatusDegraded:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+	return report
+}
+
+func (r *Registry) runCheck(ctx context.Context, checker Checker) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, r.checkTimeout)
+	defer cancel()
+
+	start := r.clock.Now()
+	err := safeCheck(checkCtx, checker)
+	result := CheckResult{
+		Name:      checker.Name(),
+		Status:    StatusOK,
+		LatencyMs: float64(r.clock.Since(start).Microseconds()) / 1000,
+	}
+	if err == nil {
+		return result
+	}
+
+	result.Error = err.Error()
+	result.Status = StatusFail
+	if _, ok := checker.(optionalChecker); ok {
+		result.Status = StatusDegraded
+	}
+	if r.log != nil {
+		r.log.WithFields(ctx, logger.Fields{
+			"check":  result.Name,
+			"status": string(result.Status),
+			"action": "health_check_failed",
+		}).Warnf("health check failed: %v", err)
+	}
+	return result
+}
+
+func safeCheck(ctx context.Context, checker Checker) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = &panicError{value: rec}
+		}
+	}()
+	return checker.Check(ctx)
+}
@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	AuditEventsRecorded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_recorded_total",
+			Help: "Total number of security audit events recorded",
+		},
+		[]string{"event_type"},
+	)
+
+	AuditEventsFailed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_failed_total",
+			Help: "Total number of security audit events that failed to persist or export",
+		},
+		[]string{"sink"},
+	)
+)
@@ -67,6 +67,22 @@ var (
 		},
 	)
 
+	ChatWebSocketDrainDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_websocket_drain_dropped_total",
+			Help: "Total number of items dropped while draining WebSocket hub on shutdown",
+		},
+		[]string{"kind"},
+	)
+
+	ChatWebSocketDrainDurationSeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "chat_websocket_drain_duration_seconds",
+			Help:    "Duration of WebSocket hub drain on shutdown in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+	)
+
 	ChatWebSocketFileTransferFailures = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "chat_websocket_file_transfer_failures_total",
@@ -91,6 +107,30 @@ var (
 		[]string{"message_type"},
 	)
 
+	ChatWebSocketBinaryFrames = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_websocket_binary_frames_total",
+			Help: "Total number of binary file chunk frames by direction (received, sent, transcoded)",
+		},
+		[]string{"direction"},
+	)
+
+	ChatWebSocketUnsupportedMessages = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_websocket_unsupported_messages_total",
+			Help: "Total number of messages not supported by the recipient by type and outcome (fallback, rejected)",
+		},
+		[]string{"type", "outcome"},
+	)
+
+	ChatWebSocketBackpressureSignals = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_websocket_backpressure_signals_total",
+			Help: "Total number of backpressure signals sent to clients by state",
+		},
+		[]string{"state"},
+	)
+
 	ChatWebSocketConnectionsRejected = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "chat_websocket_connections_rejected_total",
@@ -127,4 +167,75 @@ var (
 		},
 		[]string{"message_type"},
 	)
+
+	ChatReceiptUpdates = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_receipt_updates_total",
+			Help: "Total number of delivery and read receipt updates by status and outcome",
+		},
+		[]string{"status", "outcome"},
+	)
+
+	ChatMessageUpdates = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_message_updates_total",
+			Help: "Total number of message edit and delete authorizations by kind and outcome",
+		},
+		[]string{"kind", "outcome"},
+	)
+
+	ChatReactionUpdates = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_reaction_updates_total",
+			Help: "Total number of message reaction updates by action and outcome",
+		},
+		[]string{"action", "outcome"},
+	)
+
+	ChatWebSocketPresenceUpdates = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_websocket_presence_updates_total",
+			Help: "Total number of presence updates by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	ChatConversationUpdates = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_conversation_updates_total",
+			Help: "Total number of conversation metadata updates by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	ChatDisappearingExpired = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_disappearing_expired_total",
+			Help: "Total number of expired disappearing material removed by kind",
+		},
+		[]string{"kind"},
+	)
+
+	ChatPushNotifications = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_push_notifications_total",
+			Help: "Total number of offline push notifications by provider and outcome",
+		},
+		[]string{"provider", "outcome"},
+	)
+
+	ChatWebhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chat_webhook_deliveries_total",
+			Help: "Total number of outbound webhook delivery attempts by event and outcome",
+		},
+		[]string{"event", "outcome"},
+	)
+
+	ChatWebhookDeadLettersExpired = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "chat_webhook_dead_letters_expired_total",
+			Help: "Total number of webhook dead letters removed after their expiry",
+		},
+	)
 )
@@ -31,6 +31,79 @@ var (
 		[]string{"name"},
 	)
 
+	CircuitBreakerTransitions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "circuit_breaker_transitions_total",
+			Help: "Total number of circuit breaker state transitions",
+		},
+		[]string{"name", "from", "to"},
+	)
+
+	CircuitBreakerRejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "circuit_breaker_rejected_total",
+			Help: "Total number of calls rejected by an open or saturated half-open circuit breaker",
+		},
+		[]string{"name"},
+	)
+
+	RetryAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "retry_attempts_total",
+			Help: "Total number of retry policy outcomes (retried, recovered, failed, exhausted, aborted)",
+		},
+		[]string{"name", "outcome"},
+	)
+
+	BulkheadInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bulkhead_in_flight",
+			Help: "Number of calls currently executing inside a bulkhead",
+		},
+		[]string{"name"},
+	)
+
+	BulkheadQueued = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bulkhead_queued",
+			Help: "Number of calls waiting for a bulkhead slot",
+		},
+		[]string{"name"},
+	)
+
+	BulkheadLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bulkhead_limit",
+			Help: "Current concurrency limit of a bulkhead",
+		},
+		[]string{"name"},
+	)
+
+	BulkheadRejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bulkhead_rejected_total",
+			Help: "Total number of calls rejected by a bulkhead",
+		},
+		[]string{"name", "reason"},
+	)
+
+	BulkheadWaitSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bulkhead_wait_seconds",
+			Help:    "Time spent waiting for a bulkhead slot",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		},
+		[]string{"name"},
+	)
+
+	ConfigReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_reloads_total",
+			Help: "Total number of config file reloads by outcome (applied, unchanged, rejected, invalid)",
+		},
+		[]string{"outcome"},
+	)
+
 	DomainErrorsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "domain_errors_total",
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PgxLogger struct {
+	next pgx.Logger
+}
+
+func NewPgxLogger(next pgx.Logger) *PgxLogger {
+	return &PgxLogger{next: next}
+}
+
+func (l *PgxLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	if l.next != nil {
+		l.next.Log(ctx, level, msg, data)
+	}
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+
+	switch msg {
+	case "Query", "Exec", "QueryRow", "CopyFrom", "SendBatch", "BatchResult.Exec", "BatchResult.Query":
+	default:
+		return
+	}
+
+	end := time.Now()
+	start := end
+	if elapsed, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-elapsed)
+	}
+
+	sql, _ := data["sql"].(string)
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", operationName(sql)),
+		attribute.String("db.query.text", sql),
+	}
+	if rows, ok := data["rowCount"].(int64); ok {
+		attrs = append(attrs, attribute.Int64("db.response.returned_rows", rows))
+	}
+
+	_, span := Tracer().Start(ctx, "pgx."+msg,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	if errValue, ok := data["err"]; ok && level <= pgx.LogLevelError {
+		err := fmt.Errorf("%v", errValue)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+func operationName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,37 @@
+package domain
+
+type Visibility string
+
+const (
+	VisibilityEveryone Visibility = "everyone"
+	VisibilityContacts Visibility = "contacts"
+	VisibilityNobody   Visibility = "nobody"
+)
+
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityEveryone, VisibilityContacts, VisibilityNobody:
+		return true
+	default:
+		return false
+	}
+}
+
+type Settings struct {
+	UserID             string
+	ReadReceipts       bool
+	PresenceVisibility Visibility
+}
+
+type SettingsUpdate struct {
+	ReadReceipts       *bool
+	PresenceVisibility *Visibility
+}
+
+func DefaultSettings(userID string) Settings {
+	return Settings{
+		UserID:             userID,
+		ReadReceipts:       true,
+		PresenceVisibility: VisibilityEveryone,
+	}
+}
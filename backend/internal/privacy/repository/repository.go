@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+)
+
+type Repository interface {
+	GetSettings(ctx context.Context, userID string) (domain.Settings, error)
+	SaveSettings(ctx context.Context, settings domain.Settings) error
+	ListContacts(ctx context.Context, userID string) ([]string, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("privacy_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) GetSettings(ctx context.Context, userID string) (domain.Settings, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	settings := domain.DefaultSettings(userID)
+	var visibility string
+	start := time.Now()
+	err := r.pool.QueryRow(
+		ctx,
+		`SELECT read_receipts, presence_visibility FROM user_privacy_settings WHERE user_id = $1`,
+		userID,
+	).Scan(&settings.ReadReceipts, &visibility)
+	if errors.Is(err, pgx.ErrNoRows) {
+		db.MeasureQueryDuration("get privacy settings", start)
+		return settings, nil
+	}
+	if err != nil {
+		return domain.Settings{}, db.HandleQueryError(err, nil, "get privacy settings", start)
+	}
+	settings.PresenceVisibility = domain.Visibility(visibility)
+
+	db.MeasureQueryDuration("get privacy settings", start)
+	return settings, nil
+}
+
+func (r *PgRepository) SaveSettings(ctx context.Context, settings domain.Settings) error {
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`INSERT INTO user_privacy_settings (user_id, read_receipts, presence_visibility, updated_at)
+			 VALUES ($1, $2, $3, NOW())
+			 ON CONFLICT (user_id) DO UPDATE
+			 SET read_receipts = EXCLUDED.read_receipts,
+			     presence_visibility = EXCLUDED.presence_visibility,
+			     updated_at = NOW()`,
+			settings.UserID,
+			settings.ReadReceipts,
+			string(settings.PresenceVisibility),
+		)
+		return db.HandleExecError(err, "save privacy settings", start)
+	})
+}
+
+func (r *PgRepository) ListContacts(ctx context.Context, userID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT DISTINCT recipient_id FROM message_receipts WHERE sender_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list privacy contacts", start)
+	}
+	defer rows.Close()
+
+	var contacts []string
+	for rows.Next() {
+		var contactID string
+		if err := rows.Scan(&contactID); err != nil {
+			return nil, db.HandleQueryError(err, nil, "list privacy contacts", start)
+		}
+		contacts = append(contacts, contactID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list privacy contacts", start)
+	}
+
+	db.MeasureQueryDuration("list privacy contacts", start)
+	return contacts, nil
+}
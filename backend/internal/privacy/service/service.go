@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+	privacyrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/repository"
+)
+
+type Service interface {
+	GetSettings(ctx context.Context, userID string) (domain.Settings, error)
+	UpdateSettings(ctx context.Context, userID string, update domain.SettingsUpdate) (domain.Settings, error)
+	ReadReceiptsEnabled(ctx context.Context, userID string) bool
+	CanSeePresence(ctx context.Context, viewerID, targetID string) bool
+}
+
+type settingsCacheEntry struct {
+	settings  domain.Settings
+	expiresAt time.Time
+}
+
+type contactsCacheEntry struct {
+	contacts  map[string]struct{}
+	expiresAt time.Time
+}
+
+type PrivacyService struct {
+	repo          privacyrepo.Repository
+	clock         clock.Clock
+	log           *logger.Logger
+	settingsCache sync.Map
+	contactsCache sync.Map
+}
+
+type PrivacyServiceDeps struct {
+	Repo  privacyrepo.Repository
+	Clock clock.Clock
+	Log   *logger.Logger
+}
+
+func NewPrivacyService(deps PrivacyServiceDeps) *PrivacyService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+
+	return &PrivacyService{
+		repo:  deps.Repo,
+		clock: timeClock,
+		log:   deps.Log,
+	}
+}
+
+func (s *PrivacyService) GetSettings(ctx context.Context, userID string) (domain.Settings, error) {
+	if cached, ok := s.settingsCache.Load(userID); ok {
+		entry := cached.(*settingsCacheEntry)
+		if s.clock.Now().Before(entry.expiresAt) {
+			return entry.settings, nil
+		}
+		s.settingsCache.Delete(userID)
+	}
+
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		return domain.Settings{}, commonerrors.ErrPrivacySettingsFailed.WithCause(err)
+	}
+	s.cacheSettings(settings)
+	return settings, nil
+}
+
+func (s *PrivacyService) UpdateSettings(ctx context.Context, userID string, update domain.SettingsUpdate) (domain.Settings, error) {
+	if update.PresenceVisibility != nil && !update.PresenceVisibility.Valid() {
+		return domain.Settings{}, commonerrors.ErrInvalidPresenceVisibility
+	}
+
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		return domain.Settings{}, commonerrors.ErrPrivacySettingsFailed.WithCause(err)
+	}
+	if update.ReadReceipts != nil {
+		settings.ReadReceipts = *update.ReadReceipts
+	}
+	if update.PresenceVisibility != nil {
+		settings.PresenceVisibility = *update.PresenceVisibility
+	}
+
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "privacy_settings_update_failed",
+		}).Errorf("failed to update privacy settings: %v", err)
+		return domain.Settings{}, commonerrors.ErrPrivacySettingsFailed.WithCause(err)
+	}
+	s.cacheSettings(settings)
+	return settings, nil
+}
+
+func (s *PrivacyService) ReadReceiptsEnabled(ctx context.Context, userID string) bool {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "privacy_settings_lookup_failed",
+		}).Warnf("failed to load privacy settings, suppressing read receipts: %v", err)
+		return false
+	}
+	return settings.ReadReceipts
+}
+
+func (s *PrivacyService) CanSeePresence(ctx context.Context, viewerID, targetID string) bool {
+	if viewerID == targetID {
+		return true
+	}
+
+	settings, err := s.GetSettings(ctx, targetID)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": targetID,
+			"action":  "privacy_settings_lookup_failed",
+		}).Warnf("failed to load privacy settings, hiding presence: %v", err)
+		return false
+	}
+
+	switch settings.PresenceVisibility {
+	case domain.VisibilityEveryone:
+		return true
+	case domain.VisibilityContacts:
+		contacts, err := s.contacts(ctx, targetID)
+		if err != nil {
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id": targetID,
+				"action":  "privacy_contacts_lookup_failed",
+			}).Warnf("failed to load contacts, hiding presence: %v", err)
+			return false
+		}
+		_, ok := contacts[viewerID]
+		return ok
+	default:
+		return false
+	}
+}
+
+func (s *PrivacyService) StartCleanup(ctx context.Context) {
+	ticker := time.NewTicker(constants.PrivacyCacheCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := s.clock.Now()
+			s.settingsCache.Range(func(key, value interface{}) bool {
+				if now.After(value.(*settingsCacheEntry).expiresAt) {
+					s.settingsCache.Delete(key)
+				}
+				return true
+			})
+			s.contactsCache.Range(func(key, value interface{}) bool {
+				if now.After(value.(*contactsCacheEntry).expiresAt) {
+					s.contactsCache.Delete(key)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (s *PrivacyService) cacheSettings(settings domain.Settings) {
+	s.settingsCache.Store(settings.UserID, &settingsCacheEntry{
+		settings:  settings,
+		expiresAt: s.clock.Now().Add(constants.PrivacyCacheTTL),
+	})
+}
+
+func (s *PrivacyService) contacts(ctx context.Context, userID string) (map[string]struct{}, error) {
+	if cached, ok := s.contactsCache.Load(userID); ok {
+		entry := cached.(*contactsCacheEntry)
+		if s.clock.Now().Before(entry.expiresAt) {
+			return entry.contacts, nil
+		}
+		s.contactsCache.Delete(userID)
+	}
+
+	ids, err := s.repo.ListContacts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	contacts := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		contacts[id] = struct{}{}
+	}
+	s.contactsCache.Store(userID, &contactsCacheEntry{
+		contacts:  contacts,
+		expiresAt: s.clock.Now().Add(constants.PrivacyCacheTTL),
+	})
+	return contacts, nil
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+type Provider string
+
+const (
+	ProviderWebPush Provider = "webpush"
+	ProviderWebhook Provider = "webhook"
+)
+
+func (p Provider) Valid() bool {
+	switch p {
+	case ProviderWebPush, ProviderWebhook:
+		return true
+	default:
+		return false
+	}
+}
+
+type Device struct {
+	UserID    string
+	Token     string
+	Provider  Provider
+	CreatedAt time.Time
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+)
+
+const webhookEvent = "wake"
+
+type webhookPayload struct {
+	Token string `json:"token"`
+	Event string `json:"event"`
+}
+
+type WebhookConfig struct {
+	URL    string
+	Client *http.Client
+}
+
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(cfg WebhookConfig) *WebhookNotifier {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNotifier{url: cfg.URL, client: client}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, device domain.Device) error {
+	body, err := json.Marshal(webhookPayload{Token: device.Token, Event: webhookEvent})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(n.client, req)
+}
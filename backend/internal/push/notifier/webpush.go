@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+)
+
+type WebPushConfig struct {
+	PrivateKey string
+	Subject    string
+	Client     *http.Client
+	Clock      clock.Clock
+}
+
+type WebPushNotifier struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+	client    *http.Client
+	clock     clock.Clock
+}
+
+func NewWebPushNotifier(cfg WebPushConfig) (*WebPushNotifier, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cfg.PrivateKey, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key encoding: %w", err)
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+	publicKey := ecdhKey.PublicKey().Bytes()
+
+	client := cfg.Client
+	if client == nil {
+		client = commonhttp.NewPublicClient(constants.DefaultPushTimeout)
+	}
+	timeClock := cfg.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+
+	return &WebPushNotifier{
+		key: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(publicKey[1:33]),
+				Y:     new(big.Int).SetBytes(publicKey[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		publicKey: base64.RawURLEncoding.EncodeToString(publicKey),
+		subject:   cfg.Subject,
+		client:    client,
+		clock:     timeClock,
+	}, nil
+}
+
+func (n *WebPushNotifier) PublicKey() string {
+	return n.publicKey
+}
+
+func (n *WebPushNotifier) Notify(ctx context.Context, device domain.Device) error {
+	endpoint, err := url.Parse(device.Token)
+	if err != nil {
+		return err
+	}
+
+	claims := jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": n.clock.Now().Add(constants.PushVAPIDTokenTTL).Unix(),
+		"sub": n.subject,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(n.key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+n.publicKey)
+	req.Header.Set("TTL", strconv.Itoa(int(constants.PushMessageTTL/time.Second)))
+	req.Header.Set("Urgency", "high")
+	return send(n.client, req)
+}
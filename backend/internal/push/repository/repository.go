@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+)
+
+var ErrDeviceNotFound = pgx.ErrNoRows
+
+type Repository interface {
+	Register(ctx context.Context, device domain.Device) (domain.Device, error)
+	Delete(ctx context.Context, userID, token string) error
+	DeleteToken(ctx context.Context, token string) error
+	ListByUser(ctx context.Context, userID string) ([]domain.Device, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("push_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) Register(ctx context.Context, device domain.Device) (domain.Device, error) {
+	var registered domain.Device
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		var err error
+		registered, err = scanDevice(r.pool.QueryRow(
+			ctx,
+			`INSERT INTO push_devices (token, user_id, provider, created_at, updated_at)
+			 VALUES ($1, $2, $3, NOW(), NOW())
+			 ON CONFLICT (token) DO UPDATE
+			 SET user_id = EXCLUDED.user_id,
+			     provider = EXCLUDED.provider,
+			     updated_at = NOW()
+			 RETURNING user_id, token, provider, created_at`,
+			device.Token,
+			device.UserID,
+			string(device.Provider),
+		))
+		if err != nil {
+			return db.HandleQueryError(err, nil, "register push device", start)
+		}
+		db.MeasureQueryDuration("register push device", start)
+		return nil
+	})
+	return registered, err
+}
+
+func (r *PgRepository) Delete(ctx context.Context, userID, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM push_devices WHERE user_id = $1 AND token = $2`,
+		userID,
+		token,
+	)
+	if err != nil {
+		return db.HandleExecError(err, "delete push device", start)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrDeviceNotFound
+	}
+
+	db.MeasureQueryDuration("delete push device", start)
+	return nil
+}
+
+func (r *PgRepository) DeleteToken(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.pool.Exec(ctx, `DELETE FROM push_devices WHERE token = $1`, token)
+	return db.HandleExecError(err, "delete push device token", start)
+}
+
+func (r *PgRepository) ListByUser(ctx context.Context, userID string) ([]domain.Device, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT user_id, token, provider, created_at
+		 FROM push_devices
+		 WHERE user_id = $1
+		 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list push devices", start)
+	}
+	defer rows.Close()
+
+	var devices []domain.Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, db.HandleQueryError(err, nil, "list push devices", start)
+		}
+		devices = append(devices, device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list push devices", start)
+	}
+
+	db.MeasureQueryDuration("list push devices", start)
+	return devices, nil
+}
+
+func scanDevice(row pgx.Row) (domain.Device, error) {
+	var device domain.Device
+	var provider string
+	err := row.Scan(&device.UserID, &device.Token, &provider, &device.CreatedAt)
+	device.Provider = domain.Provider(provider)
+	return device, err
+}
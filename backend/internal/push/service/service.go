@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/push/notifier"
+	pushrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/repository"
+)
+
+const allProviders = "all"
+
+type Service interface {
+	RegisterDevice(ctx context.Context, userID string, provider domain.Provider, token string) (domain.Device, error)
+	UnregisterDevice(ctx context.Context, userID, token string) error
+	VAPIDPublicKey() string
+	NotifyOffline(userID string)
+	Stop()
+}
+
+type PushService struct {
+	ctx            context.Context
+	cancel         context.CancelFunc
+	repo           pushrepo.Repository
+	notifiers      map[domain.Provider]notifier.Notifier
+	vapidPublicKey string
+	timeout        time.Duration
+	clock          clock.Clock
+	log            *logger.Logger
+	queue          chan string
+	lastNotified   sync.Map
+	wg             sync.WaitGroup
+}
+
+type PushServiceDeps struct {
+	Repo           pushrepo.Repository
+	Notifiers      map[domain.Provider]notifier.Notifier
+	VAPIDPublicKey string
+	Timeout        time.Duration
+	Clock          clock.Clock
+	Log            *logger.Logger
+}
+
+func NewPushService(ctx context.Context, deps PushServiceDeps) *PushService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	timeout := deps.Timeout
+	if timeout <= 0 {
+		timeout = constants.DefaultPushTimeout
+	}
+
+	serviceCtx, cancel := context.WithCancel(ctx)
+	s := &PushService{
+		ctx:            serviceCtx,
+		cancel:         cancel,
+		repo:           deps.Repo,
+		notifiers:      deps.Notifiers,
+		vapidPublicKey: deps.VAPIDPublicKey,
+		timeout:        timeout,
+		clock:          timeClock,
+		log:            deps.Log,
+		queue:          make(chan string, constants.PushQueueSize),
+	}
+
+	for i := 0; i < constants.PushWorkers; i++ {
+		s.wg.Add(1)
+		go s.run()
+	}
+
+	return s
+}
+
+func (s *PushService) RegisterDevice(ctx context.Context, userID string, provider domain.Provider, token string) (domain.Device, error) {
+	if !provider.Valid() || !validToken(provider, token) {
+		return domain.Device{}, commonerrors.ErrInvalidPushDevice
+	}
+	if _, ok := s.notifiers[provider]; !ok {
+		return domain.Device{}, commonerrors.ErrPushProviderUnavailable
+	}
+
+	devices, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return domain.Device{}, commonerrors.ErrPushDeviceFailed.WithCause(err)
+	}
+	if len(devices) >= constants.MaxPushDevicesPerUser && !hasToken(devices, token) {
+		return domain.Device{}, commonerrors.ErrPushDeviceLimit
+	}
+
+	device, err := s.repo.Register(ctx, domain.Device{UserID: userID, Token: token, Provider: provider})
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id":  userID,
+			"provider": string(provider),
+			"action":   "push_device_register_failed",
+		}).Errorf("failed to register push device: %v", err)
+		return domain.Device{}, commonerrors.ErrPushDeviceFailed.WithCause(err)
+	}
+	return device, nil
+}
+
+func (s *PushService) UnregisterDevice(ctx context.Context, userID, token string) error {
+	if err := s.repo.Delete(ctx, userID, token); err != nil {
+		if errors.Is(err, pushrepo.ErrDeviceNotFound) {
+			return commonerrors.ErrPushDeviceNotFound
+		}
+		return commonerrors.ErrPushDeviceFailed.WithCause(err)
+	}
+	return nil
+}
+
+func (s *PushService) VAPIDPublicKey() string {
+	return s.vapidPublicKey
+}
+
+func (s *PushService) NotifyOffline(userID string) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+
+	now := s.clock.Now()
+	if last, ok := s.lastNotified.Load(userID); ok && now.Sub(last.(time.Time)) < constants.PushThrottleInterval {
+		metrics.ChatPushNotifications.WithLabelValues(allProviders, "throttled").Inc()
+		return
+	}
+	s.lastNotified.Store(userID, now)
+
+	select {
+	case s.queue <- userID:
+	default:
+		metrics.ChatPushNotifications.WithLabelValues(allProviders, "dropped").Inc()
+		s.log.WithFields(context.Background(), logger.Fields{
+			"user_id": userID,
+			"action":  "push_enqueue_dropped",
+		}).Warn("push queue is full, dropping notification")
+	}
+}
+
+func (s *PushService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *PushService) StartCleanup(ctx context.Context) {
+	ticker := time.NewTicker(constants.PushThrottleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := s.clock.Now()
+			s.lastNotified.Range(func(key, value interface{}) bool {
+				if now.Sub(value.(time.Time)) >= constants.PushThrottleInterval {
+					s.lastNotified.Delete(key)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (s *PushService) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case userID := <-s.queue:
+			s.deliver(userID)
+		}
+	}
+}
+
+func (s *PushService) deliver(userID string) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	devices, err := s.repo.ListByUser(ctx, userID)
+	cancel()
+	if err != nil {
+		metrics.ChatPushNotifications.WithLabelValues(allProviders, "failed").Inc()
+		s.log.WithFields(s.ctx, logger.Fields{
+			"user_id": userID,
+			"action":  "push_devices_lookup_failed",
+		}).Warnf("failed to load push devices: %v", err)
+		return
+	}
+
+	for _, device := range devices {
+		n, ok := s.notifiers[device.Provider]
+		if !ok {
+			metrics.ChatPushNotifications.WithLabelValues(string(device.Provider), "unconfigured").Inc()
+			continue
+		}
+		metrics.ChatPushNotifications.WithLabelValues(string(device.Provider), s.notify(n, device)).Inc()
+	}
+}
+
+func (s *PushService) notify(n notifier.Notifier, device domain.Device) string {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+
+	err := n.Notify(ctx, device)
+	switch {
+	case err == nil:
+		return "sent"
+	case errors.Is(err, notifier.ErrDeviceGone):
+		if err := s.repo.DeleteToken(ctx, device.Token); err != nil {
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id":  device.UserID,
+				"provider": string(device.Provider),
+				"action":   "push_device_prune_failed",
+			}).Warnf("failed to remove unregistered push device: %v", err)
+		}
+		return "gone"
+	default:
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id":  device.UserID,
+			"provider": string(device.Provider),
+			"action":   "push_delivery_failed",
+		}).Warnf("failed to deliver push notification: %v", err)
+		return "failed"
+	}
+}
+
+func validToken(provider domain.Provider, token string) bool {
+	if token == "" || len(token) > constants.MaxPushTokenLength || strings.TrimSpace(token) != token {
+		return false
+	}
+	if provider != domain.ProviderWebPush {
+		return true
+	}
+	endpoint, err := url.Parse(token)
+	return err == nil && endpoint.Scheme == "https" && endpoint.Host != "" && endpoint.User == nil && commonhttp.IsPublicHost(endpoint.Hostname())
+}
+
+func hasToken(devices []domain.Device, token string) bool {
+	for _, device := range devices {
+		if device.Token == token {
+			return true
+		}
+	}
+	return false
+}
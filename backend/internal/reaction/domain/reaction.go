@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+type Action string
+
+const (
+	ActionAdd    Action = "add"
+	ActionRemove Action = "remove"
+)
+
+func (a Action) Valid() bool {
+	return a == ActionAdd || a == ActionRemove
+}
+
+type Reaction struct {
+	MessageID string
+	UserID    string
+	PeerID    string
+	Emoji     string
+	Action    Action
+	ExpiresAt *time.Time
+}
+
+type Summary struct {
+	MessageID string
+	Emoji     string
+	Count     int
+	Reacted   bool
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+)
+
+type Repository interface {
+	Add(ctx context.Context, reaction domain.Reaction, limit int) (bool, error)
+	Remove(ctx context.Context, reaction domain.Reaction) error
+	Summarize(ctx context.Context, userID, peerID string, messageIDs []string) ([]domain.Summary, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("reaction_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) Add(ctx context.Context, reaction domain.Reaction, limit int) (bool, error) {
+	var added bool
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		res, err := r.pool.Exec(
+			ctx,
+			`INSERT INTO message_reactions (message_id, user_id, peer_id, emoji, created_at, expires_at)
+			 SELECT $1, $2, $3, $4, NOW(), $5
+			 WHERE (
+			   SELECT COUNT(*) FROM message_reactions
+			   WHERE user_id = $2 AND peer_id = $3 AND message_id = $1 AND emoji <> $4
+			 ) < $6
+			 ON CONFLICT (user_id, peer_id, message_id, emoji) DO UPDATE
+			 SET expires_at = EXCLUDED.expires_at`,
+			reaction.MessageID,
+			reaction.UserID,
+			reaction.PeerID,
+			reaction.Emoji,
+			reaction.ExpiresAt,
+			limit,
+		)
+		if err != nil {
+			return db.HandleExecError(err, "add reaction", start)
+		}
+		added = res.RowsAffected() > 0
+		db.MeasureQueryDuration("add reaction", start)
+		return nil
+	})
+	return added, err
+}
+
+func (r *PgRepository) Remove(ctx context.Context, reaction domain.Reaction) error {
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`DELETE FROM message_reactions
+			 WHERE user_id = $1 AND peer_id = $2 AND message_id = $3 AND emoji = $4`,
+			reaction.UserID,
+			reaction.PeerID,
+			reaction.MessageID,
+			reaction.Emoji,
+		)
+		return db.HandleExecError(err, "remove reaction", start)
+	})
+}
+
+func (r *PgRepository) Summarize(ctx context.Context, userID, peerID string, messageIDs []string) ([]domain.Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT message_id, emoji, COUNT(*), BOOL_OR(user_id = $1)
+		 FROM message_reactions
+		 WHERE ((user_id = $1 AND peer_id = $2) OR (user_id = $2 AND peer_id = $1))
+		   AND message_id = ANY($3)
+		   AND (expires_at IS NULL OR expires_at > NOW())
+		 GROUP BY message_id, emoji
+		 ORDER BY message_id, MIN(created_at)`,
+		userID,
+		peerID,
+		messageIDs,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list reactions", start)
+	}
+	defer rows.Close()
+
+	summaries := make([]domain.Summary, 0)
+	for rows.Next() {
+		var summary domain.Summary
+		if err := rows.Scan(&summary.MessageID, &summary.Emoji, &summary.Count, &summary.Reacted); err != nil {
+			return nil, db.HandleQueryError(err, nil, "list reactions", start)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list reactions", start)
+	}
+
+	db.MeasureQueryDuration("list reactions", start)
+	return summaries, nil
+}
+
+func (r *PgRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM message_reactions WHERE expires_at < NOW()`,
+	)
+	if err != nil {
+		return 0, db.HandleExecError(err, "delete expired reactions", start)
+	}
+	db.MeasureQueryDuration("delete expired reactions", start)
+	return res.RowsAffected(), nil
+}
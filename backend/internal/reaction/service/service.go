@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+	reactionrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/repository"
+)
+
+type Service interface {
+	Apply(ctx context.Context, reaction domain.Reaction) error
+	Summarize(ctx context.Context, userID, peerID string, messageIDs []string) ([]domain.Summary, error)
+}
+
+type ReactionService struct {
+	repo reactionrepo.Repository
+	log  *logger.Logger
+}
+
+type ReactionServiceDeps struct {
+	Repo reactionrepo.Repository
+	Log  *logger.Logger
+}
+
+func NewReactionService(deps ReactionServiceDeps) *ReactionService {
+	return &ReactionService{
+		repo: deps.Repo,
+		log:  deps.Log,
+	}
+}
+
+func (s *ReactionService) Apply(ctx context.Context, reaction domain.Reaction) error {
+	if reaction.MessageID == "" || !reaction.Action.Valid() || !domain.ValidEmoji(reaction.Emoji) {
+		action := string(reaction.Action)
+		if !reaction.Action.Valid() {
+			action = "unknown"
+		}
+		metrics.ChatReactionUpdates.WithLabelValues(action, "invalid").Inc()
+		return commonerrors.ErrInvalidReaction
+	}
+
+	if reaction.Action == domain.ActionRemove {
+		if err := s.repo.Remove(ctx, reaction); err != nil {
+			return s.failed(ctx, reaction, err)
+		}
+		metrics.ChatReactionUpdates.WithLabelValues(string(reaction.Action), "applied").Inc()
+		return nil
+	}
+
+	added, err := s.repo.Add(ctx, reaction, constants.MaxReactionsPerMessage)
+	if err != nil {
+		return s.failed(ctx, reaction, err)
+	}
+	if !added {
+		metrics.ChatReactionUpdates.WithLabelValues(string(reaction.Action), "limited").Inc()
+		return commonerrors.ErrReactionLimit
+	}
+	metrics.ChatReactionUpdates.WithLabelValues(string(reaction.Action), "applied").Inc()
+	return nil
+}
+
+func (s *ReactionService) Summarize(ctx context.Context, userID, peerID string, messageIDs []string) ([]domain.Summary, error) {
+	unique := make([]string, 0, len(messageIDs))
+	seen := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return []domain.Summary{}, nil
+	}
+	if len(unique) > constants.MaxReactionMessageIDs {
+		unique = unique[:constants.MaxReactionMessageIDs]
+	}
+
+	summaries, err := s.repo.Summarize(ctx, userID, peerID, unique)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"peer_id": peerID,
+			"action":  "reactions_list_failed",
+		}).Errorf("failed to list reactions: %v", err)
+		return nil, commonerrors.ErrReactionFailed.WithCause(err)
+	}
+	return summaries, nil
+}
+
+func (s *ReactionService) failed(ctx context.Context, reaction domain.Reaction, err error) error {
+	metrics.ChatReactionUpdates.WithLabelValues(string(reaction.Action), "failed").Inc()
+	s.log.WithFields(ctx, logger.Fields{
+		"user_id":    reaction.UserID,
+		"peer_id":    reaction.PeerID,
+		"message_id": reaction.MessageID,
+		"reaction":   string(reaction.Action),
+		"action":     "reaction_update_failed",
+	}).Errorf("failed to update reaction: %v", err)
+	return commonerrors.ErrReactionFailed.WithCause(err)
+}
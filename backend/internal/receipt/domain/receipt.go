@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type Status string
+
+const (
+	StatusSent      Status = "sent"
+	StatusDelivered Status = "delivered"
+	StatusRead      Status = "read"
+)
+
+var statusRanks = map[Status]int{
+	StatusSent:      1,
+	StatusDelivered: 2,
+	StatusRead:      3,
+}
+
+func (s Status) Rank() int {
+	return statusRanks[s]
+}
+
+type Receipt struct {
+	MessageID   string
+	SenderID    string
+	RecipientID string
+	Status      Status
+	UpdatedAt   time.Time
+	SentAt      time.Time
+	ExpiresAt   *time.Time
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+)
+
+var ErrReceiptNotFound = pgx.ErrNoRows
+
+type Repository interface {
+	Insert(ctx context.Context, receipts []domain.Receipt) error
+	Advance(ctx context.Context, receipts []domain.Receipt) error
+	RecordEdit(ctx context.Context, senderID, recipientID, messageID string, editableSince time.Time) (int, bool, error)
+	MarkDeleted(ctx context.Context, senderID, recipientID, messageID string) error
+	ListConversation(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]domain.Receipt, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("receipt_repository", db.IsTransientError),
+	}
+}
+
+type receiptColumns struct {
+	messageIDs   []string
+	senderIDs    []string
+	recipientIDs []string
+	statuses     []string
+	updatedAt    []time.Time
+	sentAt       []time.Time
+	expiresAt    []*time.Time
+}
+
+func toColumns(receipts []domain.Receipt) receiptColumns {
+	columns := receiptColumns{
+		messageIDs:   make([]string, 0, len(receipts)),
+		senderIDs:    make([]string, 0, len(receipts)),
+		recipientIDs: make([]string, 0, len(receipts)),
+		statuses:     make([]string, 0, len(receipts)),
+		updatedAt:    make([]time.Time, 0, len(receipts)),
+		sentAt:       make([]time.Time, 0, len(receipts)),
+		expiresAt:    make([]*time.Time, 0, len(receipts)),
+	}
+	for _, receipt := range receipts {
+		columns.messageIDs = append(columns.messageIDs, receipt.MessageID)
+		columns.senderIDs = append(columns.senderIDs, receipt.SenderID)
+		columns.recipientIDs = append(columns.recipientIDs, receipt.RecipientID)
+		columns.statuses = append(columns.statuses, string(receipt.Status))
+		columns.updatedAt = append(columns.updatedAt, receipt.UpdatedAt)
+		columns.sentAt = append(columns.sentAt, receipt.SentAt)
+		columns.expiresAt = append(columns.expiresAt, receipt.ExpiresAt)
+	}
+	return columns
+}
+
+func (r *PgRepository) Insert(ctx context.Context, receipts []domain.Receipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	columns := toColumns(receipts)
+
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`INSERT INTO message_receipts (message_id, sender_id, recipient_id, status, updated_at, sent_at, expires_at)
+			 SELECT r.message_id, r.sender_id::uuid, r.recipient_id::uuid, r.status, r.updated_at, r.sent_at, r.expires_at
+			 FROM UNNEST($1::text[], $2::text[], $3::text[], $4::text[], $5::timestamptz[], $6::timestamptz[], $7::timestamptz[])
+			   AS r(message_id, sender_id, recipient_id, status, updated_at, sent_at, expires_at)
+			 ON CONFLICT (sender_id, message_id) DO UPDATE
+			 SET status = EXCLUDED.status, updated_at = EXCLUDED.updated_at
+			 WHERE message_receipts.recipient_id = EXCLUDED.recipient_id
+			   AND receipt_status_rank(EXCLUDED.status) > receipt_status_rank(message_receipts.status)`,
+			columns.messageIDs,
+			columns.senderIDs,
+			columns.recipientIDs,
+			columns.statuses,
+			columns.updatedAt,
+			columns.sentAt,
+			columns.expiresAt,
+		)
+		return db.HandleExecError(err, "insert receipts", start)
+	})
+}
+
+func (r *PgRepository) Advance(ctx context.Context, receipts []domain.Receipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	columns := toColumns(receipts)
+
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`UPDATE message_receipts AS m
+			 SET status = r.status, updated_at = r.updated_at
+			 FROM UNNEST($1::text[], $2::text[], $3::text[], $4::text[], $5::timestamptz[])
+			   AS r(message_id, sender_id, recipient_id, status, updated_at)
+			 WHERE m.message_id = r.message_id
+			   AND m.sender_id = r.sender_id::uuid
+			   AND m.recipient_id = r.recipient_id::uuid
+			   AND receipt_status_rank(r.status) > receipt_status_rank(m.status)`,
+			columns.messageIDs,
+			columns.senderIDs,
+			columns.recipientIDs,
+			columns.statuses,
+			columns.updatedAt,
+		)
+		return db.HandleExecError(err, "advance receipts", start)
+	})
+}
+
+func (r *PgRepository) RecordEdit(ctx context.Context, senderID, recipientID, messageID string, editableSince time.Time) (int, bool, error) {
+	var count int
+	var editable bool
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := r.pool.QueryRow(
+			ctx,
+			`UPDATE message_receipts
+			 SET edit_count = edit_count + CASE WHEN sent_at >= $4 THEN 1 ELSE 0 END
+			 WHERE sender_id = $1 AND recipient_id = $2 AND message_id = $3
+			   AND deleted_at IS NULL
+			   AND (expires_at IS NULL OR expires_at > NOW())
+			 RETURNING edit_count, sent_at >= $4`,
+			senderID,
+			recipientID,
+			messageID,
+			editableSince,
+		).Scan(&count, &editable)
+		if err != nil {
+			return db.HandleQueryError(err, ErrReceiptNotFound, "record receipt edit", start)
+		}
+		db.MeasureQueryDuration("record receipt edit", start)
+		return nil
+	})
+	return count, editable, err
+}
+
+func (r *PgRepository) MarkDeleted(ctx context.Context, senderID, recipientID, messageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`UPDATE message_receipts
+		 SET deleted_at = COALESCE(deleted_at, NOW())
+		 WHERE sender_id = $1 AND recipient_id = $2 AND message_id = $3
+		   AND (expires_at IS NULL OR expires_at > NOW())`,
+		senderID,
+		recipientID,
+		messageID,
+	)
+	if err != nil {
+		return db.HandleExecError(err, "mark receipt deleted", start)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrReceiptNotFound
+	}
+
+	db.MeasureQueryDuration("mark receipt deleted", start)
+	return nil
+}
+
+func (r *PgRepository) ListConversation(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]domain.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT message_id, sender_id, recipient_id, status, updated_at
+		 FROM message_receipts
+		 WHERE ((sender_id = $1 AND recipient_id = $2) OR (sender_id = $2 AND recipient_id = $1))
+		   AND updated_at > $3
+		 ORDER BY updated_at ASC
+		 LIMIT $4`,
+		userID,
+		peerID,
+		since,
+		limit,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list receipts", start)
+	}
+	defer rows.Close()
+
+	receipts := make([]domain.Receipt, 0, limit)
+	for rows.Next() {
+		var receipt domain.Receipt
+		var status string
+		if err := rows.Scan(&receipt.MessageID, &receipt.SenderID, &receipt.RecipientID, &status, &receipt.UpdatedAt); err != nil {
+			return nil, db.HandleQueryError(err, nil, "list receipts", start)
+		}
+		receipt.Status = domain.Status(status)
+		receipts = append(receipts, receipt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list receipts", start)
+	}
+
+	db.MeasureQueryDuration("list receipts", start)
+	return receipts, nil
+}
+
+func (r *PgRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM message_receipts WHERE expires_at < NOW()`,
+	)
+	if err != nil {
+		return 0, db.HandleExecError(err, "delete expired receipts", start)
+	}
+	db.MeasureQueryDuration("delete expired receipts", start)
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	receiptrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/repository"
+)
+
+type Service interface {
+	Record(receipt domain.Receipt)
+	ListConversation(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]domain.Receipt, error)
+	RecordEdit(ctx context.Context, senderID, recipientID, messageID string) (int, error)
+	RecordDelete(ctx context.Context, senderID, recipientID, messageID string) error
+	SetEditWindow(window time.Duration)
+	Stop()
+}
+
+type receiptKey struct {
+	senderID  string
+	messageID string
+}
+
+type pendingReceipt struct {
+	receipt domain.Receipt
+	create  bool
+}
+
+type ReceiptService struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	repo       receiptrepo.Repository
+	clock      clock.Clock
+	log        *logger.Logger
+	queue      chan domain.Receipt
+	flushes    chan chan struct{}
+	editWindow atomic.Int64
+	wg         sync.WaitGroup
+}
+
+type ReceiptServiceDeps struct {
+	Repo       receiptrepo.Repository
+	EditWindow time.Duration
+	Clock      clock.Clock
+	Log        *logger.Logger
+}
+
+func NewReceiptService(ctx context.Context, deps ReceiptServiceDeps) *ReceiptService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+
+	serviceCtx, cancel := context.WithCancel(ctx)
+	s := &ReceiptService{
+		ctx:     serviceCtx,
+		cancel:  cancel,
+		repo:    deps.Repo,
+		clock:   timeClock,
+		log:     deps.Log,
+		queue:   make(chan domain.Receipt, constants.ReceiptQueueSize),
+		flushes: make(chan chan struct{}),
+	}
+	s.SetEditWindow(deps.EditWindow)
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+func (s *ReceiptService) Record(receipt domain.Receipt) {
+	if receipt.MessageID == "" || receipt.Status.Rank() == 0 {
+		return
+	}
+	if receipt.UpdatedAt.IsZero() {
+		receipt.UpdatedAt = s.clock.Now()
+	}
+	if receipt.Status == domain.StatusSent && receipt.SentAt.IsZero() {
+		receipt.SentAt = receipt.UpdatedAt
+	}
+
+	select {
+	case s.queue <- receipt:
+	default:
+		metrics.ChatReceiptUpdates.WithLabelValues(string(receipt.Status), "dropped").Inc()
+		s.log.WithFields(context.Background(), logger.Fields{
+			"message_id": receipt.MessageID,
+			"status":     string(receipt.Status),
+			"action":     "receipt_enqueue_dropped",
+		}).Warn("receipt queue is full, dropping update")
+	}
+}
+
+func (s *ReceiptService) ListConversation(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]domain.Receipt, error) {
+	if limit <= 0 || limit > constants.MaxReceiptListLimit {
+		limit = constants.DefaultReceiptListLimit
+	}
+
+	receipts, err := s.repo.ListConversation(ctx, userID, peerID, since, limit)
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": userID,
+			"peer_id": peerID,
+			"action":  "receipts_list_failed",
+		}).Errorf("failed to list receipts: %v", err)
+		return nil, commonerrors.ErrReceiptsListFailed.WithCause(err)
+	}
+	return receipts, nil
+}
+
+func (s *ReceiptService) RecordEdit(ctx context.Context, senderID, recipientID, messageID string) (int, error) {
+	editableSince := s.clock.Now().Add(-time.Duration(s.editWindow.Load()))
+
+	var count int
+	var editable bool
+	err := s.withPending(ctx, func() error {
+		var err error
+		count, editable, err = s.repo.RecordEdit(ctx, senderID, recipientID, messageID, editableSince)
+		return err
+	})
+	if errors.Is(err, receiptrepo.ErrReceiptNotFound) {
+		metrics.ChatMessageUpdates.WithLabelValues("edit", "not_owned").Inc()
+		return 0, commonerrors.ErrMessageNotOwned.WithCause(err)
+	}
+	if err != nil {
+		return 0, s.updateFailed(ctx, "edit", senderID, messageID, err)
+	}
+	if !editable {
+		metrics.ChatMessageUpdates.WithLabelValues("edit", "expired").Inc()
+		return 0, commonerrors.ErrEditWindowExpired
+	}
+
+	metrics.ChatMessageUpdates.WithLabelValues("edit", "applied").Inc()
+	return count, nil
+}
+
+func (s *ReceiptService) RecordDelete(ctx context.Context, senderID, recipientID, messageID string) error {
+	err := s.withPending(ctx, func() error {
+		return s.repo.MarkDeleted(ctx, senderID, recipientID, messageID)
+	})
+	if errors.Is(err, receiptrepo.ErrReceiptNotFound) {
+		metrics.ChatMessageUpdates.WithLabelValues("delete", "not_owned").Inc()
+		return commonerrors.ErrMessageNotOwned.WithCause(err)
+	}
+	if err != nil {
+		return s.updateFailed(ctx, "delete", senderID, messageID, err)
+	}
+
+	metrics.ChatMessageUpdates.WithLabelValues("delete", "applied").Inc()
+	return nil
+}
+
+func (s *ReceiptService) SetEditWindow(window time.Duration) {
+	if window <= 0 {
+		window = constants.DefaultMessageEditWindow
+	}
+	s.editWindow.Store(int64(window))
+}
+
+func (s *ReceiptService) withPending(ctx context.Context, fn func() error) error {
+	err := fn()
+	if !errors.Is(err, receiptrepo.ErrReceiptNotFound) {
+		return err
+	}
+
+	done := make(chan struct{})
+	select {
+	case s.flushes <- done:
+	case <-s.ctx.Done():
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return fn()
+}
+
+func (s *ReceiptService) updateFailed(ctx context.Context, kind, senderID, messageID string, err error) error {
+	metrics.ChatMessageUpdates.WithLabelValues(kind, "failed").Inc()
+	s.log.WithFields(ctx, logger.Fields{
+		"user_id":    senderID,
+		"message_id": messageID,
+		"action":     "message_" + kind + "_record_failed",
+	}).Errorf("failed to record message %s: %v", kind, err)
+	return commonerrors.ErrMessageUpdateFailed.WithCause(err)
+}
+
+func (s *ReceiptService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *ReceiptService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(constants.ReceiptFlushEvery)
+	defer ticker.Stop()
+
+	pending := make(map[receiptKey]*pendingReceipt)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.drain(pending)
+			s.flush(pending)
+			return
+		case receipt := <-s.queue:
+			merge(pending, receipt)
+			if len(pending) >= constants.ReceiptBatchSize {
+				s.flush(pending)
+			}
+		case done := <-s.flushes:
+			s.drain(pending)
+			s.flush(pending)
+			close(done)
+		case <-ticker.C:
+			s.flush(pending)
+		}
+	}
+}
+
+func (s *ReceiptService) drain(pending map[receiptKey]*pendingReceipt) {
+	for {
+		select {
+		case receipt := <-s.queue:
+			merge(pending, receipt)
+		default:
+			return
+		}
+	}
+}
+
+func merge(pending map[receiptKey]*pendingReceipt, receipt domain.Receipt) {
+	key := receiptKey{senderID: receipt.SenderID, messageID: receipt.MessageID}
+	existing, ok := pending[key]
+	if !ok {
+		pending[key] = &pendingReceipt{receipt: receipt, create: receipt.Status == domain.StatusSent}
+		return
+	}
+	if existing.receipt.RecipientID != receipt.RecipientID {
+		return
+	}
+	if receipt.Status == domain.StatusSent {
+		existing.create = true
+		existing.receipt.SentAt = receipt.SentAt
+		existing.receipt.ExpiresAt = receipt.ExpiresAt
+	}
+	if receipt.Status.Rank() > existing.receipt.Status.Rank() {
+		existing.receipt.Status = receipt.Status
+		existing.receipt.UpdatedAt = receipt.UpdatedAt
+	}
+}
+
+func (s *ReceiptService) flush(pending map[receiptKey]*pendingReceipt) {
+	if len(pending) == 0 {
+		return
+	}
+
+	var inserts, advances []domain.Receipt
+	for _, p := range pending {
+		if p.create {
+			inserts = append(inserts, p.receipt)
+		} else {
+			advances = append(advances, p.receipt)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.ReceiptWriteTimeout)
+	defer cancel()
+
+	s.write(ctx, inserts, s.repo.Insert)
+	s.write(ctx, advances, s.repo.Advance)
+
+	for key := range pending {
+		delete(pending, key)
+	}
+}
+
+func (s *ReceiptService) write(ctx context.Context, receipts []domain.Receipt, write func(context.Context, []domain.Receipt) error) {
+	if len(receipts) == 0 {
+		return
+	}
+
+	outcome := "recorded"
+	if err := write(ctx, receipts); err != nil {
+		outcome = "failed"
+		s.log.WithFields(ctx, logger.Fields{
+			"count":  len(receipts),
+			"action": "receipt_batch_failed",
+		}).Warnf("failed to persist receipts batch: %v", err)
+	}
+	for _, receipt := range receipts {
+		metrics.ChatReceiptUpdates.WithLabelValues(string(receipt.Status), outcome).Inc()
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
 )
 
@@ -26,11 +27,15 @@ type Repository interface {
 }
 
 type PgRepository struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
 }
 
 func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
-	return &PgRepository{pool: pool}
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("user_repository", db.IsTransientError),
+	}
 }
 
 func (r *PgRepository) Create(ctx context.Context, user domain.User) error {
@@ -58,44 +63,63 @@ func (r *PgRepository) Create(ctx context.Context, user domain.User) error {
 }
 
 func (r *PgRepository) FindByUsername(ctx context.Context, username string) (domain.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
-
-	start := time.Now()
-	row := r.pool.QueryRow(
-		ctx,
-		`SELECT id, username, password_hash, created_at, last_seen_at FROM users WHERE username = $1`,
-		username,
-	)
-
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastSeenAt)
-	if err := db.HandleQueryError(err, ErrUserNotFound, "find user by username", start); err != nil {
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		row := r.pool.QueryRow(
+			ctx,
+			`SELECT id, username, password_hash, created_at, last_seen_at FROM users WHERE username = $1`,
+			username,
+		)
+
+		err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastSeenAt)
+		return db.HandleQueryError(err, ErrUserNotFound, "find user by username", start)
+	})
+	if err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
 }
 
 func (r *PgRepository) FindByID(ctx context.Context, id domain.ID) (domain.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
-
-	start := time.Now()
-	row := r.pool.QueryRow(
-		ctx,
-		`SELECT id, username, password_hash, created_at, last_seen_at FROM users WHERE id = $1`,
-		string(id),
-	)
-
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastSeenAt)
-	if err := db.HandleQueryError(err, ErrUserNotFound, "find user by id", start); err != nil {
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		row := r.pool.QueryRow(
+			ctx,
+			`SELECT id, username, password_hash, created_at, last_seen_at FROM users WHERE id = $1`,
+			string(id),
+		)
+
+		err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastSeenAt)
+		return db.HandleQueryError(err, ErrUserNotFound, "find user by id", start)
+	})
+	if err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
 }
 
 func (r *PgRepository) SearchByUsername(ctx context.Context, query string, limit int) ([]domain.Summary, error) {
+	var users []domain.Summary
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		users, err = r.searchByUsername(ctx, query, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *PgRepository) searchByUsername(ctx context.Context, query string, limit int) ([]domain.Summary, error) {
 	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
 	defer cancel()
 
@@ -134,48 +158,53 @@ func (r *PgRepository) SearchByUsername(ctx context.Context, query string, limit
 }
 
 func (r *PgRepository) UpdateLastSeen(ctx context.Context, userID domain.ID) error {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
-
-	start := time.Now()
-	_, err := r.pool.Exec(
-		ctx,
-		`UPDATE users SET last_seen_at = NOW() WHERE id = $1`,
-		string(userID),
-	)
-	return db.HandleExecError(err, "update last_seen_at", start)
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`UPDATE users SET last_seen_at = NOW() WHERE id = $1`,
+			string(userID),
+		)
+		return db.HandleExecError(err, "update last_seen_at", start)
+	})
 }
 
 func (r *PgRepository) UpdateLastSeenBatch(ctx context.Context, userIDs []domain.ID) error {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
-
-	start := time.Now()
-
 	ids := make([]string, 0, len(userIDs))
 	for _, id := range userIDs {
 		ids = append(ids, string(id))
 	}
 
-	_, err := r.pool.Exec(
-		ctx,
-		`UPDATE users SET last_seen_at = NOW() WHERE id = ANY($1)`,
-		ids,
-	)
-	return db.HandleExecError(err, "batch update last_seen_at", start)
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`UPDATE users SET last_seen_at = NOW() WHERE id = ANY($1)`,
+			ids,
+		)
+		return db.HandleExecError(err, "batch update last_seen_at", start)
+	})
 }
 
 func (r *PgRepository) Delete(ctx context.Context, id domain.ID) error {
-	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
-	defer cancel()
-
-	start := time.Now()
-	_, err := r.pool.Exec(
-		ctx,
-		`DELETE FROM users WHERE id = $1`,
-		string(id),
-	)
-	return db.HandleExecError(err, "delete user", start)
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`DELETE FROM users WHERE id = $1`,
+			string(id),
+		)
+		return db.HandleExecError(err, "delete user", start)
+	})
 }
 
 var ErrUserNotFound = pgx.ErrNoRows
@@ -0,0 +1,36 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+)
+
+type ExpiredDeleter interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+func StartDeadLetterCleanup(ctx context.Context, repo ExpiredDeleter, log *logger.Logger) {
+	ticker := time.NewTicker(constants.WebhookDeadLetterCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				log.Errorf("webhook dead letter cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				metrics.ChatWebhookDeadLettersExpired.Add(float64(deleted))
+				log.Infof("webhook dead letter cleanup: removed %d expired dead letters", deleted)
+			}
+		}
+	}
+}
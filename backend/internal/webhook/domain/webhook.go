@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventMessage      EventType = "message"
+	EventReaction     EventType = "reaction"
+	EventFileComplete EventType = "file_complete"
+)
+
+func (e EventType) Valid() bool {
+	switch e {
+	case EventMessage, EventReaction, EventFileComplete:
+		return true
+	default:
+		return false
+	}
+}
+
+type Webhook struct {
+	ID        string
+	OwnerID   string
+	URL       string
+	Secret    string
+	Events    []EventType
+	CreatedAt time.Time
+}
+
+func (w Webhook) Subscribes(event EventType) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type DeadLetter struct {
+	ID        string
+	WebhookID string
+	EventID   string
+	EventType EventType
+	Payload   json.RawMessage
+	Attempts  int
+	LastError string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
+)
+
+var ErrWebhookNotFound = pgx.ErrNoRows
+
+type Repository interface {
+	Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	Delete(ctx context.Context, ownerID, id string) error
+	ListByOwner(ctx context.Context, ownerID string) ([]domain.Webhook, error)
+	InsertDeadLetter(ctx context.Context, letter domain.DeadLetter) error
+	ListDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]domain.DeadLetter, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type PgRepository struct {
+	pool  *pgxpool.Pool
+	retry *resilience.RetryPolicy
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{
+		pool:  pool,
+		retry: db.NewRetryPolicy("webhook_repository", db.IsTransientError),
+	}
+}
+
+func (r *PgRepository) Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	var created domain.Webhook
+	err := r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		var err error
+		created, err = scanWebhook(r.pool.QueryRow(
+			ctx,
+			`INSERT INTO webhooks (id, owner_id, url, secret, events, created_at)
+			 VALUES ($1, $2, $3, $4, $5, NOW())
+			 RETURNING id, owner_id, url, secret, events, created_at`,
+			webhook.ID,
+			webhook.OwnerID,
+			webhook.URL,
+			webhook.Secret,
+			eventNames(webhook.Events),
+		))
+		if err != nil {
+			return db.HandleQueryError(err, nil, "create webhook", start)
+		}
+		db.MeasureQueryDuration("create webhook", start)
+		return nil
+	})
+	return created, err
+}
+
+func (r *PgRepository) Delete(ctx context.Context, ownerID, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM webhooks WHERE owner_id = $1 AND id = $2`,
+		ownerID,
+		id,
+	)
+	if err != nil {
+		return db.HandleExecError(err, "delete webhook", start)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	db.MeasureQueryDuration("delete webhook", start)
+	return nil
+}
+
+func (r *PgRepository) ListByOwner(ctx context.Context, ownerID string) ([]domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT id, owner_id, url, secret, events, created_at
+		 FROM webhooks
+		 WHERE owner_id = $1
+		 ORDER BY created_at`,
+		ownerID,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list webhooks", start)
+	}
+	defer rows.Close()
+
+	var webhooks []domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, db.HandleQueryError(err, nil, "list webhooks", start)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list webhooks", start)
+	}
+
+	db.MeasureQueryDuration("list webhooks", start)
+	return webhooks, nil
+}
+
+func (r *PgRepository) InsertDeadLetter(ctx context.Context, letter domain.DeadLetter) error {
+	return r.retry.Do(ctx, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+		defer cancel()
+
+		start := time.Now()
+		_, err := r.pool.Exec(
+			ctx,
+			`INSERT INTO webhook_dead_letters (id, webhook_id, event_id, event_type, payload, attempts, last_error, expires_at, created_at)
+			 SELECT $1, id, $3, $4, $5, $6, $7, $8, NOW()
+			 FROM webhooks
+			 WHERE id = $2
+			 ON CONFLICT (id) DO NOTHING`,
+			letter.ID,
+			letter.WebhookID,
+			letter.EventID,
+			string(letter.EventType),
+			[]byte(letter.Payload),
+			letter.Attempts,
+			letter.LastError,
+			letter.ExpiresAt,
+		)
+		return db.HandleExecError(err, "insert dead letter", start)
+	})
+}
+
+func (r *PgRepository) ListDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]domain.DeadLetter, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, d.last_error, d.expires_at, d.created_at
+		 FROM webhook_dead_letters d
+		 JOIN webhooks w ON w.id = d.webhook_id
+		 WHERE w.owner_id = $1 AND d.webhook_id = $2
+		   AND (d.expires_at IS NULL OR d.expires_at > NOW())
+		 ORDER BY d.created_at DESC
+		 LIMIT $3`,
+		ownerID,
+		webhookID,
+		limit,
+	)
+	if err != nil {
+		return nil, db.HandleQueryError(err, nil, "list dead letters", start)
+	}
+	defer rows.Close()
+
+	letters := make([]domain.DeadLetter, 0)
+	for rows.Next() {
+		var letter domain.DeadLetter
+		var eventType string
+		var payload []byte
+		if err := rows.Scan(
+			&letter.ID,
+			&letter.WebhookID,
+			&letter.EventID,
+			&eventType,
+			&payload,
+			&letter.Attempts,
+			&letter.LastError,
+			&letter.ExpiresAt,
+			&letter.CreatedAt,
+		); err != nil {
+			return nil, db.HandleQueryError(err, nil, "list dead letters", start)
+		}
+		letter.EventType = domain.EventType(eventType)
+		letter.Payload = payload
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.HandleQueryError(err, nil, "list dead letters", start)
+	}
+
+	db.MeasureQueryDuration("list dead letters", start)
+	return letters, nil
+}
+
+func (r *PgRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DBQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM webhook_dead_letters WHERE expires_at < NOW()`,
+	)
+	if err != nil {
+		return 0, db.HandleExecError(err, "delete expired dead letters", start)
+	}
+	db.MeasureQueryDuration("delete expired dead letters", start)
+	return res.RowsAffected(), nil
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	var events []string
+	err := row.Scan(&webhook.ID, &webhook.OwnerID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt)
+	webhook.Events = make([]domain.EventType, 0, len(events))
+	for _, event := range events {
+		webhook.Events = append(webhook.Events, domain.EventType(event))
+	}
+	return webhook, err
+}
+
+func eventNames(events []domain.EventType) []string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, string(event))
+	}
+	return names
+}
@@ -0,0 +1,521 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/metrics"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
+	webhookrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/repository"
+)
+
+var errRejected = errors.New("webhook endpoint rejected the event")
+
+type Service interface {
+	Create(ctx context.Context, ownerID, rawURL string, events []domain.EventType) (domain.Webhook, error)
+	List(ctx context.Context, ownerID string) ([]domain.Webhook, error)
+	Delete(ctx context.Context, ownerID, id string) error
+	ListDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]domain.DeadLetter, error)
+	Dispatch(ctx context.Context, ownerID, event string, payload []byte, expiresAt time.Time) bool
+	Stop()
+}
+
+type delivery struct {
+	webhook   domain.Webhook
+	event     domain.Event
+	body      []byte
+	expiresAt time.Time
+	attempts  int
+	lastError string
+}
+
+type cachedWebhooks struct {
+	webhooks []domain.Webhook
+	loadedAt time.Time
+}
+
+type WebhookService struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	repo       webhookrepo.Repository
+	client     *http.Client
+	ids        commoncrypto.IDGenerator
+	retryDelay time.Duration
+	clock      clock.Clock
+	log        *logger.Logger
+	queue      chan *delivery
+	cache      sync.Map
+	mu         sync.Mutex
+	retrying   map[*delivery]*time.Timer
+	wg         sync.WaitGroup
+}
+
+type WebhookServiceDeps struct {
+	Repo        webhookrepo.Repository
+	Client      *http.Client
+	IDGenerator commoncrypto.IDGenerator
+	Timeout     time.Duration
+	RetryDelay  time.Duration
+	Clock       clock.Clock
+	Log         *logger.Logger
+}
+
+func NewWebhookService(ctx context.Context, deps WebhookServiceDeps) *WebhookService {
+	timeClock := deps.Clock
+	if timeClock == nil {
+		timeClock = clock.NewRealClock()
+	}
+	ids := deps.IDGenerator
+	if ids == nil {
+		ids = &commoncrypto.UUIDGenerator{}
+	}
+	retryDelay := deps.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = constants.WebhookRetryBaseDelay
+	}
+	client := deps.Client
+	if client == nil {
+		timeout := deps.Timeout
+		if timeout <= 0 {
+			timeout = constants.DefaultWebhookTimeout
+		}
+		client = commonhttp.NewPublicClient(timeout)
+	}
+
+	serviceCtx, cancel := context.WithCancel(ctx)
+	s := &WebhookService{
+		ctx:        serviceCtx,
+		cancel:     cancel,
+		repo:       deps.Repo,
+		client:     client,
+		ids:        ids,
+		retryDelay: retryDelay,
+		clock:      timeClock,
+		log:        deps.Log,
+		queue:      make(chan *delivery, constants.WebhookQueueSize),
+		retrying:   make(map[*delivery]*time.Timer),
+	}
+
+	for i := 0; i < constants.WebhookWorkers; i++ {
+		s.wg.Add(1)
+		go s.run()
+	}
+
+	return s
+}
+
+func (s *WebhookService) Create(ctx context.Context, ownerID, rawURL string, events []domain.EventType) (domain.Webhook, error) {
+	normalized, ok := normalizeEvents(events)
+	if !ok || !validURL(rawURL) {
+		return domain.Webhook{}, commonerrors.ErrInvalidWebhook
+	}
+
+	existing, err := s.repo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return domain.Webhook{}, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	if len(existing) >= constants.MaxWebhooksPerUser {
+		return domain.Webhook{}, commonerrors.ErrWebhookLimit
+	}
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		return domain.Webhook{}, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return domain.Webhook{}, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+
+	webhook, err := s.repo.Create(ctx, domain.Webhook{
+		ID:      id,
+		OwnerID: ownerID,
+		URL:     rawURL,
+		Secret:  secret,
+		Events:  normalized,
+	})
+	if err != nil {
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": ownerID,
+			"action":  "webhook_create_failed",
+		}).Errorf("failed to create webhook: %v", err)
+		return domain.Webhook{}, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	s.cache.Delete(ownerID)
+	return webhook, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, ownerID string) ([]domain.Webhook, error) {
+	webhooks, err := s.repo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, ownerID, id string) error {
+	if err := s.repo.Delete(ctx, ownerID, id); err != nil {
+		if errors.Is(err, webhookrepo.ErrWebhookNotFound) {
+			return commonerrors.ErrWebhookNotFound
+		}
+		return commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	s.cache.Delete(ownerID)
+	return nil
+}
+
+func (s *WebhookService) ListDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]domain.DeadLetter, error) {
+	webhooks, err := s.List(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasWebhook(webhooks, webhookID) {
+		return nil, commonerrors.ErrWebhookNotFound
+	}
+
+	if limit <= 0 {
+		limit = constants.DefaultWebhookDeadLetterLimit
+	}
+	if limit > constants.MaxWebhookDeadLetterLimit {
+		limit = constants.MaxWebhookDeadLetterLimit
+	}
+	letters, err := s.repo.ListDeadLetters(ctx, ownerID, webhookID, limit)
+	if err != nil {
+		return nil, commonerrors.ErrWebhookFailed.WithCause(err)
+	}
+	return letters, nil
+}
+
+func (s *WebhookService) Dispatch(ctx context.Context, ownerID, event string, payload []byte, expiresAt time.Time) bool {
+	eventType := domain.EventType(event)
+	if !eventType.Valid() {
+		return false
+	}
+
+	webhooks, err := s.webhooks(ctx, ownerID)
+	if err != nil {
+		metrics.ChatWebhookDeliveries.WithLabelValues(event, "lookup_failed").Inc()
+		s.log.WithFields(ctx, logger.Fields{
+			"user_id": ownerID,
+			"event":   event,
+			"action":  "webhook_lookup_failed",
+		}).Warnf("failed to load webhooks: %v", err)
+		return false
+	}
+
+	dispatched := false
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		d, err := s.newDelivery(webhook, eventType, payload, expiresAt)
+		if err != nil {
+			s.log.WithFields(ctx, logger.Fields{
+				"user_id":    ownerID,
+				"webhook_id": webhook.ID,
+				"event":      event,
+				"action":     "webhook_event_build_failed",
+			}).Errorf("failed to build webhook event: %v", err)
+			continue
+		}
+		s.enqueue(d)
+		dispatched = true
+	}
+	return dispatched
+}
+
+func (s *WebhookService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	pending := s.retrying
+	s.retrying = make(map[*delivery]*time.Timer)
+	s.mu.Unlock()
+
+	for d, timer := range pending {
+		timer.Stop()
+		s.deadLetter(d)
+	}
+	for {
+		select {
+		case d := <-s.queue:
+			if d.lastError == "" {
+				d.lastError = "webhook service stopped"
+			}
+			s.deadLetter(d)
+		default:
+			return
+		}
+	}
+}
+
+func (s *WebhookService) StartCleanup(ctx context.Context) {
+	ticker := time.NewTicker(constants.WebhookCacheTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := s.clock.Now()
+			s.cache.Range(func(key, value interface{}) bool {
+				if now.Sub(value.(cachedWebhooks).loadedAt) >= constants.WebhookCacheTTL {
+					s.cache.Delete(key)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (s *WebhookService) webhooks(ctx context.Context, ownerID string) ([]domain.Webhook, error) {
+	if cached, ok := s.cache.Load(ownerID); ok {
+		entry := cached.(cachedWebhooks)
+		if s.clock.Now().Sub(entry.loadedAt) < constants.WebhookCacheTTL {
+			return entry.webhooks, nil
+		}
+	}
+
+	webhooks, err := s.repo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Store(ownerID, cachedWebhooks{webhooks: webhooks, loadedAt: s.clock.Now()})
+	return webhooks, nil
+}
+
+func (s *WebhookService) newDelivery(webhook domain.Webhook, eventType domain.EventType, payload []byte, expiresAt time.Time) (*delivery, error) {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, err
+	}
+	event := domain.Event{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: s.clock.Now().UTC(),
+		Data:      payload,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery{
+		webhook:   webhook,
+		event:     event,
+		body:      body,
+		expiresAt: expiresAt,
+	}, nil
+}
+
+func (s *WebhookService) enqueue(d *delivery) {
+	if s.ctx.Err() != nil {
+		d.lastError = "webhook service stopped"
+		s.deadLetter(d)
+		return
+	}
+
+	select {
+	case s.queue <- d:
+	default:
+		d.lastError = "webhook queue is full"
+		s.deadLetter(d)
+	}
+}
+
+func (s *WebhookService) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case d := <-s.queue:
+			s.attempt(d)
+		}
+	}
+}
+
+func (s *WebhookService) attempt(d *delivery) {
+	event := string(d.event.Type)
+	if !d.expiresAt.IsZero() && !s.clock.Now().Before(d.expiresAt) {
+		metrics.ChatWebhookDeliveries.WithLabelValues(event, "expired").Inc()
+		return
+	}
+
+	d.attempts++
+	err := s.send(d)
+	if err == nil {
+		metrics.ChatWebhookDeliveries.WithLabelValues(event, "delivered").Inc()
+		return
+	}
+
+	d.lastError = err.Error()
+	if errors.Is(err, errRejected) || d.attempts >= constants.WebhookMaxAttempts {
+		s.deadLetter(d)
+		return
+	}
+
+	metrics.ChatWebhookDeliveries.WithLabelValues(event, "retried").Inc()
+	s.scheduleRetry(d)
+}
+
+func (s *WebhookService) send(d *delivery) error {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, d.webhook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	timestamp := s.clock.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(domain.HeaderEvent, string(d.event.Type))
+	req.Header.Set(domain.HeaderID, d.event.ID)
+	req.Header.Set(domain.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(domain.HeaderSignature, domain.Sign(d.webhook.Secret, timestamp, d.body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("webhook request failed: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, constants.WebhookResponseBodyLimit))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w with status %d", errRejected, resp.StatusCode)
+	}
+}
+
+func (s *WebhookService) scheduleRetry(d *delivery) {
+	delay := s.retryDelay << (d.attempts - 1)
+	if delay > constants.WebhookRetryMaxDelay {
+		delay = constants.WebhookRetryMaxDelay
+	}
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		s.deadLetter(d)
+		return
+	}
+	s.retrying[d] = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		_, ok := s.retrying[d]
+		delete(s.retrying, d)
+		s.mu.Unlock()
+		if ok {
+			s.enqueue(d)
+		}
+	})
+	s.mu.Unlock()
+}
+
+func (s *WebhookService) deadLetter(d *delivery) {
+	event := string(d.event.Type)
+	metrics.ChatWebhookDeliveries.WithLabelValues(event, "dead_lettered").Inc()
+
+	fields := logger.Fields{
+		"user_id":    d.webhook.OwnerID,
+		"webhook_id": d.webhook.ID,
+		"event_id":   d.event.ID,
+		"event":      event,
+		"attempts":   d.attempts,
+		"action":     "webhook_dead_lettered",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), constants.WebhookStopTimeout)
+	defer cancel()
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		s.log.WithFields(ctx, fields).Errorf("failed to dead-letter webhook event: %v", err)
+		return
+	}
+	letter := domain.DeadLetter{
+		ID:        id,
+		WebhookID: d.webhook.ID,
+		EventID:   d.event.ID,
+		EventType: d.event.Type,
+		Payload:   d.body,
+		Attempts:  d.attempts,
+		LastError: d.lastError,
+	}
+	if !d.expiresAt.IsZero() {
+		expiresAt := d.expiresAt
+		letter.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.InsertDeadLetter(ctx, letter); err != nil {
+		s.log.WithFields(ctx, fields).Errorf("failed to dead-letter webhook event: %v", err)
+		return
+	}
+	s.log.WithFields(ctx, fields).Warnf("webhook event moved to dead letters: %s", d.lastError)
+}
+
+func normalizeEvents(events []domain.EventType) ([]domain.EventType, bool) {
+	if len(events) == 0 {
+		return nil, false
+	}
+	normalized := make([]domain.EventType, 0, len(events))
+	seen := make(map[domain.EventType]struct{}, len(events))
+	for _, event := range events {
+		if !event.Valid() {
+			return nil, false
+		}
+		if _, ok := seen[event]; ok {
+			continue
+		}
+		seen[event] = struct{}{}
+		normalized = append(normalized, event)
+	}
+	return normalized, true
+}
+
+func validURL(rawURL string) bool {
+	if rawURL == "" || len(rawURL) > constants.MaxWebhookURLLength || strings.TrimSpace(rawURL) != rawURL {
+		return false
+	}
+	endpoint, err := url.Parse(rawURL)
+	return err == nil && endpoint.Scheme == "https" && endpoint.Host != "" && endpoint.User == nil && commonhttp.IsPublicHost(endpoint.Hostname())
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, constants.WebhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func hasWebhook(webhooks []domain.Webhook, id string) bool {
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return true
+		}
+	}
+	return false
+}
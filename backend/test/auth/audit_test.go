@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	auditdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
+	auditexporter "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/exporter"
+	auditservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+type bufferWriteCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferWriteCloser) Close() error {
+	return nil
+}
+
+func setupAuthServiceWithAudit(t *testing.T) (*service.AuthService, *mockUserRepo, *mockHasher, *mockAuditService) {
+	t.Helper()
+	mockUserRepo := &mockUserRepo{}
+	mockHasher := &mockHasher{}
+	audit := &mockAuditService{}
+	log, _ := logtest.New(t)
+
+	authService := service.NewAuthService(
+		service.AuthServiceDeps{
+			Repo:             mockUserRepo,
+			IdentityService:  &mockIdentityService{},
+			RefreshTokenRepo: &mockRefreshTokenRepo{},
+			RevokedTokenRepo: &mockRevokedTokenRepo{},
+			Audit:            audit,
+			Hasher:           mockHasher,
+			IDGenerator:      &mockIDGenerator{},
+			Clock:            clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+			Log:              log,
+		},
+		service.AuthServiceConfig{
+			JWTSecret:               constants.TestJWTSecret,
+			AccessTokenTTL:          constants.TestAccessTokenTTL,
+			RefreshTokenTTL:         constants.DefaultRefreshTokenTTL,
+			MaxRefreshTokens:        constants.DefaultMaxRefreshTokensPerUser,
+			CircuitBreakerThreshold: constants.TestCircuitBreakerThreshold,
+			CircuitBreakerTimeout:   constants.TestCircuitBreakerTimeout,
+			CircuitBreakerReset:     constants.TestCircuitBreakerReset,
+		},
+	)
+
+	return authService, mockUserRepo, mockHasher, audit
+}
+
+func TestAuthService_Login_RecordsAuditEvents(t *testing.T) {
+	svc, mockUserRepo, mockHasher, audit := setupAuthServiceWithAudit(t)
+	ctx := context.Background()
+
+	mockUserRepo.findByUsernameFunc = func(ctx context.Context, username string) (userdomain.User, error) {
+		if username == "unknown" {
+			return userdomain.User{}, userrepo.ErrUserNotFound
+		}
+		return userdomain.User{ID: "user-123", Username: username, PasswordHash: "hash"}, nil
+	}
+	mockHasher.compareFunc = func(hash string, password string) error {
+		if password != "password123" {
+			return errors.New("mismatch")
+		}
+		return nil
+	}
+
+	if _, err := svc.Login(ctx, service.LoginInput{Username: "testuser", Password: "password123"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, _ = svc.Login(ctx, service.LoginInput{Username: "testuser", Password: "wrongpass1"})
+	_, _ = svc.Login(ctx, service.LoginInput{Username: "unknown", Password: "password123"})
+
+	expected := []auditdomain.EventType{
+		auditdomain.EventLoginSuccess,
+		auditdomain.EventLoginFailed,
+		auditdomain.EventLoginFailed,
+	}
+	got := audit.types()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d events, got %v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("event %d: expected %s, got %s", i, expected[i], got[i])
+		}
+	}
+	if audit.events[2].UserID != "" || audit.events[2].Metadata["reason"] != "user_not_found" {
+		t.Errorf("unexpected unknown-user event %+v", audit.events[2])
+	}
+}
+
+func TestAuthService_Logout_RecordsAuditEvents(t *testing.T) {
+	svc, _, _, audit := setupAuthServiceWithAudit(t)
+
+	err := svc.Logout(context.Background(), service.LogoutInput{
+		Claims: jwtverify.Claims{UserID: "user-123", JTI: "jti-123"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := audit.types()
+	if len(got) != 2 || got[0] != auditdomain.EventAccessTokenRevoked || got[1] != auditdomain.EventLogout {
+		t.Errorf("unexpected events %v", got)
+	}
+}
+
+func TestAuditService_ExportsJSONLines(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	buf := &bufferWriteCloser{}
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	svc := auditservice.NewAuditService(auditservice.AuditServiceDeps{
+		Exporter:    auditexporter.NewJSONLinesExporterWithWriter(buf, "auth"),
+		IDGenerator: &mockIDGenerator{},
+		Clock:       mockClock,
+		Log:         log,
+	})
+
+	ctx := context.WithValue(context.Background(), constants.ClientIPKey, "10.0.0.1")
+	svc.Record(ctx, auditdomain.Event{UserID: "user-123", Type: auditdomain.EventLoginSuccess})
+	svc.Record(ctx, auditdomain.Event{UserID: "user-123", Type: auditdomain.EventLogout})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var first map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatalf("decode line: %v", err)
+	}
+	if first["event_type"] != string(auditdomain.EventLoginSuccess) {
+		t.Errorf("expected event_type login_success, got %v", first["event_type"])
+	}
+	if first["client_ip"] != "10.0.0.1" {
+		t.Errorf("expected client_ip from context, got %v", first["client_ip"])
+	}
+	if first["service"] != "auth" || first["timestamp"] != "2024-01-01T12:00:00Z" {
+		t.Errorf("unexpected record %v", first)
+	}
+}
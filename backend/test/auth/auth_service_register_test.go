@@ -11,12 +11,12 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
-	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
 )
 
 func setupAuthService(t *testing.T) (*service.AuthService, *mockUserRepo, *mockIdentityService, *mockRefreshTokenRepo, *mockRevokedTokenRepo, *mockHasher, *mockIDGenerator, *clock.MockClock) {
-	_ = t
+	t.Helper()
 	mockUserRepo := &mockUserRepo{}
 	mockIdentityService := &mockIdentityService{}
 	mockRefreshTokenRepo := &mockRefreshTokenRepo{}
@@ -25,7 +25,7 @@ func setupAuthService(t *testing.T) (*service.AuthService, *mockUserRepo, *mockI
 	mockIDGenerator := &mockIDGenerator{}
 	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 
-	log, _ := logger.New("", "test", "info")
+	log, _ := logtest.New(t)
 
 	authService := service.NewAuthService(
 		service.AuthServiceDeps{
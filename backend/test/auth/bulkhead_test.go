@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+)
+
+func occupy(t *testing.T, bulkhead *resilience.Bulkhead, count int) (release func()) {
+	t.Helper()
+	started := make(chan struct{}, count)
+	done := make(chan struct{})
+	finished := make(chan struct{}, count)
+	for i := 0; i < count; i++ {
+		go func() {
+			_ = bulkhead.Call(context.Background(), func(ctx context.Context) error {
+				started <- struct{}{}
+				<-done
+				return nil
+			})
+			finished <- struct{}{}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("bulkhead did not admit call")
+		}
+	}
+	return func() {
+		close(done)
+		for i := 0; i < count; i++ {
+			<-finished
+		}
+	}
+}
+
+func TestBulkhead_RejectsWhenFullAndQueueDisabled(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{MaxConcurrent: 2})
+	release := occupy(t, bulkhead, 2)
+	defer release()
+
+	err := bulkhead.Call(context.Background(), okCall)
+	if !errors.Is(err, commonerrors.ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull, got %v", err)
+	}
+	if bulkhead.InFlight() != 2 {
+		t.Fatalf("expected 2 in-flight calls, got %d", bulkhead.InFlight())
+	}
+}
+
+func TestBulkhead_QueuedCallRunsWhenSlotFrees(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Second,
+	})
+	release := occupy(t, bulkhead, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- bulkhead.Call(context.Background(), okCall)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for bulkhead.QueueLen() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	release()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("expected queued call to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued call never ran")
+	}
+	if bulkhead.InFlight() != 0 || bulkhead.QueueLen() != 0 {
+		t.Fatalf("expected empty bulkhead, got in-flight=%d queued=%d", bulkhead.InFlight(), bulkhead.QueueLen())
+	}
+}
+
+func TestBulkhead_QueueTimeout(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  20 * time.Millisecond,
+	})
+	release := occupy(t, bulkhead, 1)
+	defer release()
+
+	err := bulkhead.Call(context.Background(), okCall)
+	if !errors.Is(err, commonerrors.ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull after queue timeout, got %v", err)
+	}
+	if bulkhead.QueueLen() != 0 {
+		t.Fatalf("expected timed out waiter to leave the queue, got %d", bulkhead.QueueLen())
+	}
+}
+
+func TestBulkhead_CanceledWaiterDoesNotLeakSlot(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Second,
+	})
+	release := occupy(t, bulkhead, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := bulkhead.Call(ctx, okCall)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline, got %v", err)
+	}
+
+	release()
+	if bulkhead.InFlight() != 0 || bulkhead.QueueLen() != 0 {
+		t.Fatalf("expected empty bulkhead, got in-flight=%d queued=%d", bulkhead.InFlight(), bulkhead.QueueLen())
+	}
+	if err := bulkhead.Call(context.Background(), okCall); err != nil {
+		t.Fatalf("expected bulkhead to accept calls after cancellation, got %v", err)
+	}
+}
+
+func TestBulkhead_AdaptiveLimitShrinksOnSlowCalls(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent:  8,
+		MinConcurrent:  2,
+		Adaptive:       true,
+		TargetLatency:  100 * time.Millisecond,
+		DecreaseFactor: 0.5,
+		Clock:          mockClock,
+	})
+
+	slowCall := func(ctx context.Context) error {
+		mockClock.SetTime(mockClock.Now().Add(time.Second))
+		return nil
+	}
+	for i := 0; i < 10; i++ {
+		_ = bulkhead.Call(context.Background(), slowCall)
+	}
+	if bulkhead.Limit() != 2 {
+		t.Fatalf("expected limit to shrink to the minimum 2, got %d", bulkhead.Limit())
+	}
+
+	for i := 0; i < 10; i++ {
+		_ = bulkhead.Call(context.Background(), okCall)
+	}
+	if bulkhead.Limit() != 2 {
+		t.Fatalf("expected unsaturated fast calls to keep the limit, got %d", bulkhead.Limit())
+	}
+}
+
+func TestBulkhead_AroundCircuitBreakerDoesNotCountRejections(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{Threshold: 1})
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{MaxConcurrent: 1})
+	guarded := bulkhead.Around(breaker)
+
+	release := occupy(t, bulkhead, 1)
+	defer release()
+
+	err := guarded.Call(context.Background(), okCall)
+	if !errors.Is(err, commonerrors.ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull, got %v", err)
+	}
+	if breaker.IsOpen() {
+		t.Fatal("expected bulkhead rejection not to trip the circuit breaker")
+	}
+}
+
+func TestBulkhead_SetLimitAdmitsQueuedCalls(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Second,
+	})
+	release := occupy(t, bulkhead, 1)
+	defer release()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- bulkhead.Call(context.Background(), okCall)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for bulkhead.QueueLen() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	bulkhead.SetLimit(2)
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("expected queued call to run after the limit was raised, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued call was not admitted after SetLimit")
+	}
+	if bulkhead.Limit() != 2 {
+		t.Fatalf("expected limit 2, got %d", bulkhead.Limit())
+	}
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v4"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+)
+
+var errDatabaseDown = errors.New("database down")
+
+type transitionRecorder struct {
+	mu          sync.Mutex
+	transitions []string
+}
+
+func (r *transitionRecorder) record(name string, from, to resilience.State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, from.String()+"->"+to.String())
+}
+
+func (r *transitionRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.transitions...)
+}
+
+func newTestBreaker(mockClock *clock.MockClock, config resilience.CircuitBreakerConfig) *resilience.CircuitBreaker {
+	config.Clock = mockClock
+	if config.Timeout == 0 {
+		config.Timeout = time.Second
+	}
+	if config.ResetAfter == 0 {
+		config.ResetAfter = 10 * time.Second
+	}
+	if config.Window == 0 {
+		config.Window = time.Minute
+	}
+	return resilience.NewCircuitBreaker(config)
+}
+
+func failCall(ctx context.Context) error {
+	return errDatabaseDown
+}
+
+func okCall(ctx context.Context) error {
+	return nil
+}
+
+func TestCircuitBreaker_OpensAndRecoversThroughHalfOpen(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	recorder := &transitionRecorder{}
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{
+		Threshold:        3,
+		HalfOpenMaxCalls: 2,
+		OnStateChange:    recorder.record,
+	})
+
+	for i := 0; i < 3; i++ {
+		_ = breaker.Call(context.Background(), failCall)
+	}
+	if breaker.State() != resilience.StateOpen {
+		t.Fatalf("expected open state, got %s", breaker.State())
+	}
+
+	called := false
+	err := breaker.Call(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, commonerrors.ErrCircuitOpen) || called {
+		t.Fatalf("expected open circuit to reject call, got err=%v called=%v", err, called)
+	}
+
+	mockClock.SetTime(mockClock.Now().Add(11 * time.Second))
+	if breaker.State() != resilience.StateHalfOpen {
+		t.Fatalf("expected half-open state, got %s", breaker.State())
+	}
+
+	if err := breaker.Call(context.Background(), okCall); err != nil {
+		t.Fatalf("expected trial call to succeed, got %v", err)
+	}
+	if breaker.State() != resilience.StateHalfOpen {
+		t.Fatalf("expected half-open after first trial, got %s", breaker.State())
+	}
+	if err := breaker.Call(context.Background(), okCall); err != nil {
+		t.Fatalf("expected trial call to succeed, got %v", err)
+	}
+	if breaker.State() != resilience.StateClosed {
+		t.Fatalf("expected closed after successful trials, got %s", breaker.State())
+	}
+
+	expected := []string{"closed->open", "open->half_open", "half_open->closed"}
+	got := recorder.list()
+	if len(got) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("transition %d: expected %s, got %s", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{Threshold: 1})
+
+	_ = breaker.Call(context.Background(), failCall)
+	mockClock.SetTime(mockClock.Now().Add(11 * time.Second))
+
+	if err := breaker.Call(context.Background(), failCall); !errors.Is(err, errDatabaseDown) {
+		t.Fatalf("expected trial call error, got %v", err)
+	}
+	if breaker.State() != resilience.StateOpen {
+		t.Fatalf("expected failed trial to reopen circuit, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenLimitsTrialCalls(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{
+		Threshold:        1,
+		HalfOpenMaxCalls: 1,
+	})
+
+	_ = breaker.Call(context.Background(), failCall)
+	mockClock.SetTime(mockClock.Now().Add(11 * time.Second))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- breaker.Call(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	if err := breaker.Call(context.Background(), okCall); !errors.Is(err, commonerrors.ErrCircuitOpen) {
+		t.Errorf("expected second trial call to be rejected, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected trial call to succeed, got %v", err)
+	}
+	if breaker.State() != resilience.StateClosed {
+		t.Errorf("expected closed after trial, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_FailureRateOverSlidingWindow(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{
+		Threshold:   100,
+		FailureRate: 0.5,
+		MinCalls:    4,
+		Window:      10 * time.Second,
+	})
+
+	_ = breaker.Call(context.Background(), failCall)
+	_ = breaker.Call(context.Background(), failCall)
+	_ = breaker.Call(context.Background(), okCall)
+
+	mockClock.SetTime(mockClock.Now().Add(20 * time.Second))
+
+	_ = breaker.Call(context.Background(), okCall)
+	_ = breaker.Call(context.Background(), okCall)
+	_ = breaker.Call(context.Background(), failCall)
+	if breaker.State() != resilience.StateClosed {
+		t.Fatalf("expected failures outside window to be forgotten, got %s", breaker.State())
+	}
+
+	_ = breaker.Call(context.Background(), failCall)
+	if breaker.State() != resilience.StateOpen {
+		t.Fatalf("expected 50%% failure rate to open circuit, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ErrorClassifiers(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{Threshold: 1})
+
+	_ = breaker.Call(context.Background(), func(ctx context.Context) error { return pgx.ErrNoRows })
+	_ = breaker.Call(context.Background(), func(ctx context.Context) error { return commonerrors.ErrUserNotFound })
+	_ = breaker.Call(context.Background(), func(ctx context.Context) error { return context.Canceled })
+	if breaker.State() != resilience.StateClosed {
+		t.Fatalf("expected non-infrastructure errors to be ignored, got %s", breaker.State())
+	}
+
+	errDuplicate := errors.New("duplicate key")
+	custom := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{
+		Threshold:  1,
+		Classifier: resilience.IgnoreErrors(nil, errDuplicate),
+	})
+	_ = custom.Call(context.Background(), func(ctx context.Context) error { return errDuplicate })
+	if custom.State() != resilience.StateClosed {
+		t.Fatalf("expected ignored error not to trip circuit, got %s", custom.State())
+	}
+	_ = custom.Call(context.Background(), failCall)
+	if custom.State() != resilience.StateOpen {
+		t.Fatalf("expected classified failure to trip circuit, got %s", custom.State())
+	}
+}
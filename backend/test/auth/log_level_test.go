@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+func newStdoutLogger(t *testing.T, config logger.Config) *logger.Logger {
+	t.Helper()
+	config.ServiceName = "test"
+	config.Sinks = logger.SinkStdout
+	log, err := logger.NewWithConfig(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return log
+}
+
+func TestLogger_ComponentLevelOverridesBaseLevel(t *testing.T) {
+	log := newStdoutLogger(t, logger.Config{Level: "INFO", ComponentLevels: "websocket=DEBUG"})
+	wsLog := log.Component("websocket")
+	dbLog := log.Component("db")
+
+	if log.ShouldLog(logger.DEBUG) {
+		t.Fatal("expected base logger to skip DEBUG")
+	}
+	if !wsLog.ShouldLog(logger.DEBUG) {
+		t.Fatal("expected websocket component to log DEBUG")
+	}
+	if dbLog.ShouldLog(logger.DEBUG) {
+		t.Fatal("expected components without override to follow base level")
+	}
+
+	log.SetLevel(logger.ERROR)
+	if dbLog.ShouldLog(logger.WARNING) {
+		t.Fatal("expected component loggers to follow runtime base level changes")
+	}
+}
+
+func TestLogger_ApplyLevelsIsAtomic(t *testing.T) {
+	log := newStdoutLogger(t, logger.Config{Level: "INFO"})
+
+	_, err := log.ApplyLevels(logger.Levels{
+		Level:      "DEBUG",
+		Components: map[string]string{"websocket": "LOUD"},
+	})
+	if err == nil {
+		t.Fatal("expected invalid component level to be rejected")
+	}
+	if log.ShouldLog(logger.DEBUG) {
+		t.Fatal("expected base level to stay unchanged after rejected update")
+	}
+
+	levels, err := log.ApplyLevels(logger.Levels{Components: map[string]string{"websocket": "debug"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if levels.Components["websocket"] != "DEBUG" || levels.Level != "INFO" {
+		t.Fatalf("unexpected levels: %+v", levels)
+	}
+
+	levels, err = log.ApplyLevels(logger.Levels{Components: map[string]string{"websocket": ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := levels.Components["websocket"]; ok {
+		t.Fatal("expected empty level to reset the component override")
+	}
+}
+
+func TestLogger_ReloadRestoresConfiguredLevels(t *testing.T) {
+	log := newStdoutLogger(t, logger.Config{Level: "WARNING", ComponentLevels: "websocket=INFO"})
+	if _, err := log.ApplyLevels(logger.Levels{Level: "DEBUG", Components: map[string]string{"db": "DEBUG"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	levels, err := log.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if levels.Level != "WARNING" || len(levels.Components) != 1 || levels.Components["websocket"] != "INFO" {
+		t.Fatalf("expected configured levels to be restored, got %+v", levels)
+	}
+}
+
+func TestLogger_ReloadReadsLevelFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log-level")
+	if err := os.WriteFile(path, []byte("# levels\nERROR\nwebsocket=DEBUG\n"), 0o600); err != nil {
+		t.Fatalf("failed to write level file: %v", err)
+	}
+	log := newStdoutLogger(t, logger.Config{Level: "INFO", LevelFile: path})
+
+	levels, err := log.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if levels.Level != "ERROR" || levels.Components["websocket"] != "DEBUG" {
+		t.Fatalf("unexpected levels from file: %+v", levels)
+	}
+}
+
+func TestParseSinks(t *testing.T) {
+	sinks, err := logger.ParseSinks(" stdout , syslog,stdout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sinks) != 2 || sinks[0] != logger.SinkStdout || sinks[1] != logger.SinkSyslog {
+		t.Fatalf("unexpected sinks: %v", sinks)
+	}
+	if _, err := logger.ParseSinks("kafka"); err == nil {
+		t.Fatal("expected unknown sink to be rejected")
+	}
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	log := newStdoutLogger(t, logger.Config{Level: "INFO"})
+	handler := commonhttp.LogLevelHandler(log)
+
+	body, _ := json.Marshal(logger.Levels{Components: map[string]string{"websocket": "DEBUG"}})
+	req := httptest.NewRequest(http.MethodPut, "/api/chat/admin/log-level", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !log.Component("websocket").ShouldLog(logger.DEBUG) {
+		t.Fatal("expected handler to apply component level")
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/chat/admin/log-level", bytes.NewReader([]byte(`{"level":"verbose"}`)))
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid level, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/chat/admin/log-level", nil)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	var levels logger.Levels
+	if err := json.NewDecoder(rec.Body).Decode(&levels); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if levels.Level != "INFO" || levels.Components["websocket"] != "DEBUG" {
+		t.Fatalf("unexpected levels: %+v", levels)
+	}
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	auditdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/audit/domain"
 	authdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/domain"
 	authrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/repository"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
@@ -245,6 +247,62 @@ func (m *mockRevokedTokenRepo) DeleteExpired(ctx context.Context) (int64, error)
 	return 0, nil
 }
 
+type mockRoleRepo struct {
+	findByUserIDFunc func(ctx context.Context, userID string) ([]string, error)
+	replaceRolesFunc func(ctx context.Context, userID string, roles []string) error
+}
+
+func (m *mockRoleRepo) FindByUserID(ctx context.Context, userID string) ([]string, error) {
+	if m.findByUserIDFunc != nil {
+		return m.findByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockRoleRepo) ReplaceRoles(ctx context.Context, userID string, roles []string) error {
+	if m.replaceRolesFunc != nil {
+		return m.replaceRolesFunc(ctx, userID, roles)
+	}
+	return nil
+}
+
+type mockAuditService struct {
+	mu     sync.Mutex
+	events []auditdomain.Event
+}
+
+func (m *mockAuditService) Record(ctx context.Context, event auditdomain.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockAuditService) ListForUser(ctx context.Context, userID string, before time.Time, limit int) ([]auditdomain.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]auditdomain.Event, 0, len(m.events))
+	for _, event := range m.events {
+		if event.UserID == userID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAuditService) Close() error {
+	return nil
+}
+
+func (m *mockAuditService) types() []auditdomain.EventType {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]auditdomain.EventType, 0, len(m.events))
+	for _, event := range m.events {
+		result = append(result, event.Type)
+	}
+	return result
+}
+
 type mockHasher struct {
 	hashFunc    func(password string) (string, error)
 	compareFunc func(hash string, password string) error
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestRedactor_DefaultRules(t *testing.T) {
+	redactor := logger.NewRedactor("salt", logger.DefaultRedactionRules)
+
+	fields := redactor.Apply(logger.Fields{
+		"jti":           "token-id-1",
+		"username":      "alice",
+		"password":      "hunter2",
+		"refresh_token": "raw-refresh",
+		"ciphertext":    "AAAA",
+		"token_user_id": "user-1",
+		"query":         "alice-search",
+		"action":        "login_attempt",
+	})
+
+	for _, dropped := range []string{"password", "refresh_token", "ciphertext"} {
+		if _, ok := fields[dropped]; ok {
+			t.Fatalf("expected %q to be dropped, got %v", dropped, fields)
+		}
+	}
+	if jti, _ := fields["jti"].(string); !strings.HasPrefix(jti, "h:") || strings.Contains(jti, "token-id-1") {
+		t.Fatalf("expected jti to be hashed, got %v", fields["jti"])
+	}
+	if fields["username"] == "alice" {
+		t.Fatal("expected username to be hashed")
+	}
+	if fields["query"] != "ali…" {
+		t.Fatalf("expected query to be truncated, got %v", fields["query"])
+	}
+	if fields["token_user_id"] != "user-1" || fields["action"] != "login_attempt" {
+		t.Fatalf("expected non-sensitive fields to pass through, got %v", fields)
+	}
+}
+
+func TestRedactor_HashDependsOnSalt(t *testing.T) {
+	first := logger.NewRedactor("deployment-a", logger.DefaultRedactionRules)
+	second := logger.NewRedactor("deployment-b", logger.DefaultRedactionRules)
+
+	a1 := first.Apply(logger.Fields{"jti": "same"})["jti"]
+	a2 := first.Apply(logger.Fields{"jti": "same"})["jti"]
+	b := second.Apply(logger.Fields{"jti": "same"})["jti"]
+
+	if a1 != a2 {
+		t.Fatal("expected hashing to be stable within a deployment")
+	}
+	if a1 == b {
+		t.Fatal("expected different salts to produce different hashes")
+	}
+}
+
+func TestParseRedactionRules(t *testing.T) {
+	rules, err := logger.ParseRedactionRules("username=truncate:2, user_id=hash,fingerprint=drop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	redactor := logger.NewRedactor("salt", logger.MergeRedactionRules(logger.DefaultRedactionRules, rules))
+	fields := redactor.Apply(logger.Fields{"username": "alice", "user_id": "u-1", "fingerprint": "ab:cd"})
+
+	if fields["username"] != "al…" {
+		t.Fatalf("expected override to truncate username, got %v", fields["username"])
+	}
+	if fields["user_id"] == "u-1" {
+		t.Fatal("expected user_id to be hashed")
+	}
+	if _, ok := fields["fingerprint"]; ok {
+		t.Fatal("expected fingerprint to be dropped")
+	}
+
+	for _, invalid := range []string{"jti", "jti=encrypt", "query=truncate:x"} {
+		if _, err := logger.ParseRedactionRules(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLogger_RedactsEveryEntry(t *testing.T) {
+	log, sink := logtest.NewWithConfig(t, logger.Config{Level: "DEBUG", RedactionSalt: "salt"})
+
+	log.Component("websocket").WithFields(context.Background(), logger.Fields{
+		"jti":          "secret-jti",
+		"access_token": "raw",
+		"action":       "ws_auth",
+	}).Info("authenticated")
+
+	entry, ok := sink.FindAction("ws_auth")
+	if !ok {
+		t.Fatal("expected entry to reach the sink")
+	}
+	if entry["jti"] == "secret-jti" {
+		t.Fatal("expected jti to be hashed before reaching the sink")
+	}
+	if _, ok := entry["access_token"]; ok {
+		t.Fatal("expected access_token to be dropped before reaching the sink")
+	}
+}
+
+func TestIsSensitiveFieldName(t *testing.T) {
+	for _, name := range []string{"token", "refresh_token", "AccessToken", "password", "password_hash", "jwt_secret", "ciphertext", "Authorization"} {
+		if !logger.IsSensitiveFieldName(name) {
+			t.Fatalf("expected %q to be sensitive", name)
+		}
+	}
+	for _, name := range []string{"token_user_id", "jti", "user_id", "action", "key_length"} {
+		if logger.IsSensitiveFieldName(name) {
+			t.Fatalf("expected %q not to be sensitive", name)
+		}
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) failures() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errors...)
+}
+
+func TestLogtest_FailsOnRedactedValuesInMessages(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	log, sink := logtest.New(tb)
+	sink.Protect("secret-jti")
+
+	log.Infof("token revoked jti=%s", "other-jti")
+	if failures := tb.failures(); len(failures) != 1 || !strings.Contains(failures[0], `"jti"`) {
+		t.Fatalf("expected an inline jti to fail the sink, got %v", failures)
+	}
+
+	log.Warnf("revocation failed for %s", "secret-jti")
+	if failures := tb.failures(); len(failures) != 2 || !strings.Contains(failures[1], "secret-jti") {
+		t.Fatalf("expected a protected value in the message to fail the sink, got %v", failures)
+	}
+
+	log.WithFields(context.Background(), logger.Fields{"jti": "secret-jti", "action": "ok"}).Info("token revoked")
+	if failures := tb.failures(); len(failures) != 2 {
+		t.Fatalf("expected a redacted field to pass the sink, got %v", failures)
+	}
+}
+
+func TestJWTMiddleware_KeepsJTIOutOfLogMessages(t *testing.T) {
+	const jti = "revoked-jti-1"
+	issuer := service.NewTokenIssuer(constants.TestJWTSecret, &mockIDGenerator{newIDFunc: func() (string, error) { return jti, nil }}, constants.TestAccessTokenTTL, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessTokenWithGrant(userdomain.User{ID: "user-123", Username: "testuser"}, service.AccessGrant{})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		repo   *mockRevokedTokenRepo
+		action string
+	}{
+		{name: "revoked", repo: &mockRevokedTokenRepo{isRevokedFunc: func(ctx context.Context, jti string) (bool, error) { return true, nil }}, action: "jwt_token_revoked"},
+		{name: "check failed", repo: &mockRevokedTokenRepo{isRevokedFunc: func(ctx context.Context, jti string) (bool, error) { return false, errors.New("db down") }}, action: "jwt_revocation_check_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, sink := logtest.New(t)
+			sink.Protect(jti)
+			handler := jwtverify.Middleware(constants.TestJWTSecret, log, tt.repo)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/chat/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entry, ok := sink.FindAction(tt.action)
+			if !ok {
+				t.Fatalf("expected %s to be logged", tt.action)
+			}
+			if hashed, _ := entry["jti"].(string); !strings.HasPrefix(hashed, "h:") || entry["path"] != "/api/chat/me" {
+				t.Errorf("expected jti and path as fields, got %v", entry)
+			}
+		})
+	}
+}
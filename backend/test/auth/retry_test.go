@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/db"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/resilience"
+)
+
+var errTransient = errors.New("transient")
+
+type sleepRecorder struct {
+	delays []time.Duration
+}
+
+func (s *sleepRecorder) sleep(ctx context.Context, delay time.Duration) error {
+	s.delays = append(s.delays, delay)
+	return ctx.Err()
+}
+
+func newTestRetryPolicy(recorder *sleepRecorder, maxAttempts int) *resilience.RetryPolicy {
+	return resilience.NewRetryPolicy(resilience.RetryConfig{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     35 * time.Millisecond,
+		Multiplier:     2,
+		Retryable: func(err error) bool {
+			return errors.Is(err, errTransient)
+		},
+		Sleep: recorder.sleep,
+	})
+}
+
+func TestRetryPolicy_RecoversWithExponentialBackoff(t *testing.T) {
+	recorder := &sleepRecorder{}
+	policy := newTestRetryPolicy(recorder, 5)
+
+	calls := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 4 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls)
+	}
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond}
+	if fmt.Sprint(recorder.delays) != fmt.Sprint(expected) {
+		t.Fatalf("expected delays %v, got %v", expected, recorder.delays)
+	}
+}
+
+func TestRetryPolicy_StopsAfterMaxAttempts(t *testing.T) {
+	recorder := &sleepRecorder{}
+	policy := newTestRetryPolicy(recorder, 3)
+
+	calls := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected last transient error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	if len(recorder.delays) != 2 {
+		t.Fatalf("expected 2 sleeps, got %d", len(recorder.delays))
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryPermanentErrors(t *testing.T) {
+	recorder := &sleepRecorder{}
+	policy := newTestRetryPolicy(recorder, 3)
+
+	calls := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return pgx.ErrNoRows
+	})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_AbortsWhenContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := resilience.NewRetryPolicy(resilience.RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Minute,
+		Retryable: func(err error) bool {
+			return errors.Is(err, errTransient)
+		},
+	})
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- policy.Do(ctx, func(ctx context.Context) error {
+			calls++
+			return errTransient
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if !errors.Is(err, errTransient) {
+			t.Fatalf("expected last error to be preserved, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("retry did not stop after cancellation")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_JitterStaysWithinBounds(t *testing.T) {
+	policy := resilience.NewRetryPolicy(resilience.RetryConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	})
+
+	for i := 0; i < 100; i++ {
+		delay := policy.Backoff(2)
+		if delay < 100*time.Millisecond || delay > 200*time.Millisecond {
+			t.Fatalf("expected jittered delay within [100ms, 200ms], got %v", delay)
+		}
+	}
+}
+
+func TestRetryPolicy_AroundCircuitBreaker(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	breaker := newTestBreaker(mockClock, resilience.CircuitBreakerConfig{
+		Threshold: 2,
+		Classifier: func(err error) bool {
+			return err != nil
+		},
+	})
+	recorder := &sleepRecorder{}
+	guarded := newTestRetryPolicy(recorder, 5).Around(breaker)
+
+	calls := 0
+	err := guarded.Call(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, commonerrors.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen once the breaker trips, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls before the breaker opened, got %d", calls)
+	}
+	if !breaker.IsOpen() {
+		t.Fatal("expected breaker to be open")
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"wrapped connection reset", fmt.Errorf("find user in database: %w", syscall.ECONNRESET), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"canceled", context.Canceled, false},
+		{"nil", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := db.IsTransientError(tc.err); got != tc.want {
+				t.Fatalf("IsTransientError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableTxError_OnlySerializationConflicts(t *testing.T) {
+	if !db.IsRetryableTxError(&pgconn.PgError{Code: "40001"}) {
+		t.Fatal("expected serialization failure to be retryable in a transaction")
+	}
+	if db.IsRetryableTxError(syscall.ECONNRESET) {
+		t.Fatal("expected ambiguous connection reset not to retry a transaction")
+	}
+}
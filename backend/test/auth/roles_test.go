@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+)
+
+func setupAuthServiceWithRoles(t *testing.T, roleRepo *mockRoleRepo) (*service.AuthService, *mockUserRepo) {
+	_ = t
+	mockUserRepo := &mockUserRepo{}
+	log, _ := logger.New("", "test", "info")
+
+	authService := service.NewAuthService(
+		service.AuthServiceDeps{
+			Repo:             mockUserRepo,
+			IdentityService:  &mockIdentityService{},
+			RefreshTokenRepo: &mockRefreshTokenRepo{},
+			RevokedTokenRepo: &mockRevokedTokenRepo{},
+			RoleRepo:         roleRepo,
+			Hasher:           &mockHasher{},
+			IDGenerator:      &mockIDGenerator{},
+			Clock:            clock.NewMockClock(time.Now()),
+			Log:              log,
+		},
+		service.AuthServiceConfig{
+			JWTSecret:               constants.TestJWTSecret,
+			AccessTokenTTL:          constants.TestAccessTokenTTL,
+			RefreshTokenTTL:         constants.DefaultRefreshTokenTTL,
+			MaxRefreshTokens:        constants.DefaultMaxRefreshTokensPerUser,
+			ScopedTokenMaxTTL:       constants.TestTokenExpiryOffset,
+			CircuitBreakerThreshold: constants.TestCircuitBreakerThreshold,
+			CircuitBreakerTimeout:   constants.TestCircuitBreakerTimeout,
+			CircuitBreakerReset:     constants.TestCircuitBreakerReset,
+		},
+	)
+
+	return authService, mockUserRepo
+}
+
+func TestTokenIssuer_IssueAccessTokenWithGrant_RoundTrip(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Now())
+	issuer := service.NewTokenIssuer(
+		constants.TestJWTSecret,
+		&mockIDGenerator{},
+		constants.TestAccessTokenTTL,
+		mockClock,
+	)
+
+	user := userdomain.User{ID: "user-123", Username: "testuser"}
+	token, _, err := issuer.IssueAccessTokenWithGrant(user, service.AccessGrant{
+		Roles:  []string{"bot"},
+		Scopes: []string{jwtverify.ScopeUsersRead},
+		TTL:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claims, err := issuer.ParseToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !claims.HasRole("bot") {
+		t.Errorf("expected role bot, got %v", claims.Roles)
+	}
+	if !claims.HasScope(jwtverify.ScopeUsersRead) || claims.HasScope(jwtverify.ScopeChatConnect) {
+		t.Errorf("unexpected scopes %v", claims.Scopes)
+	}
+	if want := mockClock.Now().Add(time.Hour).Unix(); claims.ExpiresAt.Unix() != want {
+		t.Errorf("expected exp %d, got %d", want, claims.ExpiresAt.Unix())
+	}
+}
+
+func TestTokenIssuer_IssueAccessToken_DefaultScopes(t *testing.T) {
+	issuer := service.NewTokenIssuer(
+		constants.TestJWTSecret,
+		&mockIDGenerator{},
+		constants.TestAccessTokenTTL,
+		clock.NewMockClock(time.Now()),
+	)
+
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: "user-123", Username: "testuser"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claims, err := issuer.ParseToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, scope := range jwtverify.DefaultScopes {
+		if !claims.HasScope(scope) {
+			t.Errorf("expected scope %s", scope)
+		}
+	}
+	if claims.HasScope(jwtverify.ScopeRolesAdmin) {
+		t.Error("expected no roles:admin scope for default user")
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	issuer := service.NewTokenIssuer(constants.TestJWTSecret, &mockIDGenerator{}, constants.TestAccessTokenTTL, clock.NewMockClock(time.Now()))
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		jwtverify.RequireScope(jwtverify.ScopeRolesAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})),
+	)
+
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected int
+	}{
+		{name: "missing scope", scopes: jwtverify.DefaultScopes, expected: http.StatusForbidden},
+		{name: "has scope", scopes: []string{jwtverify.ScopeRolesAdmin}, expected: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := issuer.IssueAccessTokenWithGrant(userdomain.User{ID: "user-123", Username: "testuser"}, service.AccessGrant{Scopes: tt.scopes})
+			if err != nil {
+				t.Fatalf("issue token: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/users/user-123/roles", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAuthService_SetUserRoles_Validation(t *testing.T) {
+	roleRepo := &mockRoleRepo{}
+	svc, _ := setupAuthServiceWithRoles(t, roleRepo)
+	ctx := context.Background()
+
+	if _, err := svc.SetUserRoles(ctx, "user-123", nil); !errors.Is(err, service.ErrEmptyRoles) {
+		t.Errorf("expected ErrEmptyRoles, got %v", err)
+	}
+	if _, err := svc.SetUserRoles(ctx, "user-123", []string{"superuser"}); !errors.Is(err, service.ErrUnknownRole) {
+		t.Errorf("expected ErrUnknownRole, got %v", err)
+	}
+}
+
+func TestAuthService_SetUserRoles_Success(t *testing.T) {
+	var stored []string
+	roleRepo := &mockRoleRepo{
+		replaceRolesFunc: func(ctx context.Context, userID string, roles []string) error {
+			stored = roles
+			return nil
+		},
+	}
+	svc, userRepo := setupAuthServiceWithRoles(t, roleRepo)
+	userRepo.findByIDFunc = func(ctx context.Context, id userdomain.ID) (userdomain.User, error) {
+		return userdomain.User{ID: id, Username: "testuser"}, nil
+	}
+
+	roles, err := svc.SetUserRoles(context.Background(), "user-123", []string{"admin", "user", "admin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(roles) != 2 || len(stored) != 2 {
+		t.Errorf("expected deduplicated roles, got %v (stored %v)", roles, stored)
+	}
+}
+
+func TestAuthService_IssueScopedToken(t *testing.T) {
+	svc, _ := setupAuthServiceWithRoles(t, &mockRoleRepo{})
+	ctx := context.Background()
+	caller := jwtverify.Claims{
+		UserID:    "user-123",
+		Username:  "testuser",
+		Roles:     []string{"user"},
+		Scopes:    jwtverify.DefaultScopes,
+		ExpiresAt: time.Now().Add(2 * time.Hour),
+	}
+
+	result, err := svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeUsersRead},
+		TTL:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Scopes) != 1 || result.Scopes[0] != jwtverify.ScopeUsersRead {
+		t.Errorf("unexpected scopes %v", result.Scopes)
+	}
+
+	_, err = svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeRolesAdmin},
+	})
+	if !errors.Is(err, service.ErrScopeNotGranted) {
+		t.Errorf("expected ErrScopeNotGranted, got %v", err)
+	}
+
+	_, err = svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeUsersRead},
+		TTL:    constants.TestTokenExpiryOffset + time.Hour,
+	})
+	if !errors.Is(err, service.ErrScopedTokenTTLTooLong) {
+		t.Errorf("expected ErrScopedTokenTTLTooLong, got %v", err)
+	}
+}
+
+func TestAuthService_IssueScopedToken_BoundedByParent(t *testing.T) {
+	svc, _ := setupAuthServiceWithRoles(t, &mockRoleRepo{})
+	ctx := context.Background()
+	caller := jwtverify.Claims{
+		UserID:    "user-123",
+		Username:  "testuser",
+		Roles:     []string{"user"},
+		Scopes:    jwtverify.DefaultScopes,
+		ExpiresAt: time.Now().Add(15 * time.Minute).Truncate(time.Second),
+	}
+
+	_, err := svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeUsersRead, jwtverify.ScopeTokensIssue},
+	})
+	if !errors.Is(err, service.ErrScopeNotDelegable) {
+		t.Errorf("expected ErrScopeNotDelegable, got %v", err)
+	}
+
+	result, err := svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeUsersRead, jwtverify.ScopeIdentityRead},
+		TTL:    24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.ExpiresAt.Equal(caller.ExpiresAt) {
+		t.Errorf("expected expiry capped at parent %v, got %v", caller.ExpiresAt, result.ExpiresAt)
+	}
+
+	issuer := service.NewTokenIssuer(constants.TestJWTSecret, &mockIDGenerator{}, constants.TestAccessTokenTTL, clock.NewRealClock())
+	child, err := issuer.ParseToken(result.Token)
+	if err != nil {
+		t.Fatalf("failed to parse scoped token: %v", err)
+	}
+	if child.ExpiresAt.After(caller.ExpiresAt) {
+		t.Errorf("scoped token outlives parent: %v > %v", child.ExpiresAt, caller.ExpiresAt)
+	}
+
+	_, err = svc.IssueScopedToken(ctx, child, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeUsersRead},
+	})
+	if !errors.Is(err, service.ErrScopeNotGranted) {
+		t.Errorf("expected scoped token to be unable to issue tokens, got %v", err)
+	}
+}
+
+func TestAuthService_IssueScopedToken_BotLongLived(t *testing.T) {
+	svc, _ := setupAuthServiceWithRoles(t, &mockRoleRepo{})
+	ctx := context.Background()
+	caller := jwtverify.Claims{
+		UserID:   "bot-123",
+		Username: "deploybot",
+		Roles:    []string{string(authdomain.RoleBot)},
+		Scopes:   authdomain.ScopesForRoles([]string{string(authdomain.RoleBot)}),
+	}
+	if caller.HasScope(jwtverify.ScopeChatConnect) || !caller.HasScope(jwtverify.ScopeMessagesSend) || !caller.HasScope(jwtverify.ScopeWebhooksManage) {
+		t.Fatalf("unexpected bot scopes %v", caller.Scopes)
+	}
+
+	result, err := svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeMessagesSend},
+		TTL:    90 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected long-lived bot token, got %v", err)
+	}
+	if len(result.Scopes) != 1 || result.Scopes[0] != jwtverify.ScopeMessagesSend {
+		t.Errorf("unexpected scopes %v", result.Scopes)
+	}
+
+	issuer := service.NewTokenIssuer(constants.TestJWTSecret, &mockIDGenerator{}, constants.TestAccessTokenTTL, clock.NewRealClock())
+	child, err := issuer.ParseToken(result.Token)
+	if err != nil {
+		t.Fatalf("failed to parse bot token: %v", err)
+	}
+	if !child.Derived || !child.HasRole(string(authdomain.RoleBot)) || child.HasScope(jwtverify.ScopeTokensIssue) {
+		t.Errorf("unexpected bot token claims %+v", child)
+	}
+	child.Scopes = append(child.Scopes, jwtverify.ScopeTokensIssue)
+	_, err = svc.IssueScopedToken(ctx, child, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeMessagesSend},
+		TTL:    90 * 24 * time.Hour,
+	})
+	if !errors.Is(err, service.ErrScopeNotGranted) {
+		t.Errorf("expected derived bot token to be unable to issue tokens, got %v", err)
+	}
+
+	_, err = svc.IssueScopedToken(ctx, caller, service.ScopedTokenInput{
+		Scopes: []string{jwtverify.ScopeMessagesSend},
+		TTL:    constants.DefaultBotTokenMaxTTL + time.Hour,
+	})
+	if !errors.Is(err, service.ErrScopedTokenTTLTooLong) {
+		t.Errorf("expected ErrScopedTokenTTLTooLong, got %v", err)
+	}
+}
+
+func TestAuthService_Login_UsesStoredRoles(t *testing.T) {
+	roleRepo := &mockRoleRepo{
+		findByUserIDFunc: func(ctx context.Context, userID string) ([]string, error) {
+			return []string{"admin"}, nil
+		},
+	}
+	svc, userRepo := setupAuthServiceWithRoles(t, roleRepo)
+	userRepo.findByUsernameFunc = func(ctx context.Context, username string) (userdomain.User, error) {
+		return userdomain.User{ID: "user-123", Username: username, PasswordHash: "hash"}, nil
+	}
+
+	result, err := svc.Login(context.Background(), service.LoginInput{Username: "testuser", Password: "password123"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claims, err := jwtverify.ParseToken(result.AccessToken, []byte(constants.TestJWTSecret))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !claims.HasRole("admin") || !claims.HasScope(jwtverify.ScopeRolesAdmin) {
+		t.Errorf("expected admin role and scope, got roles=%v scopes=%v", claims.Roles, claims.Scopes)
+	}
+}
@@ -0,0 +1,147 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gorillaWS "github.com/gorilla/websocket"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+)
+
+func testChunkPayload() websocket.FileChunkPayload {
+	return websocket.FileChunkPayload{
+		To:          peerA,
+		FileID:      "file-1",
+		ChunkIndex:  3,
+		TotalChunks: 10,
+		Ciphertext:  base64.StdEncoding.EncodeToString([]byte("encrypted chunk bytes")),
+		Nonce:       base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 12)),
+	}
+}
+
+func TestBinaryFrame_RoundTrip(t *testing.T) {
+	data, err := websocket.EncodeFileChunkFrame(testChunkPayload(), peerA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frame, err := websocket.ParseBinaryFrame(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frame.Peer() != peerA || frame.FileID() != "file-1" || frame.ChunkIndex() != 3 || frame.TotalChunks() != 10 {
+		t.Fatalf("unexpected header fields: peer=%s file=%s index=%d total=%d", frame.Peer(), frame.FileID(), frame.ChunkIndex(), frame.TotalChunks())
+	}
+	if string(frame.Ciphertext()) != "encrypted chunk bytes" || len(frame.Nonce()) != 12 {
+		t.Fatalf("unexpected body: ciphertext=%q nonce=%d bytes", frame.Ciphertext(), len(frame.Nonce()))
+	}
+
+	if err := frame.SetPeer(peerB); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frame.SetSequence(websocket.Sequence{Seq: 5, SeqEpoch: 42})
+	payload := frame.FileChunkPayload(peerA, frame.Peer())
+	expected := testChunkPayload()
+	if payload.From != peerB || payload.Seq != 5 || payload.SeqEpoch != 42 {
+		t.Fatalf("expected header to be rewritten in place, got %+v", payload)
+	}
+	if payload.Ciphertext != expected.Ciphertext || payload.Nonce != expected.Nonce {
+		t.Fatal("expected ciphertext and nonce to survive transcoding")
+	}
+}
+
+func TestParseBinaryFrame_RejectsMalformed(t *testing.T) {
+	data, err := websocket.EncodeFileChunkFrame(testChunkPayload(), peerA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrongVersion := append([]byte(nil), data...)
+	wrongVersion[0] = 9
+	for name, frame := range map[string][]byte{
+		"truncated":     data[:20],
+		"wrong_version": wrongVersion,
+		"short_nonce":   data[:len(data)-len("encrypted chunk bytes")-5],
+	} {
+		_, err := websocket.ParseBinaryFrame(frame)
+		de, ok := commonerrors.AsDomainError(err)
+		if !ok || de.Code() != commonerrors.ErrInvalidBinaryFrame.Code() {
+			t.Errorf("%s: expected ErrInvalidBinaryFrame, got %v", name, err)
+		}
+	}
+}
+
+func TestHub_DeliversFileChunksPerNegotiatedFormat(t *testing.T) {
+	hub, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	data, err := websocket.EncodeFileChunkFrame(testChunkPayload(), peerB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frame, err := websocket.ParseBinaryFrame(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := hub.SendToUserWithContext(context.Background(), sessionTestUserID, &websocket.WSMessage{Type: websocket.TypeFileChunk, Frame: frame}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := readWSMessage(t, conn)
+	var chunk websocket.FileChunkPayload
+	if err := json.Unmarshal(msg.Payload, &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	if msg.Type != websocket.TypeFileChunk || chunk.From != peerB || chunk.To != sessionTestUserID || chunk.Ciphertext != testChunkPayload().Ciphertext {
+		t.Fatalf("expected JSON client to receive a transcoded chunk, got %s %+v", msg.Type, chunk)
+	}
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	authPayload, _ := json.Marshal(websocket.AuthPayload{Token: token, BinaryFrames: true})
+	if err := conn.WriteJSON(websocket.WSMessage{Type: websocket.TypeAuth, Payload: authPayload}); err != nil {
+		t.Fatalf("failed to send auth: %v", err)
+	}
+	var response websocket.AuthResponsePayload
+	if err := json.Unmarshal(readWSMessage(t, conn).Payload, &response); err != nil || !response.BinaryFrames {
+		t.Fatalf("expected binary frames to be acknowledged, got %+v", response)
+	}
+
+	if err := hub.SendToUserWithContext(context.Background(), sessionTestUserID, &websocket.WSMessage{Type: websocket.TypeFileChunk, Frame: frame}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jsonChunk := testChunkPayload()
+	jsonChunk.From = peerB
+	payload, _ := json.Marshal(jsonChunk)
+	if err := hub.SendToUserWithContext(context.Background(), sessionTestUserID, &websocket.WSMessage{Type: websocket.TypeFileChunk, Payload: payload}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		messageType, received, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		if messageType != gorillaWS.BinaryMessage || !bytes.Equal(received, data) {
+			t.Fatalf("expected binary client to receive the raw frame, got type %d", messageType)
+		}
+	}
+}
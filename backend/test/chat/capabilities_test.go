@@ -0,0 +1,127 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestNegotiateCapabilities(t *testing.T) {
+	legacy := websocket.NegotiateCapabilities(0, nil)
+	if legacy.Version != websocket.ProtocolVersionLegacy || !legacy.Supports(websocket.TypeMessageEdit) || legacy.Supports(websocket.TypeBackpressure) {
+		t.Fatalf("expected clients without hello to get the legacy message set, got %+v", legacy.Features())
+	}
+
+	future := websocket.NegotiateCapabilities(websocket.ProtocolVersion+3, nil)
+	if future.Version != websocket.ProtocolVersion || !future.Supports(websocket.TypeBackpressure) {
+		t.Fatalf("expected newer clients to be clamped to the server version, got %d", future.Version)
+	}
+
+	declared := websocket.NegotiateCapabilities(websocket.ProtocolVersion, []string{"message", "typing", "hologram"})
+	for msgType, want := range map[websocket.MessageType]bool{
+		websocket.TypeMessage:     true,
+		websocket.TypeTyping:      true,
+		websocket.TypeAck:         true,
+		websocket.TypeMessageEdit: false,
+		"hologram":                false,
+	} {
+		if declared.Supports(msgType) != want {
+			t.Errorf("expected Supports(%s) = %v", msgType, want)
+		}
+	}
+}
+
+type capabilitySender struct {
+	recordingSender
+	caps   websocket.Capabilities
+	mu     sync.Mutex
+	errors []error
+}
+
+func (s *capabilitySender) SupportsMessageType(userID string, msgType websocket.MessageType) bool {
+	return s.caps.Supports(msgType)
+}
+
+func (s *capabilitySender) SendErrorToUser(userID string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, err)
+}
+
+func TestMessageRouter_RespectsRecipientCapabilities(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &capabilitySender{caps: websocket.NegotiateCapabilities(websocket.ProtocolVersion, []string{"message"})}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender: sender,
+		Log:    log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	edit, _ := json.Marshal(websocket.MessageEditPayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"})
+	if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeMessageEdit, Payload: edit}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected unsupported message_edit not to be forwarded, got %d messages", len(sender.sent))
+	}
+
+	read, _ := json.Marshal(websocket.MessageReadPayload{To: peerA, MessageID: "m-1"})
+	if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeMessageRead, Payload: read}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Type != websocket.TypeAck {
+		t.Fatalf("expected message_read to fall back to ack, got %+v", sender.sent)
+	}
+	var ack websocket.AckPayload
+	if err := json.Unmarshal(sender.sent[0].Payload, &ack); err != nil || ack.MessageID != "m-1" || ack.To != peerA {
+		t.Fatalf("expected fallback ack for m-1, got %s", sender.sent[0].Payload)
+	}
+}
+
+func TestClient_HelloNegotiatesCapabilities(t *testing.T) {
+	hub, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if !hub.SupportsMessageType(sessionTestUserID, websocket.TypeMessageEdit) || hub.SupportsMessageType(sessionTestUserID, websocket.TypeBackpressure) {
+		t.Fatal("expected a client without hello to be treated as legacy")
+	}
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	authPayload, _ := json.Marshal(websocket.AuthPayload{
+		Token:           token,
+		ProtocolVersion: websocket.ProtocolVersion,
+		Features:        []string{"message", "backpressure"},
+	})
+	if err := conn.WriteJSON(websocket.WSMessage{Type: websocket.TypeAuth, Payload: authPayload}); err != nil {
+		t.Fatalf("failed to send auth: %v", err)
+	}
+
+	var response websocket.AuthResponsePayload
+	if err := json.Unmarshal(readWSMessage(t, conn).Payload, &response); err != nil {
+		t.Fatalf("failed to decode auth response: %v", err)
+	}
+	if response.ProtocolVersion != websocket.ProtocolVersion || !slices.Contains(response.Features, "backpressure") {
+		t.Fatalf("expected server hello with version and features, got %+v", response)
+	}
+	if hub.SupportsMessageType(sessionTestUserID, websocket.TypeMessageEdit) || !hub.SupportsMessageType(sessionTestUserID, websocket.TypeBackpressure) {
+		t.Fatal("expected declared features to replace the legacy message set")
+	}
+}
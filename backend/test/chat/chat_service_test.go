@@ -9,16 +9,16 @@ import (
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
 	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
-	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
 	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
 	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
 )
 
 func setupChatService(t *testing.T) (*service.ChatService, *mockUserRepo, *mockIdentityService) {
 	t.Helper()
 	mockRepo := newMockUserRepo()
 	mockIdentity := newMockIdentityService()
-	log, _ := logger.New("", "test", "info")
+	log, _ := logtest.New(t)
 	svc := service.NewChatService(service.ChatServiceDeps{
 		Repo:            mockRepo,
 		IdentityService: mockIdentity,
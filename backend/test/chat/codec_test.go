@@ -0,0 +1,286 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaWS "github.com/gorilla/websocket"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+var codecs = []websocket.Codec{websocket.JSONCodec, websocket.ProtobufCodec}
+
+func codecFixtures() map[websocket.MessageType]interface{} {
+	ciphertext := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("ciphertext", 40)))
+	nonce := base64.StdEncoding.EncodeToString([]byte("123456789012"))
+	return map[websocket.MessageType]interface{}{
+		websocket.TypeMessage: &websocket.MessagePayload{
+			Sequence:   websocket.Sequence{Seq: 7, SeqEpoch: 1700000000000000},
+			To:         peerA,
+			From:       peerB,
+			MessageID:  "msg-1",
+			Ciphertext: ciphertext,
+			Nonce:      nonce,
+			ReplyToID:  "msg-0",
+		},
+		websocket.TypeFileChunk: &websocket.FileChunkPayload{
+			To:          peerA,
+			FileID:      "file-1",
+			ChunkIndex:  4,
+			TotalChunks: 9,
+			Ciphertext:  ciphertext,
+			Nonce:       nonce,
+		},
+		websocket.TypeFileStart: &websocket.FileStartPayload{
+			To:          peerA,
+			FileID:      "file-1",
+			Filename:    "report.pdf",
+			MimeType:    "application/pdf",
+			TotalSize:   1 << 20,
+			TotalChunks: 9,
+			ChunkSize:   128 << 10,
+		},
+		websocket.TypeEphemeralKey: &websocket.EphemeralKeyPayload{To: peerA, PublicKey: nonce, Signature: ciphertext, MessageID: "k-1", RequiresAck: true},
+		websocket.TypeAck:          &websocket.AckPayload{To: peerA, MessageID: "msg-1"},
+		websocket.TypeTyping:       &websocket.TypingPayload{To: peerA, IsTyping: true},
+		websocket.TypeReaction:     &websocket.ReactionPayload{To: peerA, MessageID: "msg-1", Emoji: "👍", Action: "add"},
+		websocket.TypeBackpressure: &websocket.BackpressurePayload{State: websocket.BackpressureRejected, Queued: 256, Limit: 256, RejectedType: "file_chunk", RetryAfterMs: 500},
+		websocket.TypeError:        &websocket.ErrorPayload{Code: "INVALID_PAYLOAD", Message: "invalid payload"},
+	}
+}
+
+func TestCodecs_RoundTripPayloads(t *testing.T) {
+	for _, codec := range codecs {
+		for msgType, payload := range codecFixtures() {
+			payloadBytes, _ := json.Marshal(payload)
+			data, err := codec.Encode(&websocket.WSMessage{Type: msgType, Payload: payloadBytes, TraceParent: "00-trace-span-01"})
+			if err != nil {
+				t.Fatalf("%s: failed to encode %s: %v", codec.Name(), msgType, err)
+			}
+			decoded, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("%s: failed to decode %s: %v", codec.Name(), msgType, err)
+			}
+			if decoded.Type != msgType || decoded.TraceParent != "00-trace-span-01" {
+				t.Fatalf("%s: envelope mismatch for %s: %+v", codec.Name(), msgType, decoded)
+			}
+
+			got := reflect.New(reflect.TypeOf(payload).Elem()).Interface()
+			if err := decoded.DecodePayload(got); err != nil {
+				t.Fatalf("%s: failed to unmarshal %s payload: %v", codec.Name(), msgType, err)
+			}
+			if !reflect.DeepEqual(got, payload) {
+				t.Fatalf("%s: payload mismatch for %s: got %+v, want %+v", codec.Name(), msgType, got, payload)
+			}
+
+			for _, target := range codecs {
+				data, err := target.Encode(decoded)
+				if err != nil {
+					t.Fatalf("%s->%s: failed to re-encode %s: %v", codec.Name(), target.Name(), msgType, err)
+				}
+				redecoded, err := target.Decode(data)
+				if err != nil {
+					t.Fatalf("%s->%s: failed to decode %s: %v", codec.Name(), target.Name(), msgType, err)
+				}
+				forwarded := reflect.New(reflect.TypeOf(payload).Elem()).Interface()
+				if err := redecoded.DecodePayload(forwarded); err != nil || !reflect.DeepEqual(forwarded, payload) {
+					t.Fatalf("%s->%s: payload mismatch for %s: got %+v, err=%v", codec.Name(), target.Name(), msgType, forwarded, err)
+				}
+			}
+		}
+	}
+}
+
+func TestProtobufCodec_AuthSharesOneSchema(t *testing.T) {
+	request, _ := json.Marshal(websocket.AuthPayload{Token: "token", BinaryFrames: true})
+	data, err := websocket.ProtobufCodec.Encode(&websocket.WSMessage{Type: websocket.TypeAuth, Payload: request})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := websocket.ProtobufCodec.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var auth websocket.AuthPayload
+	if err := decoded.DecodePayload(&auth); err != nil || auth.Token != "token" || !auth.BinaryFrames {
+		t.Fatalf("expected auth request to survive, got %+v", auth)
+	}
+
+	response, _ := json.Marshal(websocket.AuthResponsePayload{Authenticated: true, ExpiresAt: "2026-01-01T00:00:00Z"})
+	data, err = websocket.ProtobufCodec.Encode(&websocket.WSMessage{Type: websocket.TypeAuth, Payload: response})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded, err = websocket.ProtobufCodec.Decode(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var authResponse websocket.AuthResponsePayload
+	if err := decoded.DecodePayload(&authResponse); err != nil || !authResponse.Authenticated || authResponse.ExpiresAt == "" {
+		t.Fatalf("expected auth response to survive, got %+v", authResponse)
+	}
+}
+
+func TestMessageRouter_ForwardsProtobufDecodedPayload(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{Sender: sender, Log: log}, websocket.MessageRouterConfig{})
+
+	payload, _ := json.Marshal(websocket.MessagePayload{To: peerA, MessageID: "msg-1", Ciphertext: "Y2lwaGVy", Nonce: "bm9uY2U="})
+	data, err := websocket.ProtobufCodec.Encode(&websocket.WSMessage{Type: websocket.TypeMessage, Payload: payload})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg, err := websocket.ProtobufCodec.Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := router.Route(context.Background(), &websocket.Client{}, msg); err != nil {
+		t.Fatalf("unexpected error routing protobuf message: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected message to be forwarded, got %d", len(sender.sent))
+	}
+
+	for _, codec := range codecs {
+		data, err := codec.Encode(sender.sent[0])
+		if err != nil {
+			t.Fatalf("%s: failed to encode forwarded message: %v", codec.Name(), err)
+		}
+		forwarded, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("%s: failed to decode forwarded message: %v", codec.Name(), err)
+		}
+		var got websocket.MessagePayload
+		if err := forwarded.DecodePayload(&got); err != nil || got.MessageID != "msg-1" || got.Ciphertext != "Y2lwaGVy" || got.To != peerA {
+			t.Errorf("%s: expected forwarded payload to keep its fields, got %+v err=%v", codec.Name(), got, err)
+		}
+	}
+}
+
+func TestProtobufCodec_RejectsUnsupportedVersion(t *testing.T) {
+	data, err := websocket.ProtobufCodec.Encode(&websocket.WSMessage{Type: websocket.TypeAck, Payload: json.RawMessage(`{"to":"x","message_id":"m"}`)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data[1] = websocket.SchemaVersion + 1
+	if _, err := websocket.ProtobufCodec.Decode(data); err == nil {
+		t.Fatal("expected unsupported schema version to be rejected")
+	}
+}
+
+func TestHub_EncodesWithNegotiatedSubprotocol(t *testing.T) {
+	log, _ := logtest.New(t)
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10, SendTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+
+	upgrader := gorillaWS.Upgrader{Subprotocols: websocket.Subprotocols()}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		claims := jwtverify.Claims{UserID: sessionTestUserID, Username: "alice", ExpiresAt: time.Now().Add(time.Hour)}
+		client := websocket.NewAuthenticatedClient(hub, conn, claims, constants.TestJWTSecret, log, nil,
+			time.Second, time.Minute, 30*time.Second, 1<<20, 16)
+		hub.Register(client)
+		client.Start()
+	}))
+	dialer := gorillaWS.Dialer{Subprotocols: []string{websocket.SubprotocolProtobuf}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		server.Close()
+	})
+	if conn.Subprotocol() != websocket.SubprotocolProtobuf {
+		t.Fatalf("expected protobuf subprotocol to be selected, got %q", conn.Subprotocol())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !hub.IsUserOnline(sessionTestUserID) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	payload, _ := json.Marshal(codecFixtures()[websocket.TypeMessage])
+	if err := hub.SendToUserWithContext(context.Background(), sessionTestUserID, &websocket.WSMessage{Type: websocket.TypeMessage, Payload: payload}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	if messageType != gorillaWS.BinaryMessage {
+		t.Fatalf("expected a binary frame, got type %d", messageType)
+	}
+	msg, err := websocket.ProtobufCodec.Decode(data)
+	if err != nil || msg.Type != websocket.TypeMessage {
+		t.Fatalf("expected a protobuf encoded message, got %+v err=%v", msg, err)
+	}
+	if len(data) >= len(payload) {
+		t.Errorf("expected protobuf frame (%d bytes) to be smaller than the JSON payload (%d bytes)", len(data), len(payload))
+	}
+}
+
+func BenchmarkCodec_Encode(b *testing.B) {
+	for _, codec := range codecs {
+		for _, msgType := range []websocket.MessageType{websocket.TypeMessage, websocket.TypeFileChunk} {
+			payload, _ := json.Marshal(codecFixtures()[msgType])
+			data, err := codec.Encode(&websocket.WSMessage{Type: msgType, Payload: payload})
+			if err != nil {
+				b.Fatal(err)
+			}
+			msg, err := codec.Decode(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(codec.Name()+"/"+string(msgType), func(b *testing.B) {
+				var size int
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					data, err := codec.Encode(msg)
+					if err != nil {
+						b.Fatal(err)
+					}
+					size = len(data)
+				}
+				b.ReportMetric(float64(size), "bytes/msg")
+			})
+		}
+	}
+}
+
+func BenchmarkCodec_Decode(b *testing.B) {
+	for _, codec := range codecs {
+		for _, msgType := range []websocket.MessageType{websocket.TypeMessage, websocket.TypeFileChunk} {
+			payload, _ := json.Marshal(codecFixtures()[msgType])
+			data, err := codec.Encode(&websocket.WSMessage{Type: msgType, Payload: payload})
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(codec.Name()+"/"+string(msgType), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := codec.Decode(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	conversationdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+	conversationrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/repository"
+	conversationservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/service"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestConversationService_MergesActivityPerConversation(t *testing.T) {
+	log, _ := logtest.New(t)
+	now := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	repo := &mockConversationRepo{}
+	conversations := conversationservice.NewConversationService(context.Background(), conversationservice.ConversationServiceDeps{
+		Repo:  repo,
+		Clock: clock.NewMockClock(now),
+		Log:   log,
+	})
+
+	conversations.RecordMessage(peerA, peerB)
+	conversations.RecordMessage(peerA, peerB)
+	conversations.MarkRead(peerB, peerA)
+	conversations.RecordMessage(peerA, peerB)
+	conversations.MarkRead(peerA, peerA)
+	conversations.Stop()
+
+	got := make(map[string]conversationdomain.Activity)
+	for _, activity := range repo.activity {
+		got[activity.OwnerID] = activity
+	}
+	if len(repo.activity) != 2 {
+		t.Fatalf("expected one update per conversation side, got %+v", repo.activity)
+	}
+	if sender := got[peerA]; sender.PeerID != peerB || sender.Unread != 0 || sender.ResetUnread || !sender.At.Equal(now) {
+		t.Errorf("unexpected sender activity: %+v", sender)
+	}
+	if recipient := got[peerB]; recipient.PeerID != peerA || recipient.Unread != 1 || !recipient.ResetUnread || !recipient.At.Equal(now) {
+		t.Errorf("expected read to reset earlier messages only, got %+v", recipient)
+	}
+}
+
+type recordingConversations struct {
+	mu       sync.Mutex
+	messages [][2]string
+	reads    [][2]string
+}
+
+func (r *recordingConversations) RecordMessage(senderID, recipientID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, [2]string{senderID, recipientID})
+}
+
+func (r *recordingConversations) MarkRead(userID, peerID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads = append(r.reads, [2]string{userID, peerID})
+}
+
+func TestMessageRouter_RecordsConversationActivity(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{offline: map[string]bool{peerB: true}}
+	userRepo := newMockUserRepo()
+	userRepo.findByIDFunc = func(ctx context.Context, id userdomain.ID) (userdomain.User, error) {
+		return userdomain.User{ID: id}, nil
+	}
+	presence := websocket.NewPresenceService(context.Background(), websocket.PresenceServiceDeps{
+		Sender:   sender,
+		UserRepo: userRepo,
+		Log:      log,
+		Clock:    clock.NewRealClock(),
+	}, websocket.PresenceServiceConfig{})
+	privacy := &stubPrivacy{}
+	conversations := &recordingConversations{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:        sender,
+		Presence:      presence,
+		Privacy:       privacy,
+		Conversations: conversations,
+		Log:           log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("unexpected error routing %s: %v", msgType, err)
+		}
+	}
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"})
+	route(websocket.TypeTyping, websocket.TypingPayload{To: peerA})
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerA, MessageID: "m-2"})
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerB, MessageID: "m-4", Ciphertext: "c", Nonce: "n"})
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerB, MessageID: "m-5"})
+	privacy.noReadReceipts = map[string]bool{"": true}
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerB, MessageID: "m-3"})
+
+	if len(conversations.messages) != 1 || conversations.messages[0] != [2]string{"", peerA} {
+		t.Errorf("expected only the delivered message to count as activity, got %+v", conversations.messages)
+	}
+	if len(conversations.reads) != 3 || conversations.reads[1] != [2]string{"", peerB} || conversations.reads[2] != [2]string{"", peerB} {
+		t.Errorf("expected reads to be recorded while the peer is offline and when read receipts are disabled, got %+v", conversations.reads)
+	}
+}
+
+func TestHandler_ListAndUpdateConversations(t *testing.T) {
+	log, _ := logtest.New(t)
+	lastActivity := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo := &mockConversationRepo{}
+	var gotArchived bool
+	repo.listFunc = func(ctx context.Context, ownerID string, archived bool, limit int) ([]conversationdomain.Conversation, error) {
+		gotArchived = archived
+		return []conversationdomain.Conversation{{OwnerID: ownerID, PeerID: peerA, LastActivityAt: lastActivity, UnreadCount: 3, Pinned: true}}, nil
+	}
+	var gotUpdate conversationdomain.Update
+	repo.updateFunc = func(ctx context.Context, ownerID, peerID string, update conversationdomain.Update) (conversationdomain.Conversation, error) {
+		if peerID != peerA {
+			return conversationdomain.Conversation{}, conversationrepo.ErrConversationNotFound
+		}
+		gotUpdate = update
+		return conversationdomain.Conversation{OwnerID: ownerID, PeerID: peerID, LastActivityAt: lastActivity, Muted: *update.Muted}, nil
+	}
+	conversations := conversationservice.NewConversationService(context.Background(), conversationservice.ConversationServiceDeps{Repo: repo, Log: log})
+	defer conversations.Stop()
+
+	chatSvc := service.NewChatService(service.ChatServiceDeps{
+		Repo:            newMockUserRepo(),
+		IdentityService: newMockIdentityService(),
+		Conversations:   conversations,
+		Log:             log,
+	})
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		chathttp.NewHandler(chatSvc, hub, config.ChatConfig{RequestTimeout: time.Second}, log, nil),
+	)
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	do := func(method, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/chat/conversations?archived=true", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var list []struct {
+		PeerID         string    `json:"peer_id"`
+		LastActivityAt time.Time `json:"last_activity_at"`
+		UnreadCount    int       `json:"unread_count"`
+		Pinned         bool      `json:"pinned"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Fatalf("unexpected conversations response: %s", rec.Body.String())
+	}
+	if list[0].PeerID != peerA || list[0].UnreadCount != 3 || !list[0].Pinned || !list[0].LastActivityAt.Equal(lastActivity) || !gotArchived {
+		t.Errorf("unexpected conversation: %+v", list[0])
+	}
+
+	rec = do(http.MethodPatch, "/api/chat/conversations/"+peerA, []byte(`{"muted":true,"read":true}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if gotUpdate.Muted == nil || !*gotUpdate.Muted || !gotUpdate.MarkRead || gotUpdate.Archived != nil || gotUpdate.Pinned != nil {
+		t.Errorf("unexpected update forwarded: %+v", gotUpdate)
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPatch, "/api/chat/conversations/" + peerB, `{"pinned":true}`, http.StatusNotFound},
+		{http.MethodPatch, "/api/chat/conversations/" + peerA, `{}`, http.StatusBadRequest},
+		{http.MethodPatch, "/api/chat/conversations/not-a-uuid", `{"pinned":true}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/chat/conversations?archived=maybe", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/chat/conversations/" + peerA, "", http.StatusMethodNotAllowed},
+		{http.MethodPatch, "/api/chat/conversations/" + peerA + "/receipts", `{"pinned":true}`, http.StatusMethodNotAllowed},
+	} {
+		if rec := do(tc.method, tc.target, []byte(tc.body)); rec.Code != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.target, tc.status, rec.Code)
+		}
+	}
+}
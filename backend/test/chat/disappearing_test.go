@@ -0,0 +1,165 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	disappearingservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestTimerService_ValidatesAndCachesPerPair(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := newMockTimerRepo()
+	timers := disappearingservice.NewTimerService(disappearingservice.TimerServiceDeps{Repo: repo, Log: log})
+	ctx := context.Background()
+
+	for _, ttl := range []time.Duration{time.Second, constants.MaxDisappearingTTL + time.Second, -time.Minute} {
+		if err := timers.SetTTL(ctx, peerA, peerB, ttl); !errors.Is(err, commonerrors.ErrInvalidDisappearingTimer) {
+			t.Errorf("expected ttl %v to be rejected, got %v", ttl, err)
+		}
+	}
+	if err := timers.SetTTL(ctx, peerA, peerB, time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, pair := range [][2]string{{peerA, peerB}, {peerB, peerA}} {
+		ttl, err := timers.TTL(ctx, pair[0], pair[1])
+		if err != nil || ttl != time.Hour {
+			t.Errorf("TTL(%s, %s) = %v, %v, want %v", pair[0], pair[1], ttl, err, time.Hour)
+		}
+	}
+	if repo.lookups != 0 {
+		t.Errorf("expected the saved timer to be served from cache, got %d lookups", repo.lookups)
+	}
+
+	repo.err = errors.New("db down")
+	_, err := timers.TTL(ctx, peerA, sessionTestUserID)
+	if de, ok := commonerrors.AsDomainError(err); !ok || de.Code() != commonerrors.ErrDisappearingTimerFailed.Code() {
+		t.Fatalf("expected lookup failure to surface, got %v", err)
+	}
+}
+
+func TestMessageRouter_StampsExpiryFromTimer(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	timers := disappearingservice.NewTimerService(disappearingservice.TimerServiceDeps{Repo: newMockTimerRepo(), Log: log})
+	clk := clock.NewMockClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender: sender,
+		Timers: timers,
+		Clock:  clk,
+		Log:    log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) error {
+		data, _ := json.Marshal(payload)
+		return router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data})
+	}
+	forged := websocket.Expiry{TTLSeconds: 1, ExpiresAt: "2000-01-01T00:00:00Z"}
+	if err := route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n", Expiry: forged}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := route(websocket.TypeDisappearingTimer, websocket.DisappearingTimerPayload{To: peerA, TTLSeconds: 3600}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-2", Ciphertext: "c", Nonce: "n"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := route(websocket.TypeDisappearingTimer, websocket.DisappearingTimerPayload{To: peerA, TTLSeconds: 1}); !errors.Is(err, commonerrors.ErrInvalidDisappearingTimer) {
+		t.Fatalf("expected out of range timer to be rejected, got %v", err)
+	}
+
+	if len(sender.sent) != 3 {
+		t.Fatalf("expected 3 forwarded messages, got %d", len(sender.sent))
+	}
+	var first websocket.MessagePayload
+	if err := json.Unmarshal(sender.sent[0].Payload, &first); err != nil {
+		t.Fatalf("failed to decode forwarded payload: %v", err)
+	}
+	if first.TTLSeconds != 0 || first.ExpiresAt != "" || !sender.sent[0].ExpiresAt.IsZero() {
+		t.Errorf("expected client supplied expiry to be cleared, got %+v", first.Expiry)
+	}
+
+	var timer websocket.DisappearingTimerPayload
+	if err := json.Unmarshal(sender.sent[1].Payload, &timer); err != nil {
+		t.Fatalf("failed to decode forwarded timer: %v", err)
+	}
+	if sender.sent[1].Type != websocket.TypeDisappearingTimer || timer.TTLSeconds != 3600 {
+		t.Errorf("unexpected forwarded timer: %+v", timer)
+	}
+
+	var second websocket.MessagePayload
+	if err := json.Unmarshal(sender.sent[2].Payload, &second); err != nil {
+		t.Fatalf("failed to decode forwarded payload: %v", err)
+	}
+	deadline := second.Deadline()
+	if second.TTLSeconds != 3600 || deadline == nil || !deadline.Equal(sender.sent[2].ExpiresAt) {
+		t.Fatalf("expected message to carry the negotiated expiry, got %+v", second.Expiry)
+	}
+	if !deadline.Equal(clk.Now().Add(time.Hour)) {
+		t.Errorf("expected expiry to be measured on the router clock, got %s", second.ExpiresAt)
+	}
+}
+
+type expirySender struct {
+	recordingSender
+	mu     sync.Mutex
+	errors map[string]error
+}
+
+func (s *expirySender) SendErrorToUser(userID string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[userID] = err
+}
+
+func TestFileTransferService_ExpiresTransfersPastDeadline(t *testing.T) {
+	log, _ := logtest.New(t)
+	now := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	clk := clock.NewMockClock(now)
+	sender := &expirySender{errors: make(map[string]error)}
+	files := websocket.NewFileTransferService(sender, time.Hour, clk, log, context.Background())
+
+	files.Track(websocket.FileStartPayload{
+		FileID:      "f-1",
+		From:        peerA,
+		To:          peerB,
+		TotalChunks: 4,
+		Expiry:      websocket.Expiry{TTLSeconds: 60, ExpiresAt: now.Add(time.Minute).Format(time.RFC3339)},
+	})
+	files.Track(websocket.FileStartPayload{FileID: "f-2", From: peerA, To: peerB, TotalChunks: 4})
+
+	if deleted, err := files.DeleteExpired(context.Background()); err != nil || deleted != 0 {
+		t.Fatalf("expected nothing to expire yet, got %d, %v", deleted, err)
+	}
+
+	clk.SetTime(now.Add(time.Minute))
+	if !files.IsExpired("f-1") || files.IsExpired("f-2") {
+		t.Fatal("expected only the disappearing transfer to be expired")
+	}
+	if deleted, err := files.DeleteExpired(context.Background()); err != nil || deleted != 1 {
+		t.Fatalf("expected one expired transfer, got %d, %v", deleted, err)
+	}
+	if deleted, _ := files.DeleteExpired(context.Background()); deleted != 0 {
+		t.Fatalf("expected expired transfer to be reaped once, got %d", deleted)
+	}
+	if !errors.Is(sender.errors[peerA], commonerrors.ErrMessageExpired) {
+		t.Errorf("expected sender to be told the transfer expired, got %v", sender.errors[peerA])
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Type != websocket.TypeFileComplete {
+		t.Errorf("expected recipient to be notified of the failed transfer, got %+v", sender.sent)
+	}
+	if !files.IsExpired("f-1") {
+		t.Error("expected late chunks for the expired transfer to stay rejected")
+	}
+}
@@ -0,0 +1,172 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+type blockingRouter struct {
+	mu      sync.Mutex
+	release chan struct{}
+	routed  int
+}
+
+func newBlockingRouter() *blockingRouter {
+	return &blockingRouter{release: make(chan struct{})}
+}
+
+func (r *blockingRouter) Route(ctx context.Context, client *websocket.Client, msg *websocket.WSMessage) error {
+	<-r.release
+	r.mu.Lock()
+	r.routed++
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *blockingRouter) Deliver(ctx context.Context, fromUserID string, msg *websocket.WSMessage) (bool, error) {
+	return false, nil
+}
+
+func (r *blockingRouter) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.routed
+}
+
+func TestMessageProcessor_Flush_CompletesQueuedTasks(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	router := newBlockingRouter()
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{Router: router, Log: log}, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 10})
+	defer processor.Shutdown()
+
+	client := &websocket.Client{}
+	for i := 0; i < 3; i++ {
+		processor.Submit(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeTyping})
+	}
+	close(router.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if remaining := processor.Flush(ctx); remaining != 0 {
+		t.Fatalf("expected queue to be flushed, %d tasks remaining", remaining)
+	}
+	if router.count() != 3 {
+		t.Errorf("expected 3 routed messages, got %d", router.count())
+	}
+}
+
+func TestMessageProcessor_Flush_DeadlineDropsRemaining(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	router := newBlockingRouter()
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{Router: router, Log: log}, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 10})
+
+	client := &websocket.Client{}
+	for i := 0; i < 3; i++ {
+		processor.Submit(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeTyping})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if remaining := processor.Flush(ctx); remaining != 3 {
+		t.Fatalf("expected 3 tasks remaining at deadline, got %d", remaining)
+	}
+
+	close(router.release)
+	processor.Shutdown()
+
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), time.Second)
+	defer flushCancel()
+	processor.Flush(flushCtx)
+
+	if router.count() != 1 {
+		t.Errorf("expected only the in-flight message to be routed, got %d", router.count())
+	}
+
+	processor.Submit(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeTyping})
+}
+
+func TestHub_Drain_RejectsUpgrades(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	chatSvc, _, _ := setupChatService(t)
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := chathttp.NewHandler(chatSvc, hub, config.ChatConfig{}, log, nil)
+
+	if hub.IsDraining() {
+		t.Fatal("expected hub not to be draining initially")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	hub.Drain(ctx)
+	hub.Drain(ctx)
+
+	if !hub.IsDraining() {
+		t.Fatal("expected hub to be draining")
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
+
+type flushRecorder struct {
+	mu      sync.Mutex
+	flushes []bool
+}
+
+func (f *flushRecorder) HandleMessage(ctx context.Context, client *websocket.Client, msg *websocket.WSMessage) {
+}
+
+func (f *flushRecorder) Flush(ctx context.Context) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes = append(f.flushes, ctx.Err() == nil)
+	return 0
+}
+
+func (f *flushRecorder) Shutdown() {}
+
+func TestHub_Drain_SlowTransferDoesNotStarveMessageFlush(t *testing.T) {
+	log, _ := logger.New("", "test", "info")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	presence := websocket.NewPresenceService(ctx, websocket.PresenceServiceDeps{Sender: hub, Log: log, Clock: clock.NewRealClock()}, websocket.PresenceServiceConfig{})
+	fileService := websocket.NewFileTransferService(hub, time.Minute, clock.NewRealClock(), log, ctx)
+	handler := &flushRecorder{}
+	hub.Wire(handler, presence, fileService)
+	fileService.Track(websocket.FileStartPayload{FileID: "f-1", From: peerA, To: peerB, TotalChunks: 10})
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer drainCancel()
+	hub.Drain(drainCtx)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.flushes) == 0 {
+		t.Fatal("expected the message queue to be flushed")
+	}
+	for i, live := range handler.flushes {
+		if !live {
+			t.Errorf("flush %d started after the drain deadline", i)
+		}
+	}
+}
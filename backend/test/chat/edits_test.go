@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	receiptservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestMessageRouter_AuthorizesEditsAndDeletes(t *testing.T) {
+	log, _ := logtest.New(t)
+	clk := clock.NewMockClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	repo := newMockReceiptRepo()
+	receipts := receiptservice.NewReceiptService(context.Background(), receiptservice.ReceiptServiceDeps{
+		Repo:       repo,
+		EditWindow: time.Hour,
+		Clock:      clk,
+		Log:        log,
+	})
+	defer receipts.Stop()
+	sender := &recordingSender{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:   sender,
+		Receipts: receipts,
+		Log:      log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) error {
+		data, _ := json.Marshal(payload)
+		return router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data})
+	}
+	edit := func(to, messageID string) error {
+		return route(websocket.TypeMessageEdit, websocket.MessageEditPayload{To: to, MessageID: messageID, Ciphertext: "c2", Nonce: "n2", EditCount: 42})
+	}
+	expectCode := func(err error, code string) {
+		t.Helper()
+		de, ok := commonerrors.AsDomainError(err)
+		if !ok || de.Code() != code {
+			t.Errorf("expected %s, got %v", code, err)
+		}
+	}
+	lastEditCount := func() int {
+		t.Helper()
+		var payload websocket.MessageEditPayload
+		if err := json.Unmarshal(sender.sent[len(sender.sent)-1].Payload, &payload); err != nil {
+			t.Fatalf("failed to decode forwarded edit: %v", err)
+		}
+		return payload.EditCount
+	}
+
+	if err := route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"}); err != nil {
+		t.Fatalf("unexpected error sending message: %v", err)
+	}
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-2", SenderID: peerA, Status: receiptdomain.StatusSent})
+
+	if err := edit(peerA, "m-1"); err != nil {
+		t.Fatalf("expected edit of an unflushed own message to be accepted, got %v", err)
+	}
+	if count := lastEditCount(); count != 1 {
+		t.Errorf("expected server-assigned edit count 1, got %d", count)
+	}
+	if err := edit(peerA, "m-1"); err != nil || lastEditCount() != 2 {
+		t.Errorf("expected second edit to be counted, got %v", err)
+	}
+	expectCode(edit(peerA, "m-2"), "MESSAGE_NOT_OWNED")
+	expectCode(edit(peerB, "m-1"), "MESSAGE_NOT_OWNED")
+	expectCode(edit(peerA, "m-unknown"), "MESSAGE_NOT_OWNED")
+
+	clk.SetTime(clk.Now().Add(2 * time.Hour))
+	expectCode(edit(peerA, "m-1"), "EDIT_WINDOW_EXPIRED")
+	receipts.SetEditWindow(3 * time.Hour)
+	if err := edit(peerA, "m-1"); err != nil || lastEditCount() != 3 {
+		t.Errorf("expected edit window change to apply immediately, got %v", err)
+	}
+
+	expectCode(route(websocket.TypeMessageDelete, websocket.MessageDeletePayload{To: peerA, MessageID: "m-1", Scope: "all"}), "INVALID_DELETE_SCOPE")
+	expectCode(route(websocket.TypeMessageDelete, websocket.MessageDeletePayload{To: peerA, MessageID: "m-2", Scope: "everyone"}), "MESSAGE_NOT_OWNED")
+	sentBefore := len(sender.sent)
+	if err := route(websocket.TypeMessageDelete, websocket.MessageDeletePayload{To: peerA, MessageID: "m-2", Scope: "me"}); err != nil {
+		t.Errorf("expected deleting a peer message for self to be accepted, got %v", err)
+	}
+	if len(sender.sent) != sentBefore {
+		t.Errorf("expected a delete for self not to be relayed to the peer")
+	}
+	if err := route(websocket.TypeMessageDelete, websocket.MessageDeletePayload{To: peerA, MessageID: "m-1"}); err != nil {
+		t.Fatalf("expected deleting own message for everyone to be accepted, got %v", err)
+	}
+	var deleted websocket.MessageDeletePayload
+	if err := json.Unmarshal(sender.sent[len(sender.sent)-1].Payload, &deleted); err != nil || deleted.Scope != websocket.DeleteScopeEveryone {
+		t.Errorf("expected missing scope to default to everyone, got %s", sender.sent[len(sender.sent)-1].Payload)
+	}
+	expectCode(edit(peerA, "m-1"), "MESSAGE_NOT_OWNED")
+
+	if len(sender.sent) != 5 {
+		t.Errorf("expected only authorized updates to be forwarded, got %d messages", len(sender.sent))
+	}
+}
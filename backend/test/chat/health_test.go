@@ -0,0 +1,192 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/health"
+)
+
+type fakeHeartbeat struct {
+	last time.Time
+}
+
+func (f *fakeHeartbeat) LastHeartbeat() time.Time {
+	return f.last
+}
+
+type fakeQueue struct {
+	length   int
+	capacity int
+}
+
+func (f *fakeQueue) QueueLen() int {
+	return f.length
+}
+
+func (f *fakeQueue) QueueCapacity() int {
+	return f.capacity
+}
+
+func newTestRegistry() *health.Registry {
+	return health.NewRegistry(health.RegistryDeps{}, health.RegistryConfig{CheckTimeout: time.Second})
+}
+
+func findCheck(report health.Report, name string) (health.CheckResult, bool) {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return health.CheckResult{}, false
+}
+
+func TestHealthRegistry_Readiness_AllPassing(t *testing.T) {
+	registry := newTestRegistry()
+	registry.AddReadiness(
+		health.NewChecker("first", func(ctx context.Context) error { return nil }),
+		health.NewChecker("second", func(ctx context.Context) error { return nil }),
+	)
+
+	report := registry.Readiness(context.Background())
+
+	if report.Status != health.StatusOK {
+		t.Fatalf("expected status ok, got %s", report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(report.Checks))
+	}
+	for _, check := range report.Checks {
+		if check.Status != health.StatusOK || check.LatencyMs < 0 {
+			t.Errorf("unexpected check result %+v", check)
+		}
+	}
+}
+
+func TestHealthRegistry_Readiness_FailingAndOptional(t *testing.T) {
+	registry := newTestRegistry()
+	registry.AddReadiness(
+		health.Optional(health.NewChecker("optional", func(ctx context.Context) error { return errors.New("flaky") })),
+	)
+
+	report := registry.Readiness(context.Background())
+	if report.Status != health.StatusDegraded {
+		t.Fatalf("expected status degraded, got %s", report.Status)
+	}
+
+	registry.AddReadiness(health.NewChecker("postgres", func(ctx context.Context) error { return errors.New("connection refused") }))
+
+	report = registry.Readiness(context.Background())
+	if report.Status != health.StatusFail {
+		t.Fatalf("expected status fail, got %s", report.Status)
+	}
+	check, ok := findCheck(report, "postgres")
+	if !ok || check.Error != "connection refused" {
+		t.Errorf("expected postgres failure in report, got %+v", report.Checks)
+	}
+}
+
+func TestHealthRegistry_Readiness_CheckTimeout(t *testing.T) {
+	registry := health.NewRegistry(health.RegistryDeps{}, health.RegistryConfig{CheckTimeout: 10 * time.Millisecond})
+	registry.AddReadiness(health.NewChecker("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	report := registry.Readiness(context.Background())
+
+	if report.Status != health.StatusFail {
+		t.Fatalf("expected status fail, got %s", report.Status)
+	}
+}
+
+func TestHealthRegistry_Readiness_ShuttingDown(t *testing.T) {
+	registry := newTestRegistry()
+	registry.AddLiveness(health.NewChecker("process", func(ctx context.Context) error { return nil }))
+
+	registry.MarkShuttingDown()
+
+	report := registry.Readiness(context.Background())
+	if report.Status != health.StatusFail {
+		t.Fatalf("expected readiness to fail during shutdown, got %s", report.Status)
+	}
+	if _, ok := findCheck(report, "shutdown"); !ok {
+		t.Errorf("expected shutdown check in report, got %+v", report.Checks)
+	}
+
+	if live := registry.Liveness(context.Background()); live.Status != health.StatusOK {
+		t.Errorf("expected liveness to stay ok during shutdown, got %s", live.Status)
+	}
+}
+
+func TestHealthCheckers_HeartbeatAndQueue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockClock := clock.NewMockClock(now)
+	heartbeat := &fakeHeartbeat{}
+	heartbeatChecker := health.HeartbeatChecker("hub", heartbeat, 15*time.Second, mockClock)
+
+	if err := heartbeatChecker.Check(context.Background()); !errors.Is(err, health.ErrHeartbeatMissing) {
+		t.Errorf("expected ErrHeartbeatMissing, got %v", err)
+	}
+
+	heartbeat.last = now.Add(-5 * time.Second)
+	if err := heartbeatChecker.Check(context.Background()); err != nil {
+		t.Errorf("expected fresh heartbeat to pass, got %v", err)
+	}
+
+	heartbeat.last = now.Add(-time.Minute)
+	if err := heartbeatChecker.Check(context.Background()); !errors.Is(err, health.ErrHeartbeatStale) {
+		t.Errorf("expected ErrHeartbeatStale, got %v", err)
+	}
+
+	queue := &fakeQueue{length: 50, capacity: 100}
+	queueChecker := health.QueueSaturationChecker("queue", queue, 0.9)
+	if err := queueChecker.Check(context.Background()); err != nil {
+		t.Errorf("expected half-full queue to pass, got %v", err)
+	}
+
+	queue.length = 95
+	if err := queueChecker.Check(context.Background()); !errors.Is(err, health.ErrQueueSaturated) {
+		t.Errorf("expected ErrQueueSaturated, got %v", err)
+	}
+}
+
+func TestReadinessHandler_StatusCodes(t *testing.T) {
+	registry := newTestRegistry()
+	handler := commonhttp.ReadinessHandler(registry)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	registry.MarkShuttingDown()
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	var report health.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Status != health.StatusFail {
+		t.Errorf("expected status fail in body, got %s", report.Status)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
@@ -2,11 +2,23 @@ package chat
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	conversationdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/conversation/domain"
+	disappearingdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/disappearing/domain"
 	identitydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/identity/domain"
+	privacydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+	pushdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	pushrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/repository"
+	reactiondomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	receiptrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/repository"
 	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
 	userrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/repository"
+	webhookdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
+	webhookrepo "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/repository"
 )
 
 type mockUserRepo struct {
@@ -82,3 +94,378 @@ func newMockUserRepo() *mockUserRepo {
 func newMockIdentityService() *mockIdentityService {
 	return &mockIdentityService{}
 }
+
+type mockReceiptRepo struct {
+	mu       sync.Mutex
+	inserts  []receiptdomain.Receipt
+	advances []receiptdomain.Receipt
+	edits    map[string]int
+	deleted  map[string]bool
+	listFunc func(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]receiptdomain.Receipt, error)
+}
+
+func (m *mockReceiptRepo) Insert(ctx context.Context, receipts []receiptdomain.Receipt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inserts = append(m.inserts, receipts...)
+	return nil
+}
+
+func (m *mockReceiptRepo) Advance(ctx context.Context, receipts []receiptdomain.Receipt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advances = append(m.advances, receipts...)
+	return nil
+}
+
+func (m *mockReceiptRepo) RecordEdit(ctx context.Context, senderID, recipientID, messageID string, editableSince time.Time) (int, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	receipt, ok := m.find(senderID, recipientID, messageID)
+	if !ok || m.deleted[messageID] {
+		return 0, false, receiptrepo.ErrReceiptNotFound
+	}
+	if receipt.SentAt.Before(editableSince) {
+		return m.edits[messageID], false, nil
+	}
+	if m.edits == nil {
+		m.edits = make(map[string]int)
+	}
+	m.edits[messageID]++
+	return m.edits[messageID], true, nil
+}
+
+func (m *mockReceiptRepo) MarkDeleted(ctx context.Context, senderID, recipientID, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.find(senderID, recipientID, messageID); !ok {
+		return receiptrepo.ErrReceiptNotFound
+	}
+	if m.deleted == nil {
+		m.deleted = make(map[string]bool)
+	}
+	m.deleted[messageID] = true
+	return nil
+}
+
+func (m *mockReceiptRepo) find(senderID, recipientID, messageID string) (receiptdomain.Receipt, bool) {
+	for _, receipt := range m.inserts {
+		if receipt.SenderID == senderID && receipt.RecipientID == recipientID && receipt.MessageID == messageID {
+			return receipt, true
+		}
+	}
+	return receiptdomain.Receipt{}, false
+}
+
+func (m *mockReceiptRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockReceiptRepo) ListConversation(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]receiptdomain.Receipt, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, userID, peerID, since, limit)
+	}
+	return nil, nil
+}
+
+type mockPrivacyRepo struct {
+	mu       sync.Mutex
+	settings map[string]privacydomain.Settings
+	contacts map[string][]string
+	receipts *mockReceiptRepo
+	err      error
+	lookups  int
+}
+
+func newMockPrivacyRepo() *mockPrivacyRepo {
+	return &mockPrivacyRepo{
+		settings: make(map[string]privacydomain.Settings),
+		contacts: make(map[string][]string),
+	}
+}
+
+func (m *mockPrivacyRepo) GetSettings(ctx context.Context, userID string) (privacydomain.Settings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	if m.err != nil {
+		return privacydomain.Settings{}, m.err
+	}
+	if settings, ok := m.settings[userID]; ok {
+		return settings, nil
+	}
+	return privacydomain.DefaultSettings(userID), nil
+}
+
+func (m *mockPrivacyRepo) SaveSettings(ctx context.Context, settings privacydomain.Settings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[settings.UserID] = settings
+	return nil
+}
+
+func (m *mockPrivacyRepo) ListContacts(ctx context.Context, userID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	contacts := append([]string(nil), m.contacts[userID]...)
+	if m.receipts != nil {
+		m.receipts.mu.Lock()
+		defer m.receipts.mu.Unlock()
+		for _, receipt := range m.receipts.inserts {
+			if receipt.SenderID == userID {
+				contacts = append(contacts, receipt.RecipientID)
+			}
+		}
+	}
+	return contacts, nil
+}
+
+func newMockReceiptRepo() *mockReceiptRepo {
+	return &mockReceiptRepo{}
+}
+
+type mockConversationRepo struct {
+	mu         sync.Mutex
+	activity   []conversationdomain.Activity
+	listFunc   func(ctx context.Context, ownerID string, archived bool, limit int) ([]conversationdomain.Conversation, error)
+	updateFunc func(ctx context.Context, ownerID, peerID string, update conversationdomain.Update) (conversationdomain.Conversation, error)
+}
+
+func (m *mockConversationRepo) ApplyActivity(ctx context.Context, activity []conversationdomain.Activity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activity = append(m.activity, activity...)
+	return nil
+}
+
+func (m *mockConversationRepo) List(ctx context.Context, ownerID string, archived bool, limit int) ([]conversationdomain.Conversation, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, ownerID, archived, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockConversationRepo) Update(ctx context.Context, ownerID, peerID string, update conversationdomain.Update) (conversationdomain.Conversation, error) {
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, ownerID, peerID, update)
+	}
+	return conversationdomain.Conversation{}, nil
+}
+
+type mockTimerRepo struct {
+	mu      sync.Mutex
+	timers  map[[2]string]time.Duration
+	err     error
+	lookups int
+}
+
+func newMockTimerRepo() *mockTimerRepo {
+	return &mockTimerRepo{timers: make(map[[2]string]time.Duration)}
+}
+
+func (m *mockTimerRepo) GetTTL(ctx context.Context, userID, peerID string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	if m.err != nil {
+		return 0, m.err
+	}
+	userA, userB := disappearingdomain.Pair(userID, peerID)
+	return m.timers[[2]string{userA, userB}], nil
+}
+
+func (m *mockTimerRepo) SaveTimer(ctx context.Context, timer disappearingdomain.Timer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	userA, userB := disappearingdomain.Pair(timer.UserID, timer.PeerID)
+	m.timers[[2]string{userA, userB}] = timer.TTL
+	return nil
+}
+
+type mockPushRepo struct {
+	mu      sync.Mutex
+	devices map[string]pushdomain.Device
+	pruned  chan string
+}
+
+func newMockPushRepo(devices ...pushdomain.Device) *mockPushRepo {
+	m := &mockPushRepo{devices: make(map[string]pushdomain.Device), pruned: make(chan string, 10)}
+	for _, device := range devices {
+		m.devices[device.Token] = device
+	}
+	return m
+}
+
+func (m *mockPushRepo) Register(ctx context.Context, device pushdomain.Device) (pushdomain.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device.CreatedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.devices[device.Token] = device
+	return device, nil
+}
+
+func (m *mockPushRepo) Delete(ctx context.Context, userID, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if device, ok := m.devices[token]; !ok || device.UserID != userID {
+		return pushrepo.ErrDeviceNotFound
+	}
+	delete(m.devices, token)
+	return nil
+}
+
+func (m *mockPushRepo) DeleteToken(ctx context.Context, token string) error {
+	m.mu.Lock()
+	delete(m.devices, token)
+	m.mu.Unlock()
+	m.pruned <- token
+	return nil
+}
+
+func (m *mockPushRepo) ListByUser(ctx context.Context, userID string) ([]pushdomain.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var devices []pushdomain.Device
+	for _, device := range m.devices {
+		if device.UserID == userID {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+type mockWebhookRepo struct {
+	mu          sync.Mutex
+	webhooks    map[string]webhookdomain.Webhook
+	deadLetters chan webhookdomain.DeadLetter
+	lookups     int
+}
+
+func newMockWebhookRepo(webhooks ...webhookdomain.Webhook) *mockWebhookRepo {
+	m := &mockWebhookRepo{webhooks: make(map[string]webhookdomain.Webhook), deadLetters: make(chan webhookdomain.DeadLetter, 10)}
+	for _, webhook := range webhooks {
+		m.webhooks[webhook.ID] = webhook
+	}
+	return m
+}
+
+func (m *mockWebhookRepo) Create(ctx context.Context, webhook webhookdomain.Webhook) (webhookdomain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook.CreatedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (m *mockWebhookRepo) Delete(ctx context.Context, ownerID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if webhook, ok := m.webhooks[id]; !ok || webhook.OwnerID != ownerID {
+		return webhookrepo.ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *mockWebhookRepo) ListByOwner(ctx context.Context, ownerID string) ([]webhookdomain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	var webhooks []webhookdomain.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.OwnerID == ownerID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *mockWebhookRepo) InsertDeadLetter(ctx context.Context, letter webhookdomain.DeadLetter) error {
+	m.deadLetters <- letter
+	return nil
+}
+
+func (m *mockWebhookRepo) ListDeadLetters(ctx context.Context, ownerID, webhookID string, limit int) ([]webhookdomain.DeadLetter, error) {
+	return []webhookdomain.DeadLetter{}, nil
+}
+
+func (m *mockWebhookRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type mockReactionRepo struct {
+	mu        sync.Mutex
+	reactions []reactiondomain.Reaction
+}
+
+func (m *mockReactionRepo) Add(ctx context.Context, reaction reactiondomain.Reaction, limit int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	others := 0
+	for i, existing := range m.reactions {
+		if existing.UserID != reaction.UserID || existing.PeerID != reaction.PeerID || existing.MessageID != reaction.MessageID {
+			continue
+		}
+		if existing.Emoji == reaction.Emoji {
+			m.reactions[i].ExpiresAt = reaction.ExpiresAt
+			return true, nil
+		}
+		others++
+	}
+	if others >= limit {
+		return false, nil
+	}
+	m.reactions = append(m.reactions, reaction)
+	return true, nil
+}
+
+func (m *mockReactionRepo) Remove(ctx context.Context, reaction reactiondomain.Reaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.reactions[:0]
+	for _, existing := range m.reactions {
+		if existing.UserID == reaction.UserID && existing.PeerID == reaction.PeerID && existing.MessageID == reaction.MessageID && existing.Emoji == reaction.Emoji {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	m.reactions = kept
+	return nil
+}
+
+func (m *mockReactionRepo) Summarize(ctx context.Context, userID, peerID string, messageIDs []string) ([]reactiondomain.Summary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	requested := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		requested[id] = true
+	}
+	summaries := make([]reactiondomain.Summary, 0)
+	index := make(map[[2]string]int)
+	for _, reaction := range m.reactions {
+		inConversation := (reaction.UserID == userID && reaction.PeerID == peerID) || (reaction.UserID == peerID && reaction.PeerID == userID)
+		if !inConversation || !requested[reaction.MessageID] {
+			continue
+		}
+		key := [2]string{reaction.MessageID, reaction.Emoji}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, reactiondomain.Summary{MessageID: reaction.MessageID, Emoji: reaction.Emoji})
+		}
+		summaries[i].Count++
+		summaries[i].Reacted = summaries[i].Reacted || reaction.UserID == userID
+	}
+	return summaries, nil
+}
+
+func (m *mockReactionRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
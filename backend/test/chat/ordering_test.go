@@ -0,0 +1,206 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+const (
+	peerA = "6f1c3a52-8f0e-4c47-9a4e-1d2b3c4d5e6f"
+	peerB = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+)
+
+type orderRecorder struct {
+	mu     sync.Mutex
+	seen   map[string][]int
+	delays map[string]time.Duration
+	block  map[string]chan struct{}
+}
+
+func newOrderRecorder() *orderRecorder {
+	return &orderRecorder{
+		seen:   make(map[string][]int),
+		delays: make(map[string]time.Duration),
+		block:  make(map[string]chan struct{}),
+	}
+}
+
+func (r *orderRecorder) Route(ctx context.Context, client *websocket.Client, msg *websocket.WSMessage) error {
+	var payload struct {
+		To    string `json:"to"`
+		Index int    `json:"index"`
+	}
+	_ = json.Unmarshal(msg.Payload, &payload)
+
+	r.mu.Lock()
+	block := r.block[payload.To]
+	delay := r.delays[payload.To]
+	r.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+	time.Sleep(delay)
+
+	r.mu.Lock()
+	r.seen[payload.To] = append(r.seen[payload.To], payload.Index)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *orderRecorder) Deliver(ctx context.Context, fromUserID string, msg *websocket.WSMessage) (bool, error) {
+	return false, nil
+}
+
+func (r *orderRecorder) indexes(to string) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.seen[to]...)
+}
+
+func submitIndexed(processor *websocket.MessageProcessor, client *websocket.Client, to string, index int) {
+	payload, _ := json.Marshal(map[string]interface{}{"to": to, "index": index})
+	processor.Submit(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeMessage, Payload: payload})
+}
+
+func TestMessageProcessor_PreservesPerPeerOrder(t *testing.T) {
+	log, _ := logtest.New(t)
+	router := newOrderRecorder()
+	router.delays[peerA] = 200 * time.Microsecond
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{Router: router, Log: log}, websocket.MessageProcessorConfig{Workers: 8, QueueSize: 1000, ClientQueueSize: 1000})
+	defer processor.Shutdown()
+
+	client := &websocket.Client{}
+	for i := 0; i < 100; i++ {
+		submitIndexed(processor, client, peerA, i)
+		submitIndexed(processor, client, peerB, i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if remaining := processor.Flush(ctx); remaining != 0 {
+		t.Fatalf("expected all tasks to complete, %d remaining", remaining)
+	}
+
+	for _, peer := range []string{peerA, peerB} {
+		got := router.indexes(peer)
+		if len(got) != 100 {
+			t.Fatalf("expected 100 messages to %s, got %d", peer, len(got))
+		}
+		for i, index := range got {
+			if index != i {
+				t.Fatalf("messages to %s routed out of order at position %d: %v", peer, i, got)
+			}
+		}
+	}
+}
+
+func TestMessageProcessor_SlowPeerDoesNotBlockOthers(t *testing.T) {
+	log, _ := logtest.New(t)
+	router := newOrderRecorder()
+	release := make(chan struct{})
+	router.block[peerA] = release
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{Router: router, Log: log}, websocket.MessageProcessorConfig{Workers: 2, QueueSize: 100})
+	defer processor.Shutdown()
+
+	client := &websocket.Client{}
+	submitIndexed(processor, client, peerA, 0)
+	submitIndexed(processor, client, peerA, 1)
+	submitIndexed(processor, client, peerB, 0)
+
+	deadline := time.Now().Add(time.Second)
+	for len(router.indexes(peerB)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(router.indexes(peerB)) != 1 {
+		t.Fatal("expected message to another peer to be routed while the first pair is blocked")
+	}
+	close(release)
+}
+
+type recordingSender struct {
+	mu      sync.Mutex
+	sent    []*websocket.WSMessage
+	offline map[string]bool
+}
+
+func (s *recordingSender) SendToUserWithContext(ctx context.Context, userID string, message *websocket.WSMessage) error {
+	if s.offline[userID] {
+		return commonerrors.ErrUserNotConnected
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func (s *recordingSender) SendErrorToUser(userID string, err error) {}
+
+func (s *recordingSender) IsUserOnline(userID string) bool { return !s.offline[userID] }
+
+func (s *recordingSender) SupportsMessageType(userID string, msgType websocket.MessageType) bool {
+	return true
+}
+
+func TestMessageRouter_StampsSequenceNumbers(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	sequences := websocket.NewSequenceTracker(context.Background(), time.Hour, clock.NewRealClock())
+	defer sequences.Shutdown()
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:    sender,
+		Sequences: sequences,
+		Log:       log,
+	}, websocket.MessageRouterConfig{})
+
+	client := &websocket.Client{}
+	for _, to := range []string{peerA, peerA, peerB} {
+		payload, _ := json.Marshal(websocket.MessagePayload{To: to, MessageID: "m", Ciphertext: "c", Nonce: "n", Sequence: websocket.Sequence{Seq: 99}})
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeMessage, Payload: payload}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(sender.sent) != 3 {
+		t.Fatalf("expected 3 forwarded messages, got %d", len(sender.sent))
+	}
+	var stamped []websocket.MessagePayload
+	for _, msg := range sender.sent {
+		var payload websocket.MessagePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			t.Fatalf("failed to decode forwarded payload: %v", err)
+		}
+		stamped = append(stamped, payload)
+	}
+	if stamped[0].Seq != 1 || stamped[1].Seq != 2 {
+		t.Fatalf("expected server-assigned sequence 1, 2 for the same pair, got %d, %d", stamped[0].Seq, stamped[1].Seq)
+	}
+	if stamped[0].SeqEpoch == 0 || stamped[0].SeqEpoch != stamped[1].SeqEpoch {
+		t.Fatal("expected a stable non-zero epoch within a pair")
+	}
+	if stamped[2].Seq != 1 {
+		t.Fatalf("expected independent sequence per recipient, got %d", stamped[2].Seq)
+	}
+}
+
+func TestSequenceTracker_CountsPerDirection(t *testing.T) {
+	mockClock := clock.NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sequences := websocket.NewSequenceTracker(context.Background(), time.Hour, mockClock)
+	defer sequences.Shutdown()
+
+	first := sequences.Next("alice", "bob")
+	second := sequences.Next("alice", "bob")
+	if second.Seq != first.Seq+1 || second.SeqEpoch != first.SeqEpoch {
+		t.Fatalf("expected consecutive sequence numbers, got %+v then %+v", first, second)
+	}
+	if reverse := sequences.Next("bob", "alice"); reverse.Seq != 1 {
+		t.Fatalf("expected each direction to have its own counter, got %d", reverse.Seq)
+	}
+}
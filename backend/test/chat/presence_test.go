@@ -0,0 +1,320 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	privacydomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/domain"
+	privacyservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/service"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+type stubPrivacy struct {
+	noReadReceipts map[string]bool
+	hidden         map[[2]string]bool
+}
+
+func (p *stubPrivacy) ReadReceiptsEnabled(ctx context.Context, userID string) bool {
+	return !p.noReadReceipts[userID]
+}
+
+func (p *stubPrivacy) CanSeePresence(ctx context.Context, viewerID, targetID string) bool {
+	return !p.hidden[[2]string{viewerID, targetID}]
+}
+
+type presenceDelivery struct {
+	to  string
+	msg *websocket.WSMessage
+}
+
+type presenceSender struct {
+	mu      sync.Mutex
+	online  map[string]bool
+	sent    []presenceDelivery
+	waiters chan struct{}
+}
+
+func newPresenceSender(online ...string) *presenceSender {
+	s := &presenceSender{online: make(map[string]bool), waiters: make(chan struct{}, 100)}
+	for _, userID := range online {
+		s.online[userID] = true
+	}
+	return s
+}
+
+func (s *presenceSender) SendToUserWithContext(ctx context.Context, userID string, message *websocket.WSMessage) error {
+	s.mu.Lock()
+	s.sent = append(s.sent, presenceDelivery{to: userID, msg: message})
+	s.mu.Unlock()
+	s.waiters <- struct{}{}
+	return nil
+}
+
+func (s *presenceSender) SendErrorToUser(userID string, err error) {}
+
+func (s *presenceSender) IsUserOnline(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.online[userID]
+}
+
+func (s *presenceSender) SupportsMessageType(userID string, msgType websocket.MessageType) bool {
+	return true
+}
+
+func (s *presenceSender) OnlineUserIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.online))
+	for userID, online := range s.online {
+		if online {
+			ids = append(ids, userID)
+		}
+	}
+	return ids
+}
+
+func (s *presenceSender) setOnline(userID string, online bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.online[userID] = online
+}
+
+func (s *presenceSender) drain() []presenceDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := s.sent
+	s.sent = nil
+	for range sent {
+		<-s.waiters
+	}
+	return sent
+}
+
+func (s *presenceSender) await(t *testing.T, count int) []presenceDelivery {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for i := 0; i < count; i++ {
+		select {
+		case <-s.waiters:
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d deliveries", count)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := s.sent
+	s.sent = nil
+	return sent
+}
+
+func decodePresence(t *testing.T, msg *websocket.WSMessage) websocket.PresencePayload {
+	t.Helper()
+	if msg.Type != websocket.TypePresence {
+		t.Fatalf("expected %s message, got %s", websocket.TypePresence, msg.Type)
+	}
+	var payload websocket.PresencePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		t.Fatalf("failed to decode presence payload: %v", err)
+	}
+	return payload
+}
+
+func TestPrivacyService_UnsolicitedSenderIsNotAContact(t *testing.T) {
+	log, _ := logtest.New(t)
+	receipts := newMockReceiptRepo()
+	repo := newMockPrivacyRepo()
+	repo.receipts = receipts
+	repo.settings[peerA] = privacydomain.Settings{UserID: peerA, ReadReceipts: true, PresenceVisibility: privacydomain.VisibilityContacts}
+	privacy := privacyservice.NewPrivacyService(privacyservice.PrivacyServiceDeps{Repo: repo, Log: log})
+	ctx := context.Background()
+
+	_ = receipts.Insert(ctx, []receiptdomain.Receipt{
+		{MessageID: "m-1", SenderID: peerB, RecipientID: peerA, Status: receiptdomain.StatusSent},
+		{MessageID: "m-2", SenderID: peerA, RecipientID: sessionTestUserID, Status: receiptdomain.StatusSent},
+	})
+
+	if privacy.CanSeePresence(ctx, peerB, peerA) {
+		t.Error("expected a user who only sent unsolicited messages not to see presence")
+	}
+	if !privacy.CanSeePresence(ctx, sessionTestUserID, peerA) {
+		t.Error("expected a peer the target messaged to see presence")
+	}
+}
+
+func TestPrivacyService_PresenceVisibility(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := newMockPrivacyRepo()
+	repo.settings[peerA] = privacydomain.Settings{UserID: peerA, ReadReceipts: true, PresenceVisibility: privacydomain.VisibilityContacts}
+	repo.settings[peerB] = privacydomain.Settings{UserID: peerB, ReadReceipts: true, PresenceVisibility: privacydomain.VisibilityNobody}
+	repo.contacts[peerA] = []string{peerB}
+	privacy := privacyservice.NewPrivacyService(privacyservice.PrivacyServiceDeps{Repo: repo, Log: log})
+	ctx := context.Background()
+
+	cases := []struct {
+		viewer, target string
+		want           bool
+	}{
+		{sessionTestUserID, sessionTestUserID, true},
+		{peerA, sessionTestUserID, true},
+		{peerB, peerA, true},
+		{sessionTestUserID, peerA, false},
+		{peerA, peerB, false},
+	}
+	for _, tc := range cases {
+		if got := privacy.CanSeePresence(ctx, tc.viewer, tc.target); got != tc.want {
+			t.Errorf("CanSeePresence(%s, %s) = %v, want %v", tc.viewer, tc.target, got, tc.want)
+		}
+	}
+	if repo.lookups != 3 {
+		t.Errorf("expected settings to be cached per user, got %d lookups", repo.lookups)
+	}
+
+	visibility := privacydomain.Visibility("friends")
+	if _, err := privacy.UpdateSettings(ctx, peerB, privacydomain.SettingsUpdate{PresenceVisibility: &visibility}); !errors.Is(err, commonerrors.ErrInvalidPresenceVisibility) {
+		t.Fatalf("expected invalid visibility to be rejected, got %v", err)
+	}
+	visibility = privacydomain.VisibilityEveryone
+	if _, err := privacy.UpdateSettings(ctx, peerB, privacydomain.SettingsUpdate{PresenceVisibility: &visibility}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !privacy.CanSeePresence(ctx, peerA, peerB) {
+		t.Fatal("expected updated visibility to take effect immediately")
+	}
+
+	repo.err = errors.New("db down")
+	if privacy.CanSeePresence(ctx, peerB, "22222222-2222-2222-2222-222222222222") {
+		t.Fatal("expected presence to be hidden when settings cannot be loaded")
+	}
+	if privacy.ReadReceiptsEnabled(ctx, "22222222-2222-2222-2222-222222222222") {
+		t.Fatal("expected read receipts to be suppressed when settings cannot be loaded")
+	}
+}
+
+func TestPresenceService_SubscribeSendsVisibleSnapshot(t *testing.T) {
+	log, _ := logtest.New(t)
+	lastSeen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	userRepo := newMockUserRepo()
+	userRepo.findByIDFunc = func(ctx context.Context, id userdomain.ID) (userdomain.User, error) {
+		return userdomain.User{ID: id, LastSeenAt: &lastSeen}, nil
+	}
+	sender := newPresenceSender(peerA)
+	privacy := &stubPrivacy{hidden: map[[2]string]bool{{sessionTestUserID, peerB}: true}}
+	presence := websocket.NewPresenceService(context.Background(), websocket.PresenceServiceDeps{
+		Sender:   sender,
+		UserRepo: userRepo,
+		Privacy:  privacy,
+		Log:      log,
+		Clock:    clock.NewRealClock(),
+	}, websocket.PresenceServiceConfig{})
+
+	if err := presence.Subscribe(context.Background(), sessionTestUserID, []string{peerA, peerB, sessionTestUserID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent := sender.drain()
+	if len(sent) != 1 || sent[0].to != sessionTestUserID {
+		t.Fatalf("expected a single snapshot for the visible peer, got %+v", sent)
+	}
+	if payload := decodePresence(t, sent[0].msg); payload.UserID != peerA || payload.Status != websocket.PresenceOnline {
+		t.Errorf("unexpected snapshot: %+v", payload)
+	}
+
+	privacy.hidden = nil
+	if err := presence.Subscribe(context.Background(), sessionTestUserID, []string{peerB}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent = sender.drain()
+	if len(sent) != 1 {
+		t.Fatalf("expected a single snapshot, got %+v", sent)
+	}
+	payload := decodePresence(t, sent[0].msg)
+	if payload.UserID != peerB || payload.Status != websocket.PresenceOffline || payload.LastSeenAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("unexpected snapshot: %+v", payload)
+	}
+
+	tooMany := make([]string, constants.MaxPresenceSubscriptions+1)
+	if err := presence.Subscribe(context.Background(), sessionTestUserID, tooMany); !errors.Is(err, commonerrors.ErrTooManyPresenceSubscriptions) {
+		t.Fatalf("expected subscription limit to be enforced, got %v", err)
+	}
+}
+
+func TestPresenceService_PublishesTransitionsToPermittedSubscribers(t *testing.T) {
+	log, _ := logtest.New(t)
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	sender := newPresenceSender(peerA, peerB, sessionTestUserID)
+	privacy := &stubPrivacy{hidden: map[[2]string]bool{{peerB, peerA}: true}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	presence := websocket.NewPresenceService(ctx, websocket.PresenceServiceDeps{
+		Sender:    sender,
+		Privacy:   privacy,
+		Directory: sender,
+		Log:       log,
+		Clock:     clock.NewMockClock(now),
+	}, websocket.PresenceServiceConfig{})
+	go presence.StartNotifier()
+
+	for _, subscriberID := range []string{sessionTestUserID, peerB} {
+		if err := presence.Subscribe(ctx, subscriberID, []string{peerA}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	sender.drain()
+
+	sender.setOnline(peerA, false)
+	presence.UserOffline(peerA)
+	sent := sender.await(t, 2)
+
+	var disconnected, offline bool
+	for _, delivery := range sent {
+		if delivery.to != sessionTestUserID {
+			t.Fatalf("expected presence of %s to be hidden from %s, got %s", peerA, delivery.to, delivery.msg.Type)
+		}
+		switch delivery.msg.Type {
+		case websocket.TypePeerDisconnected:
+			disconnected = true
+		default:
+			payload := decodePresence(t, delivery.msg)
+			offline = payload.Status == websocket.PresenceOffline && payload.LastSeenAt == "2026-03-04T05:06:07Z"
+		}
+	}
+	if !disconnected || !offline {
+		t.Fatalf("expected peer_disconnected and offline presence, got %+v", sent)
+	}
+}
+
+func TestPresenceService_PeerOfflineRespectsVisibility(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := newPresenceSender(sessionTestUserID)
+	privacy := &stubPrivacy{hidden: map[[2]string]bool{{sessionTestUserID, peerB}: true}}
+	presence := websocket.NewPresenceService(context.Background(), websocket.PresenceServiceDeps{
+		Sender:  sender,
+		Privacy: privacy,
+		Log:     log,
+		Clock:   clock.NewRealClock(),
+	}, websocket.PresenceServiceConfig{})
+
+	for _, peerID := range []string{peerA, peerB} {
+		if err := presence.SendPeerOffline(context.Background(), sessionTestUserID, peerID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	sent := sender.drain()
+	if len(sent) != 1 || sent[0].msg.Type != websocket.TypePeerOffline {
+		t.Fatalf("expected a single peer_offline for the visible peer, got %+v", sent)
+	}
+	var payload websocket.PeerOfflinePayload
+	if err := json.Unmarshal(sent[0].msg.Payload, &payload); err != nil || payload.PeerID != peerA {
+		t.Errorf("unexpected peer_offline payload %s", sent[0].msg.Payload)
+	}
+}
@@ -0,0 +1,209 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+const peerC = "1b2c3d4e-5f60-4a7b-8c9d-0e1f2a3b4c5d"
+
+type routedMessage struct {
+	msgType websocket.MessageType
+	to      string
+	index   int
+}
+
+type gateRouter struct {
+	mu     sync.Mutex
+	gate   chan struct{}
+	gated  string
+	routed []routedMessage
+}
+
+func (r *gateRouter) Route(ctx context.Context, client *websocket.Client, msg *websocket.WSMessage) error {
+	var payload struct {
+		To    string `json:"to"`
+		Index int    `json:"index"`
+	}
+	_ = json.Unmarshal(msg.Payload, &payload)
+	if payload.To == r.gated {
+		<-r.gate
+	}
+	r.mu.Lock()
+	r.routed = append(r.routed, routedMessage{msgType: msg.Type, to: payload.To, index: payload.Index})
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *gateRouter) Deliver(ctx context.Context, fromUserID string, msg *websocket.WSMessage) (bool, error) {
+	return false, nil
+}
+
+func (r *gateRouter) snapshot() []routedMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]routedMessage(nil), r.routed...)
+}
+
+type backpressureRecorder struct {
+	mu      sync.Mutex
+	signals []websocket.BackpressurePayload
+}
+
+func (r *backpressureRecorder) notify(client *websocket.Client, payload websocket.BackpressurePayload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signals = append(r.signals, payload)
+}
+
+func (r *backpressureRecorder) states() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	states := make([]string, 0, len(r.signals))
+	for _, signal := range r.signals {
+		states = append(states, signal.State)
+	}
+	return states
+}
+
+func submitTyped(processor *websocket.MessageProcessor, client *websocket.Client, msgType websocket.MessageType, to string, index int) {
+	payload, _ := json.Marshal(map[string]interface{}{"to": to, "index": index})
+	processor.Submit(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: payload})
+}
+
+func newGatedProcessor(t *testing.T, config websocket.MessageProcessorConfig) (*websocket.MessageProcessor, *gateRouter, *backpressureRecorder, *websocket.Client) {
+	t.Helper()
+	log, _ := logtest.New(t)
+	router := &gateRouter{gate: make(chan struct{}), gated: "gate"}
+	recorder := &backpressureRecorder{}
+	processor := websocket.NewMessageProcessor(websocket.MessageProcessorDeps{
+		Router:   router,
+		Log:      log,
+		Notifier: recorder.notify,
+	}, config)
+	t.Cleanup(processor.Shutdown)
+
+	blocker := &websocket.Client{}
+	submitTyped(processor, blocker, websocket.TypeMessage, "gate", 0)
+	deadline := time.Now().Add(time.Second)
+	for processor.QueueLen() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return processor, router, recorder, blocker
+}
+
+func flushProcessor(t *testing.T, processor *websocket.MessageProcessor) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if remaining := processor.Flush(ctx); remaining != 0 {
+		t.Fatalf("expected all tasks to complete, %d remaining", remaining)
+	}
+}
+
+func TestMessageProcessor_ControlMessagesJumpAheadOfBulk(t *testing.T) {
+	processor, router, _, _ := newGatedProcessor(t, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 100})
+	client := &websocket.Client{}
+
+	submitTyped(processor, client, websocket.TypeFileChunk, peerA, 0)
+	submitTyped(processor, client, websocket.TypeMessage, peerB, 0)
+	submitTyped(processor, client, websocket.TypeAck, peerC, 0)
+	close(router.gate)
+	flushProcessor(t, processor)
+
+	routed := router.snapshot()[1:]
+	expected := []websocket.MessageType{websocket.TypeAck, websocket.TypeMessage, websocket.TypeFileChunk}
+	for i, msgType := range expected {
+		if routed[i].msgType != msgType {
+			t.Fatalf("expected %v at position %d, got %+v", msgType, i, routed)
+		}
+	}
+}
+
+func TestMessageProcessor_PriorityKeepsPerPeerOrder(t *testing.T) {
+	processor, router, _, _ := newGatedProcessor(t, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 100})
+	client := &websocket.Client{}
+
+	submitTyped(processor, client, websocket.TypeMessage, peerA, 0)
+	submitTyped(processor, client, websocket.TypeEphemeralKey, peerA, 1)
+	submitTyped(processor, client, websocket.TypeAck, peerA, 2)
+	submitTyped(processor, client, websocket.TypeFileChunk, peerB, 3)
+	submitTyped(processor, client, websocket.TypeAck, peerC, 4)
+	close(router.gate)
+	flushProcessor(t, processor)
+
+	routed := router.snapshot()[1:]
+	expected := []routedMessage{
+		{msgType: websocket.TypeAck, to: peerC, index: 4},
+		{msgType: websocket.TypeMessage, to: peerA, index: 0},
+		{msgType: websocket.TypeEphemeralKey, to: peerA, index: 1},
+		{msgType: websocket.TypeAck, to: peerA, index: 2},
+		{msgType: websocket.TypeFileChunk, to: peerB, index: 3},
+	}
+	if len(routed) != len(expected) {
+		t.Fatalf("expected %d routed messages, got %+v", len(expected), routed)
+	}
+	for i, msg := range expected {
+		if routed[i] != msg {
+			t.Fatalf("expected %+v at position %d, got %+v", msg, i, routed)
+		}
+	}
+}
+
+func TestMessageProcessor_RoundRobinAcrossClients(t *testing.T) {
+	processor, router, _, _ := newGatedProcessor(t, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 100, ClientQueueSize: 50})
+	noisy := &websocket.Client{}
+	quiet := &websocket.Client{}
+
+	for i := 0; i < 40; i++ {
+		submitTyped(processor, noisy, websocket.TypeMessage, peerA, i)
+	}
+	for i := 0; i < 3; i++ {
+		submitTyped(processor, quiet, websocket.TypeMessage, peerB, i)
+	}
+	close(router.gate)
+	flushProcessor(t, processor)
+
+	routed := router.snapshot()[1:]
+	lastQuiet := -1
+	for i, msg := range routed {
+		if msg.to == peerB {
+			lastQuiet = i
+		}
+	}
+	if lastQuiet < 0 || lastQuiet > 6 {
+		t.Fatalf("expected the quiet client to be served within the first rounds, last served at %d", lastQuiet)
+	}
+}
+
+func TestMessageProcessor_SignalsBackpressure(t *testing.T) {
+	processor, router, recorder, _ := newGatedProcessor(t, websocket.MessageProcessorConfig{Workers: 1, QueueSize: 100, ClientQueueSize: 4})
+	client := &websocket.Client{}
+
+	for i := 0; i < 5; i++ {
+		submitTyped(processor, client, websocket.TypeFileChunk, peerA, i)
+	}
+	if processor.ClientQueueLen(client) != 4 {
+		t.Fatalf("expected the client queue to be capped at 4, got %d", processor.ClientQueueLen(client))
+	}
+	states := recorder.states()
+	if len(states) != 2 || states[0] != websocket.BackpressureThrottle || states[1] != websocket.BackpressureRejected {
+		t.Fatalf("expected throttle then rejected signals, got %v", states)
+	}
+
+	close(router.gate)
+	flushProcessor(t, processor)
+	states = recorder.states()
+	if states[len(states)-1] != websocket.BackpressureResume {
+		t.Fatalf("expected resume signal once the queue drained, got %v", states)
+	}
+	if got := len(router.snapshot()); got != 5 {
+		t.Fatalf("expected 4 accepted chunks plus the blocker to be routed, got %d", got)
+	}
+}
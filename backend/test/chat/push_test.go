@@ -0,0 +1,308 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	commonhttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	pushdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/domain"
+	pushnotifier "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/notifier"
+	pushservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/push/service"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/pushtest"
+)
+
+func newVAPIDKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate vapid key: %v", err)
+	}
+	return key, base64.RawURLEncoding.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+}
+
+func TestWebPushNotifier_SendsContentFreeVAPIDRequest(t *testing.T) {
+	server := pushtest.NewServer(t)
+	key, privateKey := newVAPIDKey(t)
+	now := time.Now()
+	webPush, err := pushnotifier.NewWebPushNotifier(pushnotifier.WebPushConfig{
+		PrivateKey: privateKey,
+		Subject:    "mailto:ops@example.com",
+		Client:     server.Client(),
+		Clock:      clock.NewMockClock(now),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	device := pushdomain.Device{UserID: peerA, Token: server.URL + "/push/abc", Provider: pushdomain.ProviderWebPush}
+	if err := webPush.Notify(context.Background(), device); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := server.Await(t, 1)
+	req := requests[0]
+	if req.Method != http.MethodPost || req.Path != "/push/abc" || len(req.Body) != 0 {
+		t.Fatalf("expected an empty POST to the subscription endpoint, got %s %s with %d bytes", req.Method, req.Path, len(req.Body))
+	}
+	if req.Header.Get("TTL") == "" || req.Header.Get("Urgency") != "high" {
+		t.Errorf("unexpected push headers: %v", req.Header)
+	}
+
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "vapid t=") || !strings.HasSuffix(auth, ", k="+webPush.PublicKey()) {
+		t.Fatalf("unexpected authorization header %q", auth)
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(auth, "vapid t="), ", k="+webPush.PublicKey())
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(server.URL)); err != nil {
+		t.Fatalf("expected a valid vapid token: %v", err)
+	}
+	if claims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("unexpected vapid claims: %v", claims)
+	}
+
+	server.SetStatus(http.StatusGone)
+	if err := webPush.Notify(context.Background(), device); !errors.Is(err, pushnotifier.ErrDeviceGone) {
+		t.Fatalf("expected gone subscription to be reported, got %v", err)
+	}
+
+	if _, err := pushnotifier.NewWebPushNotifier(pushnotifier.WebPushConfig{PrivateKey: "not-a-key"}); err == nil {
+		t.Fatal("expected invalid vapid key to be rejected")
+	}
+}
+
+func TestPushService_DeliversToDevicesAndPrunesGone(t *testing.T) {
+	log, _ := logtest.New(t)
+	webhookServer := pushtest.NewServer(t)
+	webhookServer.SetStatus(http.StatusOK)
+	webPushServer := pushtest.NewServer(t)
+	webPushServer.SetStatus(http.StatusGone)
+	_, privateKey := newVAPIDKey(t)
+	webPush, err := pushnotifier.NewWebPushNotifier(pushnotifier.WebPushConfig{PrivateKey: privateKey, Subject: "mailto:ops@example.com", Client: webPushServer.Client()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	goneToken := webPushServer.URL + "/gone"
+	repo := newMockPushRepo(
+		pushdomain.Device{UserID: peerA, Token: "device-1", Provider: pushdomain.ProviderWebhook},
+		pushdomain.Device{UserID: peerA, Token: goneToken, Provider: pushdomain.ProviderWebPush},
+		pushdomain.Device{UserID: peerB, Token: "device-2", Provider: pushdomain.ProviderWebhook},
+	)
+	push := pushservice.NewPushService(context.Background(), pushservice.PushServiceDeps{
+		Repo: repo,
+		Notifiers: map[pushdomain.Provider]pushnotifier.Notifier{
+			pushdomain.ProviderWebhook: pushnotifier.NewWebhookNotifier(pushnotifier.WebhookConfig{URL: webhookServer.URL + "/wake", Client: webhookServer.Client()}),
+			pushdomain.ProviderWebPush: webPush,
+		},
+		Log: log,
+	})
+	defer push.Stop()
+
+	push.NotifyOffline(peerA)
+	push.NotifyOffline(peerA)
+
+	requests := webhookServer.Await(t, 1)
+	var body map[string]interface{}
+	if err := json.Unmarshal(requests[0].Body, &body); err != nil {
+		t.Fatalf("failed to decode webhook body: %v", err)
+	}
+	if len(body) != 2 || body["token"] != "device-1" || body["event"] != "wake" {
+		t.Errorf("expected a content-free webhook payload, got %s", requests[0].Body)
+	}
+	webPushServer.Await(t, 1)
+
+	select {
+	case token := <-repo.pruned:
+		if token != goneToken {
+			t.Errorf("expected the gone subscription to be pruned, got %s", token)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the gone subscription to be pruned")
+	}
+
+	select {
+	case <-time.After(50 * time.Millisecond):
+	case token := <-repo.pruned:
+		t.Fatalf("unexpected prune of %s", token)
+	}
+	if devices, _ := repo.ListByUser(context.Background(), peerA); len(devices) != 1 {
+		t.Errorf("expected a single remaining device, got %+v", devices)
+	}
+}
+
+type recordingPush struct {
+	mu    sync.Mutex
+	users []string
+}
+
+func (p *recordingPush) NotifyOffline(userID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.users = append(p.users, userID)
+}
+
+func TestMessageRouter_NotifiesOfflineRecipients(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := newPresenceSender(peerB)
+	push := &recordingPush{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender: sender,
+		Push:   push,
+		Log:    log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("unexpected error routing %s: %v", msgType, err)
+		}
+	}
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"})
+	route(websocket.TypeTyping, websocket.TypingPayload{To: peerA})
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerB, MessageID: "m-2", Ciphertext: "c", Nonce: "n"})
+
+	if len(push.users) != 1 || push.users[0] != peerA {
+		t.Fatalf("expected only the offline message recipient to be notified, got %+v", push.users)
+	}
+	if sent := sender.drain(); len(sent) != 1 || sent[0].to != peerB {
+		t.Errorf("expected the online recipient to receive the message, got %+v", sent)
+	}
+}
+
+func TestHandler_RegistersPushDevices(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := newMockPushRepo()
+	for i := 0; i < constants.MaxPushDevicesPerUser-1; i++ {
+		repo.devices[string(rune('a'+i))] = pushdomain.Device{UserID: sessionTestUserID, Token: string(rune('a' + i)), Provider: pushdomain.ProviderWebhook}
+	}
+	push := pushservice.NewPushService(context.Background(), pushservice.PushServiceDeps{
+		Repo: repo,
+		Notifiers: map[pushdomain.Provider]pushnotifier.Notifier{
+			pushdomain.ProviderWebhook: pushnotifier.NewWebhookNotifier(pushnotifier.WebhookConfig{URL: "https://push.invalid/wake"}),
+		},
+		Log: log,
+	})
+	defer push.Stop()
+
+	chatSvc := service.NewChatService(service.ChatServiceDeps{
+		Repo:            newMockUserRepo(),
+		IdentityService: newMockIdentityService(),
+		Push:            push,
+		Log:             log,
+	})
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		chathttp.NewHandler(chatSvc, hub, config.ChatConfig{RequestTimeout: time.Second}, log, nil),
+	)
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/chat/push/devices", `{"provider":"webhook","token":"device-token"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "device-token") {
+		t.Errorf("expected the device token not to be echoed, got %s", rec.Body.String())
+	}
+	if device, ok := repo.devices["device-token"]; !ok || device.UserID != sessionTestUserID || device.Provider != pushdomain.ProviderWebhook {
+		t.Fatalf("expected device to be stored, got %+v", repo.devices)
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPost, "/api/chat/push/devices", `{"provider":"webhook","token":"device-token"}`, http.StatusCreated},
+		{http.MethodPost, "/api/chat/push/devices", `{"provider":"webhook","token":"another-token"}`, http.StatusConflict},
+		{http.MethodPost, "/api/chat/push/devices", `{"provider":"webpush","token":"https://push.example.com/sub"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/push/devices", `{"provider":"apns","token":"device-token"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/push/devices", `{"provider":"webhook"}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/chat/push/devices", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/chat/push/vapid-key", "", http.StatusBadRequest},
+		{http.MethodDelete, "/api/chat/push/devices", `{"token":"device-token"}`, http.StatusNoContent},
+		{http.MethodDelete, "/api/chat/push/devices", `{"token":"device-token"}`, http.StatusNotFound},
+	} {
+		if rec := do(tc.method, tc.target, tc.body); rec.Code != tc.status {
+			t.Errorf("%s %s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.body, tc.status, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestPushService_RejectsNonPublicWebPushEndpoints(t *testing.T) {
+	log, _ := logtest.New(t)
+	_, privateKey := newVAPIDKey(t)
+	webPush, err := pushnotifier.NewWebPushNotifier(pushnotifier.WebPushConfig{PrivateKey: privateKey, Subject: "mailto:ops@example.com"})
+	if err != nil {
+		t.Fatalf("failed to configure web push: %v", err)
+	}
+	push := pushservice.NewPushService(context.Background(), pushservice.PushServiceDeps{
+		Repo:      newMockPushRepo(),
+		Notifiers: map[pushdomain.Provider]pushnotifier.Notifier{pushdomain.ProviderWebPush: webPush},
+		Log:       log,
+	})
+	defer push.Stop()
+
+	for _, endpoint := range []string{
+		"https://localhost/sub",
+		"https://127.0.0.1/sub",
+		"https://10.0.0.5/sub",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/sub",
+		"https://[::ffff:192.168.1.1]/sub",
+	} {
+		if _, err := push.RegisterDevice(context.Background(), sessionTestUserID, pushdomain.ProviderWebPush, endpoint); !errors.Is(err, commonerrors.ErrInvalidPushDevice) {
+			t.Errorf("expected %s to be rejected, got %v", endpoint, err)
+		}
+	}
+	if _, err := push.RegisterDevice(context.Background(), sessionTestUserID, pushdomain.ProviderWebPush, "https://fcm.googleapis.com/fcm/send/abc"); err != nil {
+		t.Errorf("expected public endpoint to be accepted, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the request to a loopback address to be blocked")
+	}))
+	defer server.Close()
+	if _, err := commonhttp.NewPublicClient(time.Second).Get(server.URL); !errors.Is(err, commonhttp.ErrNonPublicAddress) {
+		t.Errorf("expected dial to a loopback address to fail, got %v", err)
+	}
+}
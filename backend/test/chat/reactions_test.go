@@ -0,0 +1,177 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	commonerrors "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/errors"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	reactiondomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/domain"
+	reactionservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/reaction/service"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestValidEmoji(t *testing.T) {
+	for _, emoji := range []string{"👍", "❤️", "❤", "👍🏽", "👨‍👩‍👧‍👦", "🏳️‍🌈", "🧑🏽‍🤝‍🧑🏻", "🇰🇿", "1️⃣", "#⃣", "🏴󠁧󠁢󠁳󠁣󠁴󠁿", "©️"} {
+		if !reactiondomain.ValidEmoji(emoji) {
+			t.Errorf("expected %q to be a valid emoji", emoji)
+		}
+	}
+	for _, emoji := range []string{"", "a", "+1", "👍👍", "👍 ", "🏽", "‍👍", "👍‍", "🇰", "1", "1️", "<script>", "\xf0\x9f", strings.Repeat("👨‍👩‍👧‍👦", 3)} {
+		if reactiondomain.ValidEmoji(emoji) {
+			t.Errorf("expected %q to be rejected", emoji)
+		}
+	}
+}
+
+func TestMessageRouter_ValidatesAndCapsReactions(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	repo := &mockReactionRepo{}
+	reactions := reactionservice.NewReactionService(reactionservice.ReactionServiceDeps{Repo: repo, Log: log})
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:    sender,
+		Reactions: reactions,
+		Log:       log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(emoji, action string) error {
+		payload, _ := json.Marshal(websocket.ReactionPayload{To: peerA, MessageID: "m-1", Emoji: emoji, Action: action})
+		return router.Route(context.Background(), client, &websocket.WSMessage{Type: websocket.TypeReaction, Payload: payload})
+	}
+	expectCode := func(err error, code string) {
+		t.Helper()
+		de, ok := commonerrors.AsDomainError(err)
+		if !ok || de.Code() != code {
+			t.Errorf("expected %s, got %v", code, err)
+		}
+	}
+
+	for _, emoji := range []string{"👍", "❤️", "🔥"} {
+		if err := route(emoji, "add"); err != nil {
+			t.Fatalf("unexpected error adding %s: %v", emoji, err)
+		}
+	}
+	expectCode(route("lol", "add"), "INVALID_REACTION")
+	expectCode(route("👍", "toggle"), "INVALID_REACTION")
+	expectCode(route("🎉", "add"), "REACTION_LIMIT")
+	if err := route("👍", "add"); err != nil {
+		t.Errorf("expected repeated reaction to be accepted at the limit, got %v", err)
+	}
+	if err := route("🔥", "remove"); err != nil {
+		t.Fatalf("unexpected error removing reaction: %v", err)
+	}
+	if err := route("🎉", "add"); err != nil {
+		t.Errorf("expected reaction to be accepted after removal, got %v", err)
+	}
+
+	if len(sender.sent) != 6 {
+		t.Fatalf("expected only valid reactions to be forwarded, got %d", len(sender.sent))
+	}
+	var forwarded websocket.ReactionPayload
+	if err := json.Unmarshal(sender.sent[5].Payload, &forwarded); err != nil || forwarded.Emoji != "🎉" || forwarded.Action != "add" {
+		t.Errorf("unexpected forwarded reaction %s", sender.sent[5].Payload)
+	}
+
+	summaries, err := reactions.Summarize(context.Background(), peerA, "", []string{"m-1"})
+	if err != nil {
+		t.Fatalf("unexpected error summarizing reactions: %v", err)
+	}
+	var emojis []string
+	for _, summary := range summaries {
+		emojis = append(emojis, summary.Emoji)
+	}
+	if strings.Join(emojis, ",") != "👍,❤️,🎉" {
+		t.Errorf("expected removed reaction to be dropped from the aggregate, got %v", emojis)
+	}
+}
+
+func TestHandler_ConversationReactions(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := &mockReactionRepo{}
+	reactions := reactionservice.NewReactionService(reactionservice.ReactionServiceDeps{Repo: repo, Log: log})
+	ctx := context.Background()
+	for _, reaction := range []reactiondomain.Reaction{
+		{MessageID: "m-1", UserID: sessionTestUserID, PeerID: peerA, Emoji: "👍", Action: reactiondomain.ActionAdd},
+		{MessageID: "m-1", UserID: peerA, PeerID: sessionTestUserID, Emoji: "👍", Action: reactiondomain.ActionAdd},
+		{MessageID: "m-1", UserID: peerA, PeerID: sessionTestUserID, Emoji: "😂", Action: reactiondomain.ActionAdd},
+		{MessageID: "m-2", UserID: peerA, PeerID: sessionTestUserID, Emoji: "🔥", Action: reactiondomain.ActionAdd},
+		{MessageID: "m-1", UserID: peerB, PeerID: sessionTestUserID, Emoji: "👎", Action: reactiondomain.ActionAdd},
+	} {
+		if err := reactions.Apply(ctx, reaction); err != nil {
+			t.Fatalf("failed to seed reaction: %v", err)
+		}
+	}
+
+	chatSvc := service.NewChatService(service.ChatServiceDeps{
+		Repo:            newMockUserRepo(),
+		IdentityService: newMockIdentityService(),
+		Reactions:       reactions,
+		Log:             log,
+	})
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		chathttp.NewHandler(chatSvc, hub, config.ChatConfig{RequestTimeout: time.Second}, log, nil),
+	)
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	do := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/chat/conversations/"+peerA+"/reactions?message_id=m-1&message_id=m-3")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var summaries []struct {
+		MessageID string `json:"message_id"`
+		Emoji     string `json:"emoji"`
+		Count     int    `json:"count"`
+		Reacted   bool   `json:"reacted"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &summaries); err != nil {
+		t.Fatalf("failed to decode reactions: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].Emoji != "👍" || summaries[0].Count != 2 || !summaries[0].Reacted ||
+		summaries[1].Emoji != "😂" || summaries[1].Count != 1 || summaries[1].Reacted {
+		t.Errorf("unexpected reaction summaries %+v", summaries)
+	}
+
+	tooMany := "/api/chat/conversations/" + peerA + "/reactions?message_id=m" + strings.Repeat("&message_id=m", constants.MaxReactionMessageIDs)
+	for _, tc := range []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/api/chat/conversations/" + peerA + "/reactions", http.StatusBadRequest},
+		{http.MethodGet, tooMany, http.StatusBadRequest},
+		{http.MethodGet, "/api/chat/conversations/not-a-uuid/reactions?message_id=m-1", http.StatusBadRequest},
+		{http.MethodPatch, "/api/chat/conversations/" + peerA + "/reactions", http.StatusMethodNotAllowed},
+	} {
+		if rec := do(tc.method, tc.target); rec.Code != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.status, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	privacyservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/privacy/service"
+	receiptdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/domain"
+	receiptservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/receipt/service"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+func TestReceiptService_MergesStatusesPerMessage(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := newMockReceiptRepo()
+	receipts := receiptservice.NewReceiptService(context.Background(), receiptservice.ReceiptServiceDeps{Repo: repo, Log: log})
+
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-1", SenderID: peerA, RecipientID: peerB, Status: receiptdomain.StatusSent})
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-1", SenderID: peerA, RecipientID: peerB, Status: receiptdomain.StatusRead})
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-1", SenderID: peerA, RecipientID: peerB, Status: receiptdomain.StatusDelivered})
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-2", SenderID: peerA, RecipientID: peerB, Status: receiptdomain.StatusDelivered})
+	receipts.Record(receiptdomain.Receipt{MessageID: "m-2", SenderID: peerA, RecipientID: sessionTestUserID, Status: receiptdomain.StatusRead})
+	receipts.Stop()
+
+	if len(repo.inserts) != 1 || repo.inserts[0].MessageID != "m-1" || repo.inserts[0].Status != receiptdomain.StatusRead {
+		t.Fatalf("expected m-1 to be inserted once with the highest status, got %+v", repo.inserts)
+	}
+	if len(repo.advances) != 1 || repo.advances[0].MessageID != "m-2" || repo.advances[0].Status != receiptdomain.StatusDelivered {
+		t.Fatalf("expected m-2 to be advanced by its recipient only, got %+v", repo.advances)
+	}
+}
+
+type recordingReceipts struct {
+	mu      sync.Mutex
+	records []receiptdomain.Receipt
+}
+
+func (r *recordingReceipts) Record(receipt receiptdomain.Receipt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, receipt)
+}
+
+func (r *recordingReceipts) RecordEdit(ctx context.Context, senderID, recipientID, messageID string) (int, error) {
+	return 0, nil
+}
+
+func (r *recordingReceipts) RecordDelete(ctx context.Context, senderID, recipientID, messageID string) error {
+	return nil
+}
+
+func TestMessageRouter_RecordsReceipts(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	userRepo := newMockUserRepo()
+	userRepo.findByIDFunc = func(ctx context.Context, id userdomain.ID) (userdomain.User, error) {
+		return userdomain.User{ID: id}, nil
+	}
+	presence := websocket.NewPresenceService(context.Background(), websocket.PresenceServiceDeps{
+		Sender:   sender,
+		UserRepo: userRepo,
+		Log:      log,
+		Clock:    clock.NewRealClock(),
+	}, websocket.PresenceServiceConfig{})
+	receipts := &recordingReceipts{}
+	privacy := &stubPrivacy{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:   sender,
+		Presence: presence,
+		Receipts: receipts,
+		Privacy:  privacy,
+		Log:      log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("unexpected error routing %s: %v", msgType, err)
+		}
+	}
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"})
+	route(websocket.TypeAck, websocket.AckPayload{To: peerA, MessageID: "m-2"})
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerA, MessageID: "m-2"})
+
+	expected := []receiptdomain.Receipt{
+		{MessageID: "m-1", RecipientID: peerA, Status: receiptdomain.StatusSent},
+		{MessageID: "m-2", SenderID: peerA, Status: receiptdomain.StatusDelivered},
+		{MessageID: "m-2", SenderID: peerA, Status: receiptdomain.StatusRead},
+	}
+	if len(receipts.records) != len(expected) {
+		t.Fatalf("expected %d receipts, got %+v", len(expected), receipts.records)
+	}
+	for i, want := range expected {
+		if receipts.records[i] != want {
+			t.Errorf("receipt %d: expected %+v, got %+v", i, want, receipts.records[i])
+		}
+	}
+
+	privacy.noReadReceipts = map[string]bool{"": true}
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerA, MessageID: "m-3"})
+	if len(sender.sent) != 3 || len(receipts.records) != 3 {
+		t.Fatalf("expected message_read to be suppressed when read receipts are disabled, got %d sent", len(sender.sent))
+	}
+}
+
+func TestMessageRouter_RecordsReceiptsWhileSenderOffline(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{offline: map[string]bool{peerA: true}}
+	userRepo := newMockUserRepo()
+	userRepo.findByIDFunc = func(ctx context.Context, id userdomain.ID) (userdomain.User, error) {
+		return userdomain.User{ID: id}, nil
+	}
+	presence := websocket.NewPresenceService(context.Background(), websocket.PresenceServiceDeps{
+		Sender:   sender,
+		UserRepo: userRepo,
+		Log:      log,
+		Clock:    clock.NewRealClock(),
+	}, websocket.PresenceServiceConfig{})
+	receipts := &recordingReceipts{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:   sender,
+		Presence: presence,
+		Receipts: receipts,
+		Log:      log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("unexpected error routing %s: %v", msgType, err)
+		}
+	}
+	route(websocket.TypeAck, websocket.AckPayload{To: peerA, MessageID: "m-1"})
+	route(websocket.TypeMessageRead, websocket.MessageReadPayload{To: peerA, MessageID: "m-1"})
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-2", Ciphertext: "c", Nonce: "n"})
+
+	expected := []receiptdomain.Receipt{
+		{MessageID: "m-1", SenderID: peerA, Status: receiptdomain.StatusDelivered},
+		{MessageID: "m-1", SenderID: peerA, Status: receiptdomain.StatusRead},
+	}
+	if len(receipts.records) != len(expected) {
+		t.Fatalf("expected only delivered and read receipts for an offline sender, got %+v", receipts.records)
+	}
+	for i, want := range expected {
+		if receipts.records[i] != want {
+			t.Errorf("receipt %d: expected %+v, got %+v", i, want, receipts.records[i])
+		}
+	}
+}
+
+func TestHandler_PrivacySettingsAndReceipts(t *testing.T) {
+	log, _ := logtest.New(t)
+	repo := newMockReceiptRepo()
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var gotSince time.Time
+	repo.listFunc = func(ctx context.Context, userID, peerID string, since time.Time, limit int) ([]receiptdomain.Receipt, error) {
+		gotSince = since
+		return []receiptdomain.Receipt{{MessageID: "m-1", SenderID: userID, RecipientID: peerID, Status: receiptdomain.StatusRead, UpdatedAt: updatedAt}}, nil
+	}
+	receipts := receiptservice.NewReceiptService(context.Background(), receiptservice.ReceiptServiceDeps{Repo: repo, Log: log})
+	defer receipts.Stop()
+	privacy := privacyservice.NewPrivacyService(privacyservice.PrivacyServiceDeps{Repo: newMockPrivacyRepo(), Log: log})
+
+	chatSvc := service.NewChatService(service.ChatServiceDeps{
+		Repo:            newMockUserRepo(),
+		IdentityService: newMockIdentityService(),
+		Receipts:        receipts,
+		Privacy:         privacy,
+		Log:             log,
+	})
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		chathttp.NewHandler(chatSvc, hub, config.ChatConfig{RequestTimeout: time.Second}, log, nil),
+	)
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	do := func(method, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/api/chat/me/privacy", []byte(`{"read_receipts":false}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if privacy.ReadReceiptsEnabled(context.Background(), sessionTestUserID) {
+		t.Fatal("expected read receipts to be disabled")
+	}
+	rec = do(http.MethodPut, "/api/chat/me/privacy", []byte(`{"presence_visibility":"contacts"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	for _, body := range []string{`{}`, `{"presence_visibility":"friends"}`} {
+		if rec := do(http.MethodPut, "/api/chat/me/privacy", []byte(body)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+		}
+	}
+	rec = do(http.MethodGet, "/api/chat/me/privacy", nil)
+	var settings struct {
+		ReadReceipts       bool   `json:"read_receipts"`
+		PresenceVisibility string `json:"presence_visibility"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &settings); err != nil || settings.ReadReceipts || settings.PresenceVisibility != "contacts" {
+		t.Fatalf("expected read_receipts=false and presence_visibility=contacts, got %s", rec.Body.String())
+	}
+
+	rec = do(http.MethodGet, "/api/chat/conversations/"+peerA+"/receipts?since=2026-01-01T00:00:00Z", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp []struct {
+		MessageID   string    `json:"message_id"`
+		RecipientID string    `json:"recipient_id"`
+		Status      string    `json:"status"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp) != 1 {
+		t.Fatalf("unexpected receipts response: %s", rec.Body.String())
+	}
+	if resp[0].RecipientID != peerA || resp[0].Status != "read" || !resp[0].UpdatedAt.Equal(updatedAt) {
+		t.Errorf("unexpected receipt: %+v", resp[0])
+	}
+	if !gotSince.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected since to be forwarded, got %s", gotSince)
+	}
+
+	for _, target := range []string{
+		"/api/chat/conversations/" + peerA + "/receipts?since=yesterday",
+		"/api/chat/conversations/not-a-uuid/receipts",
+	} {
+		if rec := do(http.MethodGet, target, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", target, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaWS "github.com/gorilla/websocket"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+)
+
+const sessionTestUserID = "11111111-1111-1111-1111-111111111111"
+
+func startSessionServer(t *testing.T, claims jwtverify.Claims) (*websocket.Hub, *gorillaWS.Conn) {
+	t.Helper()
+	return startSessionServerWithClock(t, claims, nil)
+}
+
+func startSessionServerWithClock(t *testing.T, claims jwtverify.Claims, clk clock.Clock) (*websocket.Hub, *gorillaWS.Conn) {
+	t.Helper()
+	log, _ := logtest.New(t)
+	hub := websocket.NewHub(websocket.HubDeps{Log: log, Clock: clk}, websocket.HubConfig{
+		MaxConnections: 10,
+		SendTimeout:    time.Second,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+
+	upgrader := gorillaWS.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := websocket.NewAuthenticatedClient(hub, conn, claims, constants.TestJWTSecret, log, nil,
+			time.Second, time.Minute, 30*time.Second, 1<<20, 16)
+		hub.Register(client)
+		client.Start()
+	}))
+
+	conn, _, err := gorillaWS.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		server.Close()
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for !hub.IsUserOnline(claims.UserID) {
+		if time.Now().After(deadline) {
+			t.Fatal("client was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return hub, conn
+}
+
+func readWSMessage(t *testing.T, conn *gorillaWS.Conn) websocket.WSMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var msg websocket.WSMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	return msg
+}
+
+func expectCloseCode(t *testing.T, conn *gorillaWS.Conn, code int) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !gorillaWS.IsCloseError(err, code) {
+			t.Fatalf("expected close code %d, got %v", code, err)
+		}
+		return
+	}
+}
+
+func TestClient_TokenExpiry_WarnsThenDisconnects(t *testing.T) {
+	_, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Second),
+	})
+
+	msg := readWSMessage(t, conn)
+	if msg.Type != websocket.TypeAuthExpiring {
+		t.Fatalf("expected %s message, got %s", websocket.TypeAuthExpiring, msg.Type)
+	}
+	var payload websocket.AuthExpiringPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.ExpiresAt == "" {
+		t.Errorf("expected expires_at in payload, got %s", string(msg.Payload))
+	}
+
+	expectCloseCode(t, conn, constants.WebSocketCloseTokenExpired)
+}
+
+func TestClient_TokenExpiry_UsesHubClock(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	clk := clock.NewMockClock(expiresAt.Add(-constants.WebSocketAuthExpiryWarning / 2))
+	_, conn := startSessionServerWithClock(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: expiresAt,
+	}, clk)
+
+	msg := readWSMessage(t, conn)
+	if msg.Type != websocket.TypeAuthExpiring {
+		t.Fatalf("expected %s message, got %s", websocket.TypeAuthExpiring, msg.Type)
+	}
+	var payload websocket.AuthExpiringPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.ExpiresInSeconds != int64(constants.WebSocketAuthExpiryWarning/2/time.Second) {
+		t.Errorf("expected expiry to be measured on the hub clock, got %s", string(msg.Payload))
+	}
+}
+
+func TestClient_Reauthenticate_ExtendsSession(t *testing.T) {
+	_, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Second),
+	})
+
+	if msg := readWSMessage(t, conn); msg.Type != websocket.TypeAuthExpiring {
+		t.Fatalf("expected %s message, got %s", websocket.TypeAuthExpiring, msg.Type)
+	}
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "alice"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	authPayload, _ := json.Marshal(websocket.AuthPayload{Token: token})
+	if err := conn.WriteJSON(websocket.WSMessage{Type: websocket.TypeAuth, Payload: authPayload}); err != nil {
+		t.Fatalf("failed to send auth: %v", err)
+	}
+
+	msg := readWSMessage(t, conn)
+	if msg.Type != websocket.TypeAuth {
+		t.Fatalf("expected auth response, got %s", msg.Type)
+	}
+	var response websocket.AuthResponsePayload
+	if err := json.Unmarshal(msg.Payload, &response); err != nil {
+		t.Fatalf("failed to decode auth response: %v", err)
+	}
+	if !response.Authenticated || response.ExpiresAt == "" {
+		t.Fatalf("expected successful re-auth with expiry, got %+v", response)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(1500 * time.Millisecond))
+	_, _, err = conn.ReadMessage()
+	if gorillaWS.IsCloseError(err, constants.WebSocketCloseTokenExpired) {
+		t.Fatal("expected session to stay open after re-authentication")
+	}
+}
+
+func TestClient_Reauthenticate_RejectsInvalidToken(t *testing.T) {
+	_, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	authPayload, _ := json.Marshal(websocket.AuthPayload{Token: "garbage"})
+	if err := conn.WriteJSON(websocket.WSMessage{Type: websocket.TypeAuth, Payload: authPayload}); err != nil {
+		t.Fatalf("failed to send auth: %v", err)
+	}
+
+	msg := readWSMessage(t, conn)
+	var response websocket.AuthResponsePayload
+	if err := json.Unmarshal(msg.Payload, &response); err != nil {
+		t.Fatalf("failed to decode auth response: %v", err)
+	}
+	if response.Authenticated || response.Code != "INVALID_TOKEN" {
+		t.Errorf("expected INVALID_TOKEN response, got %+v", response)
+	}
+}
+
+func TestHub_DisconnectRevoked(t *testing.T) {
+	hub, conn := startSessionServer(t, jwtverify.Claims{
+		UserID:    sessionTestUserID,
+		Username:  "alice",
+		JTI:       "jti-current",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	hub.DisconnectRevoked("jti-other", sessionTestUserID)
+	if !hub.IsUserOnline(sessionTestUserID) {
+		t.Fatal("expected client to stay connected for unrelated revocation")
+	}
+
+	hub.DisconnectRevoked("jti-current", sessionTestUserID)
+	expectCloseCode(t, conn, constants.WebSocketCloseTokenRevoked)
+}
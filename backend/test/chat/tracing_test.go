@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/observability/tracing"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func initDisabledTracing(t *testing.T) {
+	t.Helper()
+	log, _ := logger.New("", "test", "info")
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{Enabled: false}, log)
+	if err != nil {
+		t.Fatalf("failed to init tracing: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+}
+
+func TestTracing_TraceParentRoundTrip(t *testing.T) {
+	initDisabledTracing(t)
+
+	ctx := tracing.ExtractTraceParent(context.Background(), testTraceParent)
+
+	if got := tracing.TraceID(ctx); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace id from traceparent, got %q", got)
+	}
+	if got := tracing.TraceParent(ctx); got != testTraceParent {
+		t.Errorf("expected traceparent %q, got %q", testTraceParent, got)
+	}
+}
+
+func TestTracing_ExtractTraceParent_Invalid(t *testing.T) {
+	initDisabledTracing(t)
+
+	ctx := tracing.ExtractTraceParent(context.Background(), "not-a-traceparent")
+
+	if got := tracing.TraceID(ctx); got != "" {
+		t.Errorf("expected empty trace id, got %q", got)
+	}
+	if got := tracing.TraceParent(ctx); got != "" {
+		t.Errorf("expected empty traceparent, got %q", got)
+	}
+}
+
+func TestTracing_HTTPMiddleware_PropagatesTraceParent(t *testing.T) {
+	initDisabledTracing(t)
+
+	var handlerTraceID string
+	handler := tracing.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceID = tracing.TraceID(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/chat/me", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+	if handlerTraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected handler to see incoming trace id, got %q", handlerTraceID)
+	}
+	if got := rec.Header().Get("traceparent"); got != testTraceParent {
+		t.Errorf("expected response traceparent %q, got %q", testTraceParent, got)
+	}
+}
+
+func TestTracing_HTTPMiddleware_NamesSpanByMethod(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+
+	handler := tracing.HTTPMiddlewareWithProvider(provider)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	paths := []string{"/api/identity/users/a1", "/api/identity/users/b2"}
+	for _, path := range paths {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != len(paths) {
+		t.Fatalf("expected %d spans, got %d", len(paths), len(spans))
+	}
+	for i, span := range spans {
+		if span.Name() != http.MethodGet {
+			t.Errorf("expected span name %q, got %q", http.MethodGet, span.Name())
+		}
+		want := attribute.String("url.path", paths[i])
+		found := false
+		for _, attr := range span.Attributes() {
+			if attr == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected span attributes to contain %v, got %v", want, span.Attributes())
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	authservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/auth/service"
+	chathttp "github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/http"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/chat/websocket"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/clock"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/config"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/constants"
+	commoncrypto "github.com/AlibekovAA/dh-secure-chat/backend/internal/common/crypto"
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/jwtverify"
+	userdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/user/domain"
+	webhookdomain "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/domain"
+	webhookservice "github.com/AlibekovAA/dh-secure-chat/backend/internal/webhook/service"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/logtest"
+	"github.com/AlibekovAA/dh-secure-chat/backend/test/pushtest"
+)
+
+func awaitDeadLetter(t *testing.T, repo *mockWebhookRepo) webhookdomain.DeadLetter {
+	t.Helper()
+	select {
+	case letter := <-repo.deadLetters:
+		return letter
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for dead letter")
+		return webhookdomain.DeadLetter{}
+	}
+}
+
+func TestWebhookService_SignsDeliveriesAndDeadLettersFailures(t *testing.T) {
+	log, _ := logtest.New(t)
+	server := pushtest.NewServer(t)
+	webhook := webhookdomain.Webhook{
+		ID:      "7b0c9e1a-2d3f-4a5b-8c6d-9e0f1a2b3c4d",
+		OwnerID: peerA,
+		URL:     server.URL + "/hooks",
+		Secret:  "webhook-signing-key",
+		Events:  []webhookdomain.EventType{webhookdomain.EventMessage},
+	}
+	repo := newMockWebhookRepo(webhook)
+	webhooks := webhookservice.NewWebhookService(context.Background(), webhookservice.WebhookServiceDeps{
+		Repo:       repo,
+		Client:     server.Client(),
+		RetryDelay: time.Millisecond,
+		Log:        log,
+	})
+	defer webhooks.Stop()
+	ctx := context.Background()
+
+	payload := []byte(`{"to":"` + peerA + `","from":"` + peerB + `","message_id":"m-1","ciphertext":"c","nonce":"n"}`)
+	if webhooks.Dispatch(ctx, peerA, "reaction", payload, time.Time{}) || webhooks.Dispatch(ctx, peerA, "typing", payload, time.Time{}) {
+		t.Fatal("expected events without a subscription not to be dispatched")
+	}
+	if !webhooks.Dispatch(ctx, peerA, "message", payload, time.Time{}) {
+		t.Fatal("expected message event to be dispatched")
+	}
+
+	req := server.Await(t, 1)[0]
+	if req.Method != http.MethodPost || req.Path != "/hooks" {
+		t.Errorf("unexpected request %s %s", req.Method, req.Path)
+	}
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhookdomain.HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header: %v", err)
+	}
+	if !webhookdomain.Verify(webhook.Secret, req.Header.Get(webhookdomain.HeaderSignature), timestamp, req.Body) {
+		t.Errorf("expected a valid signature, got %q", req.Header.Get(webhookdomain.HeaderSignature))
+	}
+	if webhookdomain.Verify(webhook.Secret, req.Header.Get(webhookdomain.HeaderSignature), timestamp+1, req.Body) {
+		t.Error("expected signature to cover the timestamp")
+	}
+	var event webhookdomain.Event
+	if err := json.Unmarshal(req.Body, &event); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if event.Type != webhookdomain.EventMessage || event.ID != req.Header.Get(webhookdomain.HeaderID) || string(event.Data) != string(payload) {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	server.SetStatus(http.StatusServiceUnavailable)
+	webhooks.Dispatch(ctx, peerA, "message", payload, time.Time{})
+	server.Await(t, constants.WebhookMaxAttempts)
+	letter := awaitDeadLetter(t, repo)
+	if letter.Attempts != constants.WebhookMaxAttempts || letter.WebhookID != webhook.ID || !strings.Contains(letter.LastError, "503") {
+		t.Errorf("unexpected dead letter after retries: %+v", letter)
+	}
+
+	server.SetStatus(http.StatusBadRequest)
+	webhooks.Dispatch(ctx, peerA, "message", payload, time.Time{})
+	server.Await(t, 1)
+	if letter := awaitDeadLetter(t, repo); letter.Attempts != 1 {
+		t.Errorf("expected rejected event to be dead-lettered without retries, got %+v", letter)
+	}
+	if repo.lookups != 1 {
+		t.Errorf("expected subscriptions to be served from cache, got %d lookups", repo.lookups)
+	}
+}
+
+type dispatchedEvent struct {
+	owner   string
+	event   string
+	payload []byte
+}
+
+type recordingWebhooks struct {
+	mu     sync.Mutex
+	owners map[string]bool
+	events []dispatchedEvent
+}
+
+func (w *recordingWebhooks) Dispatch(ctx context.Context, ownerID, event string, payload []byte, expiresAt time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.owners[ownerID] {
+		return false
+	}
+	w.events = append(w.events, dispatchedEvent{owner: ownerID, event: event, payload: payload})
+	return true
+}
+
+func TestMessageRouter_DispatchesOfflineEventsToWebhooks(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := newPresenceSender()
+	push := &recordingPush{}
+	webhooks := &recordingWebhooks{owners: map[string]bool{peerA: true}}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender:   sender,
+		Push:     push,
+		Webhooks: webhooks,
+		Log:      log,
+	}, websocket.MessageRouterConfig{})
+	client := &websocket.Client{}
+
+	route := func(msgType websocket.MessageType, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		if err := router.Route(context.Background(), client, &websocket.WSMessage{Type: msgType, Payload: data}); err != nil {
+			t.Fatalf("unexpected error routing %s: %v", msgType, err)
+		}
+	}
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerA, MessageID: "m-1", Ciphertext: "c", Nonce: "n"})
+	route(websocket.TypeReaction, websocket.ReactionPayload{To: peerA, MessageID: "m-1", Emoji: "👍", Action: "add"})
+	route(websocket.TypeTyping, websocket.TypingPayload{To: peerA})
+	route(websocket.TypeMessage, websocket.MessagePayload{To: peerB, MessageID: "m-2", Ciphertext: "c", Nonce: "n"})
+
+	if len(webhooks.events) != 2 || webhooks.events[0].event != "message" || webhooks.events[1].event != "reaction" {
+		t.Fatalf("expected message and reaction to reach the webhook, got %+v", webhooks.events)
+	}
+	var forwarded websocket.MessagePayload
+	if err := json.Unmarshal(webhooks.events[0].payload, &forwarded); err != nil || forwarded.MessageID != "m-1" || forwarded.Ciphertext != "c" {
+		t.Errorf("expected the forwarded payload to be dispatched, got %s", webhooks.events[0].payload)
+	}
+	if len(push.users) != 1 || push.users[0] != peerB {
+		t.Errorf("expected only the recipient without a webhook to be pushed, got %+v", push.users)
+	}
+}
+
+func TestHandler_BotMessagesAndWebhooks(t *testing.T) {
+	log, _ := logtest.New(t)
+	sender := &recordingSender{}
+	router := websocket.NewMessageRouter(websocket.MessageRouterDeps{
+		Sender: sender,
+		Log:    log,
+	}, websocket.MessageRouterConfig{})
+	repo := newMockWebhookRepo()
+	webhooks := webhookservice.NewWebhookService(context.Background(), webhookservice.WebhookServiceDeps{Repo: repo, Log: log})
+	defer webhooks.Stop()
+
+	chatSvc := service.NewChatService(service.ChatServiceDeps{
+		Repo:            newMockUserRepo(),
+		IdentityService: newMockIdentityService(),
+		Webhooks:        webhooks,
+		Messenger:       router,
+		Log:             log,
+	})
+	hub := websocket.NewHub(websocket.HubDeps{Log: log}, websocket.HubConfig{MaxConnections: 10})
+	handler := jwtverify.Middleware(constants.TestJWTSecret, log, nil)(
+		chathttp.NewHandler(chatSvc, hub, config.ChatConfig{RequestTimeout: time.Second}, log, nil),
+	)
+
+	issuer := authservice.NewTokenIssuer(constants.TestJWTSecret, &commoncrypto.UUIDGenerator{}, time.Hour, clock.NewRealClock())
+	token, _, err := issuer.IssueAccessToken(userdomain.User{ID: sessionTestUserID, Username: "deploybot"})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/chat/bot/messages", `{"to":"`+peerA+`","message_id":"m-1","ciphertext":"c","nonce":"n"}`)
+	if rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"delivered":true`) {
+		t.Fatalf("expected accepted delivery, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var delivered websocket.MessagePayload
+	if len(sender.sent) != 1 || json.Unmarshal(sender.sent[0].Payload, &delivered) != nil || delivered.From != sessionTestUserID {
+		t.Fatalf("expected message to be sent on behalf of the bot, got %+v", sender.sent)
+	}
+
+	rec = do(http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://bot.example.com/hooks","events":["message","reaction","message"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created struct {
+		ID     string   `json:"id"`
+		Events []string `json:"events"`
+		Secret string   `json:"secret"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode webhook: %v", err)
+	}
+	if len(created.Secret) != 2*constants.WebhookSecretBytes || len(created.Events) != 2 {
+		t.Fatalf("expected a generated secret and deduplicated events, got %+v", created)
+	}
+	if rec := do(http.MethodGet, "/api/chat/bot/webhooks", ""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), created.Secret) || !strings.Contains(rec.Body.String(), created.ID) {
+		t.Errorf("expected the webhook to be listed without its secret, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPost, "/api/chat/bot/messages", `{"to":"` + peerA + `","message_id":"m-2","ciphertext":"c"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/messages", `{"to":"not-a-uuid","message_id":"m-2","ciphertext":"c","nonce":"n"}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/chat/bot/messages", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"http://bot.example.com/hooks","events":["message"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://localhost:8443/hooks","events":["message"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://192.168.0.10/hooks","events":["message"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://[fe80::1]/hooks","events":["message"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://bot.example.com/hooks","events":["typing"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/chat/bot/webhooks", `{"url":"https://bot.example.com/hooks","events":[]}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/chat/bot/webhooks", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/chat/bot/webhooks/" + created.ID + "/dead-letters", "", http.StatusOK},
+		{http.MethodGet, "/api/chat/bot/webhooks/" + peerB + "/dead-letters", "", http.StatusNotFound},
+		{http.MethodDelete, "/api/chat/bot/webhooks/not-a-uuid", "", http.StatusBadRequest},
+		{http.MethodDelete, "/api/chat/bot/webhooks/" + created.ID, "", http.StatusNoContent},
+		{http.MethodDelete, "/api/chat/bot/webhooks/" + created.ID, "", http.StatusNotFound},
+	} {
+		if rec := do(tc.method, tc.target, tc.body); rec.Code != tc.status {
+			t.Errorf("%s %s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.body, tc.status, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AlibekovAA/dh-secure-chat/backend/internal/common/logger"
+)
+
+var inlineFieldPattern = regexp.MustCompile(`([A-Za-z_]+)=`)
+
+type Sink struct {
+	t         testing.TB
+	mu        sync.Mutex
+	buf       []byte
+	entries   []map[string]interface{}
+	protected []string
+	done      bool
+}
+
+func New(t testing.TB) (*logger.Logger, *Sink) {
+	return NewWithConfig(t, logger.Config{Level: "DEBUG"})
+}
+
+func NewWithConfig(t testing.TB, config logger.Config) (*logger.Logger, *Sink) {
+	t.Helper()
+	sink := &Sink{t: t}
+	t.Cleanup(sink.close)
+
+	if config.ServiceName == "" {
+		config.ServiceName = "test"
+	}
+	config.Writer = sink
+	log, err := logger.NewWithConfig(config)
+	if err != nil {
+		t.Fatalf("logtest: failed to create logger: %v", err)
+	}
+	return log, sink
+}
+
+func (s *Sink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, p...)
+	for {
+		idx := bytes.IndexByte(s.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		line := s.buf[:idx]
+		s.buf = s.buf[idx+1:]
+		s.record(line)
+	}
+	return len(p), nil
+}
+
+func (s *Sink) record(line []byte) {
+	var entry map[string]interface{}
+	if err := json.Unmarshal(line, &entry); err != nil {
+		s.fail("logtest: log line is not valid JSON: %s", line)
+		return
+	}
+	for key := range entry {
+		if logger.IsSensitiveFieldName(key) {
+			s.fail("logtest: sensitive field %q reached the log sink: %s", key, line)
+		}
+	}
+	if message, ok := entry["message"].(string); ok {
+		for _, match := range inlineFieldPattern.FindAllStringSubmatch(message, -1) {
+			if isRedactedField(match[1]) {
+				s.fail("logtest: field %q is formatted into the log message instead of passed as a field: %s", match[1], line)
+			}
+		}
+	}
+	for _, value := range s.protected {
+		if strings.Contains(string(line), value) {
+			s.fail("logtest: protected value %q reached the log sink: %s", value, line)
+		}
+	}
+	s.entries = append(s.entries, entry)
+}
+
+func isRedactedField(name string) bool {
+	if logger.IsSensitiveFieldName(name) {
+		return true
+	}
+	for _, rule := range logger.DefaultRedactionRules {
+		if strings.EqualFold(rule.Field, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sink) Protect(values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, value := range values {
+		if value != "" {
+			s.protected = append(s.protected, value)
+		}
+	}
+}
+
+func (s *Sink) fail(format string, args ...interface{}) {
+	if s.done {
+		return
+	}
+	s.t.Errorf(format, args...)
+}
+
+func (s *Sink) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+}
+
+func (s *Sink) Entries() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.entries...)
+}
+
+func (s *Sink) FindAction(action string) (map[string]interface{}, bool) {
+	for _, entry := range s.Entries() {
+		if entry["action"] == action {
+			return entry, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package pushtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	requests []Request
+	received chan struct{}
+}
+
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{status: http.StatusCreated, received: make(chan struct{}, 100)}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) SetStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *Server) Await(t testing.TB, count int) []Request {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for i := 0; i < count; i++ {
+		select {
+		case <-s.received:
+		case <-deadline:
+			t.Fatalf("pushtest: timed out waiting for %d requests", count)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	status := s.status
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+	s.received <- struct{}{}
+}
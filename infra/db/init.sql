@@ -32,3 +32,116 @@ CREATE TABLE IF NOT EXISTS revoked_tokens (
 );
 CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id);
 CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY,
+    user_id UUID,
+    event_type TEXT NOT NULL,
+    client_ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    trace_id TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id_created_at ON audit_events (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_event_type ON audit_events (event_type);
+CREATE OR REPLACE FUNCTION audit_events_reject_modification() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_reject_modification();
+CREATE TABLE IF NOT EXISTS message_receipts (
+    message_id TEXT NOT NULL,
+    sender_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'delivered', 'read')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    edit_count INT NOT NULL DEFAULT 0 CHECK (edit_count >= 0),
+    deleted_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    PRIMARY KEY (sender_id, message_id)
+);
+CREATE INDEX IF NOT EXISTS idx_message_receipts_conversation ON message_receipts (sender_id, recipient_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_message_receipts_recipient_id ON message_receipts (recipient_id, sender_id);
+CREATE INDEX IF NOT EXISTS idx_message_receipts_expires_at ON message_receipts (expires_at) WHERE expires_at IS NOT NULL;
+CREATE OR REPLACE FUNCTION receipt_status_rank(status TEXT) RETURNS INT AS $$
+    SELECT CASE status WHEN 'sent' THEN 1 WHEN 'delivered' THEN 2 WHEN 'read' THEN 3 ELSE 0 END;
+$$ LANGUAGE sql IMMUTABLE;
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    peer_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, peer_id, message_id, emoji)
+);
+CREATE INDEX IF NOT EXISTS idx_message_reactions_peer_id ON message_reactions (peer_id, user_id, message_id);
+CREATE INDEX IF NOT EXISTS idx_message_reactions_expires_at ON message_reactions (expires_at) WHERE expires_at IS NOT NULL;
+CREATE TABLE IF NOT EXISTS user_privacy_settings (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    read_receipts BOOLEAN NOT NULL DEFAULT TRUE,
+    presence_visibility TEXT NOT NULL DEFAULT 'everyone' CHECK (presence_visibility IN ('everyone', 'contacts', 'nobody')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS conversations (
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    peer_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    last_activity_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unread_count INT NOT NULL DEFAULT 0 CHECK (unread_count >= 0),
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (owner_id, peer_id)
+);
+CREATE INDEX IF NOT EXISTS idx_conversations_owner_activity ON conversations (owner_id, archived, pinned DESC, last_activity_at DESC);
+CREATE TABLE IF NOT EXISTS disappearing_timers (
+    user_a UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_b UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ttl_seconds BIGINT NOT NULL DEFAULT 0 CHECK (ttl_seconds >= 0),
+    updated_by UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_a, user_b),
+    CHECK (user_a < user_b)
+);
+CREATE TABLE IF NOT EXISTS push_devices (
+    token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider TEXT NOT NULL CHECK (provider IN ('webpush', 'webhook')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices (user_id);
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL CHECK (cardinality(events) > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner_id ON webhooks (owner_id);
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL,
+    last_error TEXT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_webhook_id ON webhook_dead_letters (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_expires_at ON webhook_dead_letters (expires_at) WHERE expires_at IS NOT NULL;